@@ -0,0 +1,187 @@
+package gospeech
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+// A PhoneTiming records when a phone is heard in synthesized speech.
+type PhoneTiming struct {
+	Phone Phone
+
+	// Word is the index of the word containing the phone, or -1 for WordBreaks and Pauses.
+	Word int
+
+	// Start and End bound the phone, from the start of the speech.
+	// The phones of an utterance follow each other without gaps, and WordBreaks and Pauses
+	// cover the silences between words.
+	Start time.Duration
+	End   time.Duration
+}
+
+// Samples returns the range [start, end) of samples in which the phone is heard when the speech
+// is encoded at the given sample rate.
+func (p PhoneTiming) Samples(sampleRate int) (start, end int) {
+	return tracks.SampleCount(p.Start, sampleRate), tracks.SampleCount(p.End, sampleRate)
+}
+
+// A WordTiming records when a word is heard in synthesized speech.
+type WordTiming struct {
+	// Text is the word as it was written, or the symbols of its phones if the speech was
+	// synthesized from phones.
+	Text string
+
+	Start time.Duration
+	End   time.Duration
+}
+
+// An Alignment records when the phones and words of an utterance are heard in its synthesized
+// speech.
+type Alignment struct {
+	Phones []PhoneTiming
+	Words  []WordTiming
+
+	// Duration is the length of the speech, including the silence after the final word.
+	Duration time.Duration
+}
+
+// newAlignment creates an Alignment for a sequence of phones, given the time at which each phone
+// starts and the time at which the final phone ends.
+func newAlignment(phones []Phone, starts []time.Duration, duration time.Duration) *Alignment {
+	res := &Alignment{Phones: make([]PhoneTiming, len(phones)), Duration: duration}
+	inWord := false
+	for i, phone := range phones {
+		timing := PhoneTiming{Phone: phone, Word: -1, Start: starts[i], End: starts[i+1]}
+		switch phone.(type) {
+		case WordBreak, Pause:
+			inWord = false
+		default:
+			if !inWord {
+				res.Words = append(res.Words, WordTiming{Start: timing.Start})
+				inWord = true
+			}
+			timing.Word = len(res.Words) - 1
+			word := &res.Words[timing.Word]
+			word.Text += phoneSymbol(phone)
+			word.End = timing.End
+		}
+		res.Phones[i] = timing
+	}
+	return res
+}
+
+// phoneSymbol returns the IPA symbol of a phone produced by ParseIPA, or "" for other phones.
+func phoneSymbol(p Phone) string {
+	if ipa, ok := p.(*IPAPhone); ok {
+		return ipa.Symbol
+	}
+	return ""
+}
+
+// WriteJSON writes the alignment as JSON, with times in seconds.
+// Phones are identified by their IPA symbols.
+func (a *Alignment) WriteJSON(w io.Writer) error {
+	type jsonPhone struct {
+		Symbol string  `json:"symbol"`
+		Word   int     `json:"word"`
+		Start  float64 `json:"start"`
+		End    float64 `json:"end"`
+	}
+	type jsonWord struct {
+		Text  string  `json:"text"`
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+	}
+	var obj struct {
+		Duration float64     `json:"duration"`
+		Phones   []jsonPhone `json:"phones"`
+		Words    []jsonWord  `json:"words"`
+	}
+	obj.Duration = a.Duration.Seconds()
+	obj.Phones = make([]jsonPhone, len(a.Phones))
+	for i, p := range a.Phones {
+		obj.Phones[i] = jsonPhone{phoneSymbol(p.Phone), p.Word, p.Start.Seconds(), p.End.Seconds()}
+	}
+	obj.Words = make([]jsonWord, len(a.Words))
+	for i, word := range a.Words {
+		obj.Words[i] = jsonWord{word.Text, word.Start.Seconds(), word.End.Seconds()}
+	}
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// A textGridInterval is a labeled interval of a TextGrid tier.
+type textGridInterval struct {
+	start time.Duration
+	end   time.Duration
+	text  string
+}
+
+// WriteTextGrid writes the alignment as a Praat TextGrid with a "words" tier and a "phones"
+// tier.
+// The silences between words, and WordBreaks and Pauses, are unlabeled intervals.
+func (a *Alignment) WriteTextGrid(w io.Writer) error {
+	var words, phones []textGridInterval
+	for _, word := range a.Words {
+		words = append(words, textGridInterval{word.Start, word.End, word.Text})
+	}
+	for _, p := range a.Phones {
+		phones = append(phones, textGridInterval{p.Start, p.End, phoneSymbol(p.Phone)})
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "File type = \"ooTextFile\"\nObject class = \"TextGrid\"\n\n")
+	fmt.Fprintf(&buf, "xmin = 0\nxmax = %g\ntiers? <exists>\nsize = 2\nitem []:\n",
+		a.Duration.Seconds())
+	a.writeTextGridTier(&buf, 1, "words", words)
+	a.writeTextGridTier(&buf, 2, "phones", phones)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeTextGridTier writes an interval tier, filling the gaps between intervals with unlabeled
+// intervals so that the tier covers the whole alignment.
+func (a *Alignment) writeTextGridTier(buf *bytes.Buffer, index int, name string,
+	intervals []textGridInterval) {
+	var filled []textGridInterval
+	var last time.Duration
+	for _, interval := range intervals {
+		if interval.end <= interval.start {
+			continue
+		}
+		if interval.start > last {
+			filled = append(filled, textGridInterval{start: last, end: interval.start})
+		}
+		filled = append(filled, interval)
+		last = interval.end
+	}
+	if last < a.Duration || len(filled) == 0 {
+		filled = append(filled, textGridInterval{start: last, end: a.Duration})
+	}
+
+	fmt.Fprintf(buf, "    item [%d]:\n", index)
+	fmt.Fprintf(buf, "        class = \"IntervalTier\"\n        name = %s\n", textGridString(name))
+	fmt.Fprintf(buf, "        xmin = 0\n        xmax = %g\n", a.Duration.Seconds())
+	fmt.Fprintf(buf, "        intervals: size = %d\n", len(filled))
+	for i, interval := range filled {
+		fmt.Fprintf(buf, "        intervals [%d]:\n", i+1)
+		fmt.Fprintf(buf, "            xmin = %g\n            xmax = %g\n",
+			interval.start.Seconds(), interval.end.Seconds())
+		fmt.Fprintf(buf, "            text = %s\n", textGridString(interval.text))
+	}
+}
+
+// textGridString quotes a string for a TextGrid, in which quotes are escaped by doubling them.
+func textGridString(s string) string {
+	return "\"" + strings.ReplaceAll(s, "\"", "\"\"") + "\""
+}
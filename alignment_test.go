@@ -0,0 +1,144 @@
+package gospeech
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+func TestSynthesizeAlignedSlices(t *testing.T) {
+	const text = "Hello, world. Good morning."
+	for _, rate := range []float64{1, 1.5} {
+		voice := DefaultVoice
+		voice.Rate = rate
+		frontend := &TextFrontend{Dictionary: SampleDictionary(), Voice: voice}
+		sound, alignment, err := frontend.SynthesizeAligned(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples := sound.Samples()
+		sampleRate := sound.SampleRate()
+		if n := tracks.SampleCount(alignment.Duration, sampleRate); n != len(samples) {
+			t.Fatalf("rate %f: expected %d samples for %s but got %d", rate, n,
+				alignment.Duration, len(samples))
+		}
+
+		var words []string
+		for _, word := range alignment.Words {
+			words = append(words, word.Text)
+		}
+		if joined := strings.Join(words, " "); joined != "Hello world Good morning" {
+			t.Errorf("rate %f: unexpected words %q", rate, joined)
+		}
+
+		var last time.Duration
+		for i, p := range alignment.Phones {
+			if p.Start != last {
+				t.Errorf("rate %f: phone %d starts at %s, after a gap from %s", rate, i, p.Start,
+					last)
+			}
+			last = p.End
+
+			// Slicing the audio at the boundaries gives as much audio as the phone lasts.
+			start, end := p.Samples(sampleRate)
+			slice := samples[start:end]
+			expected := float64(p.End-p.Start) / float64(time.Second) * float64(sampleRate)
+			if math.Abs(float64(len(slice))-expected) > 1 {
+				t.Errorf("rate %f: phone %d: expected %f samples but the slice has %d", rate, i,
+					expected, len(slice))
+			}
+			if p.Word >= 0 && (p.Start < alignment.Words[p.Word].Start ||
+				p.End > alignment.Words[p.Word].End) {
+				t.Errorf("rate %f: phone %d lies outside of word %d", rate, i, p.Word)
+			}
+
+			// Inserted pauses fall silent once the preceding word has faded out.
+			if _, ok := p.Phone.(Pause); ok {
+				tail := slice[tracks.SampleCount(time.Millisecond*100, sampleRate):]
+				if peak := tracks.PeakLevel(tail); peak > 0.01 {
+					t.Errorf("rate %f: expected pause %d to be silent, but it peaks at %f", rate,
+						i, peak)
+				}
+			}
+		}
+		if last > alignment.Duration {
+			t.Errorf("rate %f: the phones end at %s, after the speech at %s", rate, last,
+				alignment.Duration)
+		}
+	}
+}
+
+func TestSynthesizeAlignedStress(t *testing.T) {
+	duration := func(ipa string) time.Duration {
+		_, alignment := DefaultVoice.SynthesizeAligned(timeIPA(t, DefaultVoice, ipa))
+		vowel := alignment.Phones[1]
+		return vowel.End - vowel.Start
+	}
+	if stressed, plain := duration("ˈzaz"), duration("zaz"); stressed <= plain {
+		t.Errorf("expected stress to lengthen the aligned vowel from %s, but got %s", plain,
+			stressed)
+	}
+}
+
+func TestAlignmentExport(t *testing.T) {
+	phones, err := DefaultVoice.ParseIPA("ha ʊ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, alignment := DefaultVoice.SynthesizeAligned(DefaultVoice.TimePhones(phones))
+
+	var buf bytes.Buffer
+	if err := alignment.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var obj struct {
+		Duration float64
+		Phones   []struct {
+			Symbol     string
+			Word       int
+			Start, End float64
+		}
+		Words []struct{ Text string }
+	}
+	if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+		t.Fatal(err)
+	}
+	if len(obj.Phones) != len(alignment.Phones) || len(obj.Words) != 2 ||
+		obj.Words[0].Text != "ha" || obj.Words[1].Text != "ʊ" {
+		t.Fatalf("unexpected JSON: %s", buf.String())
+	}
+	for i, p := range obj.Phones {
+		if math.Abs(p.End-alignment.Phones[i].End.Seconds()) > 1e-9 ||
+			p.Word != alignment.Phones[i].Word {
+			t.Errorf("phone %d: unexpected JSON %v", i, p)
+		}
+	}
+	if math.Abs(obj.Duration-alignment.Duration.Seconds()) > 1e-9 {
+		t.Errorf("expected a duration of %f but got %f", alignment.Duration.Seconds(),
+			obj.Duration)
+	}
+
+	buf.Reset()
+	if err := alignment.WriteTextGrid(&buf); err != nil {
+		t.Fatal(err)
+	}
+	grid := buf.String()
+	for _, expected := range []string{
+		"Object class = \"TextGrid\"",
+		"size = 2",
+		"name = \"words\"",
+		"name = \"phones\"",
+		"text = \"ha\"",
+		"text = \"ʊ\"",
+		"text = \"h\"",
+	} {
+		if !strings.Contains(grid, expected) {
+			t.Errorf("expected the TextGrid to contain %q:\n%s", expected, grid)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+// Command gospeech synthesizes English text or IPA into a WAV file.
+//
+// Usage:
+//
+//	gospeech [flags] [text ...]
+//
+// The text is read from the arguments, or from stdin if there are none.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/unixpickle/gospeech"
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+// voices are the voices which can be selected by name with the -voice flag.
+var voices = map[string]func() gospeech.Voice{
+	"default": func() gospeech.Voice {
+		return gospeech.DefaultVoice
+	},
+	"whisper": func() gospeech.Voice {
+		v := gospeech.DefaultVoice
+		v.Whisper = true
+		return v
+	},
+}
+
+func main() {
+	if err := Run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// Run runs the command with the given arguments, excluding the program name.
+// Input is read from stdin if no text is given in the arguments, and the output is written to
+// stdout if the output path is "-".
+func Run(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gospeech", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	voiceName := fs.String("voice", "default", "voice name ("+voiceNames()+
+		") or path to a JSON voice config")
+	rate := fs.Float64("rate", 1, "speech rate multiplier")
+	pitch := fs.Float64("pitch", 0, "base pitch offset in semitones")
+	sampleRate := fs.Int("samplerate", 44100, "output sample rate")
+	output := fs.String("o", "output.wav", "output path, or - for stdout")
+	ipa := fs.Bool("ipa", false, "treat the input as IPA instead of English text")
+	dictPath := fs.String("dict", "", "CSV dictionary of words and IPA (default: a small "+
+		"built-in dictionary)")
+	cmuDictPath := fs.String("cmudict", "", "dictionary in the CMU Pronouncing Dictionary format")
+	if err := fs.Parse(args); err != nil {
+		var usage bytes.Buffer
+		fs.SetOutput(&usage)
+		fs.PrintDefaults()
+		return errors.New(err.Error() + "\nUsage: gospeech [flags] [text ...]\n" +
+			strings.TrimRight(usage.String(), "\n"))
+	}
+
+	if *rate <= 0 || math.IsInf(*rate, 0) || math.IsNaN(*rate) {
+		return errors.New("invalid rate: " + strconv.FormatFloat(*rate, 'g', -1, 64))
+	}
+	if *sampleRate <= 0 {
+		return errors.New("invalid sample rate: " + strconv.Itoa(*sampleRate))
+	}
+	voice, err := loadVoice(*voiceName)
+	if err != nil {
+		return err
+	}
+	if voice.Rate == 0 {
+		voice.Rate = 1
+	}
+	voice.Rate *= *rate
+	if voice.Pitch == 0 {
+		voice.Pitch = gospeech.NewVocalSystem().Pitch()
+	}
+	voice.Pitch *= math.Pow(2, *pitch/12)
+
+	text, err := inputText(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	var phones []gospeech.TimedPhone
+	if *ipa {
+		plain, err := voice.ParseIPA(text)
+		if err != nil {
+			return err
+		}
+		phones = voice.TimePhones(plain)
+		gospeech.ApplyContour(phones, gospeech.StatementContour)
+	} else {
+		dict, err := loadDictionary(*dictPath, *cmuDictPath)
+		if err != nil {
+			return err
+		}
+		frontend := &gospeech.TextFrontend{Dictionary: dict, Voice: voice}
+		phones, err = frontend.TimedPhones(text)
+		if err != nil {
+			return err
+		}
+	}
+
+	track := voice.TimedTrack(phones)
+	opts := tracks.EncodeOptions{SampleRate: *sampleRate, BitDepth: 16, Channels: 1}
+	if *output == "-" {
+		return tracks.WriteWAV(track, stdout, opts)
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = tracks.WriteWAV(track, f, opts)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// loadVoice finds a voice by name, or else loads a voice config from a path.
+func loadVoice(nameOrPath string) (gospeech.Voice, error) {
+	if voice, ok := voices[nameOrPath]; ok {
+		return voice(), nil
+	}
+	f, err := os.Open(nameOrPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return gospeech.Voice{}, errors.New("unknown voice: " + nameOrPath)
+		}
+		return gospeech.Voice{}, err
+	}
+	defer f.Close()
+	voice, err := gospeech.LoadVoice(f)
+	if err != nil {
+		return gospeech.Voice{}, errors.New("load voice " + nameOrPath + ": " + err.Error())
+	}
+	return *voice, nil
+}
+
+// loadDictionary loads the dictionary given by the flags, which default to the sample
+// dictionary.
+func loadDictionary(path, cmuPath string) (gospeech.Dictionary, error) {
+	switch {
+	case path != "" && cmuPath != "":
+		return nil, errors.New("-dict and -cmudict cannot be used together")
+	case path != "":
+		return gospeech.LoadDictionary(path)
+	case cmuPath != "":
+		return gospeech.LoadCMUDictionary(cmuPath)
+	default:
+		return gospeech.SampleDictionary(), nil
+	}
+}
+
+// inputText joins the text arguments, or reads stdin if there are none.
+func inputText(args []string, stdin io.Reader) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+	data, err := io.ReadAll(stdin)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == "" {
+		return "", errors.New("no input text")
+	}
+	return text, nil
+}
+
+// voiceNames lists the names of the voices, separated by commas.
+func voiceNames() string {
+	var names []string
+	for name := range voices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/unixpickle/gospeech"
+	"github.com/unixpickle/gospeech/tracks"
+	"github.com/unixpickle/wav"
+)
+
+func TestRunWAV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Run([]string{"-o", "-", "-samplerate", "16000", "hello", "world"}, nil,
+		&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if len(data) < 44 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		t.Fatalf("expected a WAV header but got %q", data[:12])
+	}
+	if channels := binary.LittleEndian.Uint16(data[22:]); channels != 1 {
+		t.Errorf("expected 1 channel but got %d", channels)
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:]); rate != 16000 {
+		t.Errorf("expected a sample rate of 16000 but got %d", rate)
+	}
+	if depth := binary.LittleEndian.Uint16(data[34:]); depth != 16 {
+		t.Errorf("expected a bit depth of 16 but got %d", depth)
+	}
+
+	// The audio lasts exactly as long as the speech synthesized by the library.
+	frontend := &gospeech.TextFrontend{Dictionary: gospeech.SampleDictionary(),
+		Voice: gospeech.DefaultVoice}
+	phones, err := frontend.TimedPhones("hello world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	duration := gospeech.DefaultVoice.TimedTrack(phones).Duration()
+	sound, err := wav.ReadSound(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := tracks.SampleCount(duration, 16000); len(sound.Samples()) != n {
+		t.Errorf("expected %d samples for %s but got %d", n, duration, len(sound.Samples()))
+	}
+
+	// Text from stdin and output to a file give the same WAV.
+	path := filepath.Join(t.TempDir(), "out.wav")
+	err = Run([]string{"-o", path, "-samplerate", "16000"}, strings.NewReader("hello world\n"),
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written, err := os.ReadFile(path); err != nil {
+		t.Error(err)
+	} else if !bytes.Equal(written, data) {
+		t.Error("expected the file to match the WAV written to stdout")
+	}
+}
+
+func TestRunFlags(t *testing.T) {
+	length := func(args ...string) int {
+		var buf bytes.Buffer
+		if err := Run(append([]string{"-o", "-", "-samplerate", "8000"}, args...), nil,
+			&buf); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Len()
+	}
+	normal := length("hello", "world")
+	if fast := length("-rate", "2", "hello", "world"); fast >= normal*3/4 {
+		t.Errorf("expected a rate of 2 to shorten %d bytes of WAV, but got %d", normal, fast)
+	}
+	if ipa := length("-ipa", "həˈloʊ"); ipa <= 44 {
+		t.Error("expected IPA input to be synthesized")
+	}
+
+	// A voice config is loaded from a path.
+	voice := gospeech.DefaultVoice
+	voice.Rate = 2
+	path := filepath.Join(t.TempDir(), "voice.json")
+	var config bytes.Buffer
+	if err := voice.Save(&config); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, config.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, fast := length("-voice", path, "hello", "world"),
+		length("-rate", "2", "hello", "world"); loaded != fast {
+		t.Errorf("expected the voice config to speak at its rate, in %d bytes, but got %d", fast,
+			loaded)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, test := range []struct {
+		args  []string
+		stdin string
+		err   string
+	}{
+		{[]string{"-rate", "0", "hello"}, "", "invalid rate: 0"},
+		{[]string{"-samplerate", "-1", "hello"}, "", "invalid sample rate: -1"},
+		{[]string{"-voice", filepath.Join(dir, "missing"), "hello"}, "",
+			"unknown voice: " + filepath.Join(dir, "missing")},
+		{[]string{"-dict", "a.csv", "-cmudict", "b.dict", "hello"}, "",
+			"-dict and -cmudict cannot be used together"},
+		{nil, " \n", "no input text"},
+	} {
+		err := Run(append([]string{"-o", "-"}, test.args...), strings.NewReader(test.stdin),
+			&bytes.Buffer{})
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%v: expected error %q but got %v", test.args, test.err, err)
+		}
+	}
+
+	err := Run([]string{"-bogus"}, nil, &bytes.Buffer{})
+	if err == nil || !strings.HasPrefix(err.Error(), "flag provided but not defined: -bogus\n"+
+		"Usage: gospeech [flags] [text ...]\n") || !strings.Contains(err.Error(), "-rate") {
+		t.Errorf("expected an error with the usage but got %v", err)
+	}
+}
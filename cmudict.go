@@ -0,0 +1,169 @@
+package gospeech
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// arpabetVowels maps ARPAbet vowels, without stress digits, to IPA.
+var arpabetVowels = map[string]string{
+	"AA": "a",
+	"AE": "æ",
+	"AH": "ʌ",
+	"AO": "ɔ",
+	"AW": "aʊ",
+	"AY": "aI",
+	"EH": "ɛ",
+	"ER": "əɹ",
+	"EY": "eI",
+	"IH": "I",
+	"IY": "i",
+	"OW": "oʊ",
+	"OY": "ɔI",
+	"UH": "ʊ",
+	"UW": "u",
+}
+
+// arpabetConsonants maps ARPAbet consonants to IPA.
+var arpabetConsonants = map[string]string{
+	"B":  "b",
+	"CH": "tʃ",
+	"D":  "d",
+	"DH": "ð",
+	"F":  "f",
+	"G":  "g",
+	"HH": "h",
+	"JH": "dʒ",
+	"K":  "k",
+	"L":  "l",
+	"M":  "m",
+	"N":  "n",
+	"NG": "ŋ",
+	"P":  "p",
+	"R":  "ɹ",
+	"S":  "s",
+	"SH": "ʃ",
+	"T":  "t",
+	"TH": "θ",
+	"V":  "v",
+	"W":  "w",
+	"Y":  "j",
+	"Z":  "z",
+	"ZH": "ʒ",
+}
+
+// LoadCMUDictionary reads a file in the format of the CMU Pronouncing Dictionary.
+// See ReadCMUDictionary for details.
+func LoadCMUDictionary(path string) (Dictionary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadCMUDictionary(f)
+}
+
+// ReadCMUDictionary reads a dictionary in the format of the CMU Pronouncing Dictionary, where
+// each line contains a word followed by its ARPAbet phones.
+//
+// The ARPAbet is converted to IPA with stress marks, so the result can be used like any other
+// Dictionary.
+// Comment lines starting with ";;;" are skipped, and only the first pronunciation of each word is
+// kept.
+func ReadCMUDictionary(r io.Reader) (Dictionary, error) {
+	res := Dictionary{}
+	scanner := bufio.NewScanner(r)
+	var lineNum int
+	for scanner.Scan() {
+		lineNum++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], ";;;") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, errors.New("unexpected string at line: " + strconv.Itoa(lineNum))
+		}
+		word := strings.ToLower(fields[0])
+		if strings.HasSuffix(word, ")") {
+			// Alternate pronunciations, such as "word(2)", follow the main entry.
+			continue
+		}
+		ipa, err := ARPAbetToIPA(fields[1:])
+		if err != nil {
+			return nil, errors.New(err.Error() + " at line: " + strconv.Itoa(lineNum))
+		}
+		res[word] = ipa
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// ARPAbetToIPA converts a sequence of ARPAbet phones, such as "HH AH0 L OW1", to IPA.
+//
+// The stress digits of the vowels become stress marks at the start of their syllables, and
+// unstressed syllables after the first are separated by periods.
+// An unstressed AH is a schwa.
+// Each syllable's onset is the consonant right before its vowel, or every leading consonant for
+// the first syllable.
+func ARPAbetToIPA(phones []string) (string, error) {
+	type syllable struct {
+		stress string
+		phones []string
+	}
+	var syllables []*syllable
+	var pending []string
+	for _, phone := range phones {
+		if ipa, ok := arpabetConsonants[phone]; ok {
+			pending = append(pending, ipa)
+			continue
+		}
+		base := strings.TrimRight(phone, "012")
+		ipa, ok := arpabetVowels[base]
+		if !ok || len(base) < len(phone)-1 {
+			return "", errors.New("unknown ARPAbet phone: " + phone)
+		}
+		digit := phone[len(base):]
+		if base == "AH" && digit == "0" {
+			ipa = "ə"
+		}
+
+		onset := pending
+		if len(syllables) > 0 && len(pending) > 0 {
+			last := syllables[len(syllables)-1]
+			last.phones = append(last.phones, pending[:len(pending)-1]...)
+			onset = pending[len(pending)-1:]
+		}
+		stress := "."
+		if digit == "1" {
+			stress = "ˈ"
+		} else if digit == "2" {
+			stress = "ˌ"
+		} else if len(syllables) == 0 {
+			stress = ""
+		}
+		syllables = append(syllables, &syllable{
+			stress: stress,
+			phones: append(append([]string{}, onset...), ipa),
+		})
+		pending = nil
+	}
+
+	if len(syllables) == 0 {
+		return strings.Join(pending, ""), nil
+	}
+	last := syllables[len(syllables)-1]
+	last.phones = append(last.phones, pending...)
+
+	var res strings.Builder
+	for _, s := range syllables {
+		res.WriteString(s.stress)
+		res.WriteString(strings.Join(s.phones, ""))
+	}
+	return res.String(), nil
+}
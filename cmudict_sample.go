@@ -0,0 +1,43 @@
+package gospeech
+
+import "strings"
+
+// sampleCMUDictionary is a small excerpt of the CMU Pronouncing Dictionary.
+const sampleCMUDictionary = `;;; A small subset of cmudict for examples and tests.
+A  AH0
+AM  AE1 M
+ARE  AA1 R
+COMPUTER  K AH0 M P Y UW1 T ER0
+COOL  K UW1 L
+DON'T  D OW1 N T
+GOOD  G UH1 D
+HE  HH IY1
+HELLO  HH AH0 L OW1
+HELLO(2)  HH EH0 L OW1
+HOW  HH AW1
+I  AY1
+IS  IH1 Z
+MORNING  M AO1 R N IH0 NG
+NO  N OW1
+ONE  W AH1 N
+SPEECH  S P IY1 CH
+SYNTHESIS  S IH1 N TH AH0 S AH0 S
+TEST  T EH1 S T
+THE  DH AH0
+THIS  DH IH1 S
+THREE  TH R IY1
+TWO  T UW1
+WORLD  W ER1 L D
+YES  Y EH1 S
+YOU  Y UW1
+`
+
+// SampleDictionary returns a small dictionary of common English words, which is handy for
+// examples and tests that should not depend on a dictionary file.
+func SampleDictionary() Dictionary {
+	dict, err := ReadCMUDictionary(strings.NewReader(sampleCMUDictionary))
+	if err != nil {
+		panic(err)
+	}
+	return dict
+}
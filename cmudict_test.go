@@ -0,0 +1,105 @@
+package gospeech
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestARPAbetToIPA(t *testing.T) {
+	for _, test := range []struct {
+		arpabet  string
+		expected string
+	}{
+		{"HH AH0 L OW1", "həˈloʊ"},
+		{"W ER1 L D", "ˈwəɹld"},
+		{"K AH0 M P Y UW1 T ER0", "kəmpˈju.təɹ"},
+		{"S IH1 N TH AH0 S AH0 S", "ˈsIn.θə.səs"},
+		{"DH AH0", "ðə"},
+		{"W AH1 N", "ˈwʌn"},
+		{"S P IY1 CH", "ˈspitʃ"},
+		{"JH AH1 M P", "ˈdʒʌmp"},
+		{"EH2 K", "ˌɛk"},
+		{"AY1", "ˈaI"},
+		{"M AO1 R N IH0 NG", "ˈmɔɹ.nIŋ"},
+		{"HH M", "hm"},
+		{"", ""},
+	} {
+		actual, err := ARPAbetToIPA(strings.Fields(test.arpabet))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.arpabet, err)
+		} else if actual != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.arpabet, test.expected, actual)
+		}
+	}
+
+	for _, phone := range []string{"AX1", "AH12", "Q", "ah1"} {
+		if _, err := ARPAbetToIPA([]string{"K", phone}); err == nil {
+			t.Errorf("expected an error for %q", phone)
+		} else if err.Error() != "unknown ARPAbet phone: "+phone {
+			t.Errorf("%q: unexpected error: %s", phone, err)
+		}
+	}
+}
+
+func TestReadCMUDictionary(t *testing.T) {
+	contents := ";;; A comment\n\nHELLO  HH AH0 L OW1\nHELLO(2)  HH EH0 L OW1\n" +
+		"DON'T  D OW1 N T\n"
+	dict, err := ReadCMUDictionary(strings.NewReader(contents))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Dictionary{"hello": "həˈloʊ", "don't": "ˈdoʊnt"}
+	if len(dict) != len(expected) {
+		t.Fatalf("expected %v but got %v", expected, dict)
+	}
+	for word, ipa := range expected {
+		if dict[word] != ipa {
+			t.Errorf("%q: expected %q but got %q", word, ipa, dict[word])
+		}
+	}
+
+	for _, test := range []struct {
+		contents string
+		err      string
+	}{
+		{"HELLO  HH AH0 L OW1\nWORLD\n", "unexpected string at line: 2"},
+		{";;; comment\nHELLO  HH AX0 L OW1\n", "unknown ARPAbet phone: AX0 at line: 2"},
+	} {
+		if _, err := ReadCMUDictionary(strings.NewReader(test.contents)); err == nil {
+			t.Errorf("%q: expected an error", test.contents)
+		} else if err.Error() != test.err {
+			t.Errorf("%q: expected error %q but got %q", test.contents, test.err, err)
+		}
+	}
+}
+
+func TestLoadCMUDictionary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cmudict")
+	if err := os.WriteFile(path, []byte("TWO  T UW1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadCMUDictionary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dict) != 1 || dict["two"] != "ˈtu" {
+		t.Errorf("unexpected dictionary: %v", dict)
+	}
+	if _, err := LoadCMUDictionary(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestSampleDictionaryParses(t *testing.T) {
+	dict := SampleDictionary()
+	if len(dict) == 0 {
+		t.Fatal("expected a non-empty dictionary")
+	}
+	for word := range dict {
+		if _, ok := dict.Lookup(word); !ok {
+			t.Errorf("the IPA for %q does not parse: %q", word, dict[word])
+		}
+	}
+}
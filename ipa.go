@@ -0,0 +1,220 @@
+package gospeech
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Stress is the stress level of a syllable.
+type Stress int
+
+const (
+	Unstressed Stress = iota
+	SecondaryStress
+	PrimaryStress
+)
+
+// An IPAPhone is a Phone produced by parsing IPA, annotated with the marks that surrounded it.
+type IPAPhone struct {
+	Phone
+
+	// Symbol is the key of the phone in the voice's Phones.
+	Symbol string
+
+	// Stress is the stress of the syllable containing the phone.
+	Stress Stress
+
+	// Syllable is the index of the syllable containing the phone within its word.
+	Syllable int
+
+	Long      bool
+	Nasalized bool
+	Aspirated bool
+}
+
+// A WordBreak is a Phone which separates words.
+// It ends the current word with a short pause.
+type WordBreak struct{}
+
+func (w WordBreak) EncodeBeginning(system VocalSystem, lastPhone, nextPhone Phone) {
+	system.AdjustVolume(0, time.Millisecond*50)
+	system.Continue(time.Millisecond * 300)
+}
+
+func (w WordBreak) FormantPull(nextFormant FormantState) FormantState {
+	return nextFormant
+}
+
+func (w WordBreak) TransitionTime() time.Duration {
+	return 0
+}
+
+// ipaAliases maps IPA symbols onto the equivalent symbols used by the phone tables.
+var ipaAliases = map[rune]string{
+	'ɪ': "I",
+	'ɡ': "g",
+	'r': "ɹ",
+	'ɑ': "a",
+	'ɒ': "ɔ",
+	'ʧ': "tʃ",
+	'ʤ': "dʒ",
+	'ɚ': "əɹ",
+	'ɝ': "əɹ",
+	'ɜ': "ə",
+	'ɐ': "ə",
+}
+
+const (
+	ipaPrimaryStress   = 'ˈ'
+	ipaSecondaryStress = 'ˌ'
+	ipaSyllableBreak   = '.'
+	ipaLong            = 'ː'
+	ipaHalfLong        = 'ˑ'
+	ipaAspirated       = 'ʰ'
+	ipaNasalized       = '̃'
+	ipaSyllabic        = '̩'
+	ipaNonSyllabic     = '̯'
+	ipaTieAbove        = '͡'
+	ipaTieBelow        = '͜'
+)
+
+// ParseIPA parses an IPA transcription into phones of the DefaultVoice.
+// See Voice.ParseIPA for details.
+func ParseIPA(s string) ([]Phone, error) {
+	return DefaultVoice.ParseIPA(s)
+}
+
+// ParseIPA parses an IPA transcription into a sequence of the voice's phones.
+//
+// Every phone is an *IPAPhone, and words are separated by WordBreaks.
+// Symbols spanning several code points, such as affricates, are matched against the longest
+// entry in the voice's Phones, and affricates joined by a tie bar which the voice lacks become
+// separate phones.
+// Stress marks apply to the syllable they precede, and syllables are only split at stress marks
+// and periods.
+//
+// Unknown symbols produce an error naming the rune and its byte offset.
+func (v Voice) ParseIPA(s string) ([]Phone, error) {
+	var res []Phone
+	var syllable int
+	var stress Stress
+	var wordLen int
+	var tied bool
+
+	maxSymbolLen := 1
+	for symbol := range v.Phones {
+		if n := utf8.RuneCountInString(symbol); n > maxSymbolLen {
+			maxSymbolLen = n
+		}
+	}
+
+	runes, offsets := normalizeIPA(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		var last *IPAPhone
+		if wordLen > 0 {
+			last = res[len(res)-1].(*IPAPhone)
+		}
+		switch {
+		case unicode.IsSpace(r):
+			if wordLen > 0 {
+				res = append(res, WordBreak{})
+			}
+			wordLen, syllable, stress = 0, 0, Unstressed
+			continue
+		case r == ipaPrimaryStress || r == ipaSecondaryStress || r == ipaSyllableBreak:
+			if wordLen > 0 {
+				syllable++
+			}
+			stress = Unstressed
+			if r == ipaPrimaryStress {
+				stress = PrimaryStress
+			} else if r == ipaSecondaryStress {
+				stress = SecondaryStress
+			}
+			continue
+		case r == ipaTieAbove || r == ipaTieBelow:
+			tied = true
+			if last == nil {
+				return nil, ipaError(r, offsets[i])
+			}
+			continue
+		case r == ipaLong || r == ipaHalfLong || r == ipaAspirated || r == ipaNasalized ||
+			r == ipaSyllabic || r == ipaNonSyllabic:
+			if last == nil {
+				return nil, ipaError(r, offsets[i])
+			}
+			switch r {
+			case ipaLong, ipaHalfLong:
+				last.Long = true
+			case ipaAspirated:
+				last.Aspirated = true
+			case ipaNasalized:
+				last.Nasalized = true
+			}
+			continue
+		}
+
+		var symbol string
+		for n := maxSymbolLen; n > 0 && symbol == ""; n-- {
+			if i+n <= len(runes) {
+				if _, ok := v.Phones[string(runes[i:i+n])]; ok {
+					symbol = string(runes[i : i+n])
+				}
+			}
+		}
+		if symbol == "" {
+			return nil, ipaError(r, offsets[i])
+		}
+		if tied && last != nil {
+			if _, ok := v.Phones[last.Symbol+symbol]; ok {
+				last.Symbol += symbol
+				last.Phone = v.Phones[last.Symbol]
+				i += utf8.RuneCountInString(symbol) - 1
+				tied = false
+				continue
+			}
+		}
+		tied = false
+		res = append(res, &IPAPhone{
+			Phone:    v.Phones[symbol],
+			Symbol:   symbol,
+			Stress:   stress,
+			Syllable: syllable,
+		})
+		wordLen++
+		i += utf8.RuneCountInString(symbol) - 1
+	}
+
+	if len(res) > 0 {
+		if _, ok := res[len(res)-1].(WordBreak); ok {
+			res = res[:len(res)-1]
+		}
+	}
+	return res, nil
+}
+
+// normalizeIPA replaces aliased symbols in an IPA string, returning the resulting runes along
+// with the byte offset of the symbol each rune came from.
+func normalizeIPA(s string) (runes []rune, offsets []int) {
+	for offset, r := range s {
+		if alias, ok := ipaAliases[r]; ok {
+			for _, aliasRune := range alias {
+				runes = append(runes, aliasRune)
+				offsets = append(offsets, offset)
+			}
+		} else {
+			runes = append(runes, r)
+			offsets = append(offsets, offset)
+		}
+	}
+	return
+}
+
+func ipaError(r rune, offset int) error {
+	return errors.New("unknown IPA symbol " + strconv.QuoteRune(r) + " at byte " +
+		strconv.Itoa(offset))
+}
@@ -0,0 +1,157 @@
+package gospeech
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseIPA(t *testing.T) {
+	// Each phone is written as its symbol, syllable and stress, followed by any of the letters
+	// L, N and A for long, nasalized and aspirated phones.
+	// Word breaks are written as "|".
+	for _, test := range []struct {
+		ipa      string
+		expected string
+	}{
+		{"", ""},
+		{"  ", ""},
+		{"ˈ", ""},
+		{"a", "a:0:0"},
+		{"  hi  ", "h:0:0 i:0:0"},
+		{"ˈaɪ", "aI:0:2"},
+		{"aʊ", "aʊ:0:0"},
+		{"eɪoʊɔɪ", "eI:0:0 oʊ:0:0 ɔI:0:0"},
+		{"həˈloʊ", "h:0:0 ə:0:0 l:1:2 oʊ:1:2"},
+		{"ˌɪntɚˈnæʃənəl", "I:0:1 n:0:1 t:0:1 ə:0:1 ɹ:0:1 n:1:2 æ:1:2 ʃ:1:2 ə:1:2 n:1:2 " +
+			"ə:1:2 l:1:2"},
+		{"bʌ.tɚ", "b:0:0 ʌ:0:0 t:1:0 ə:1:0 ɹ:1:0"},
+		{"ˈɹɛd.ˌhɛd", "ɹ:0:2 ɛ:0:2 d:0:2 h:2:1 ɛ:2:1 d:2:1"},
+		{".ˈa", "a:0:2"},
+		{"ɡʊd ˈdeɪ", "g:0:0 ʊ:0:0 d:0:0 | d:0:2 eI:0:2"},
+		{"ˈa ˈb ", "a:0:2 | b:0:2"},
+		{"a \t\n b", "a:0:0 | b:0:0"},
+		{"ˈwɝld", "w:0:2 ə:0:2 ɹ:0:2 l:0:2 d:0:2"},
+		{"rɑt", "ɹ:0:0 a:0:0 t:0:0"},
+		{"ɒn", "ɔ:0:0 n:0:0"},
+		{"ɐbaʊt", "ə:0:0 b:0:0 aʊ:0:0 t:0:0"},
+		{"ɜ", "ə:0:0"},
+		{"ʧɪp", "t:0:0 ʃ:0:0 I:0:0 p:0:0"},
+		{"ʤʌmp", "d:0:0 ʒ:0:0 ʌ:0:0 m:0:0 p:0:0"},
+		{"t\u0361ʃɪp", "t:0:0 ʃ:0:0 I:0:0 p:0:0"},
+		{"d\u035cʒ", "d:0:0 ʒ:0:0"},
+		{"a\u0361", "a:0:0"},
+		{"kʰæt", "k:0:0A æ:0:0 t:0:0"},
+		{"fiː", "f:0:0 i:0:0L"},
+		{"fiˑ", "f:0:0 i:0:0L"},
+		{"bʊ\u0303k", "b:0:0 ʊ:0:0N k:0:0"},
+		{"fɹa\u0303ː", "f:0:0 ɹ:0:0 a:0:0LN"},
+		{"pʰa\u0303ː", "p:0:0A a:0:0LN"},
+		{"n\u0329", "n:0:0"},
+		{"aɪ\u032f", "aI:0:0"},
+		{"ʔʌʔoʊ", "ʔ:0:0 ʌ:0:0 ʔ:0:0 oʊ:0:0"},
+		{"ɾŋθðvzʒjw", "ɾ:0:0 ŋ:0:0 θ:0:0 ð:0:0 v:0:0 z:0:0 ʒ:0:0 j:0:0 w:0:0"},
+	} {
+		phones, err := ParseIPA(test.ipa)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.ipa, err)
+			continue
+		}
+		if actual := describeIPA(phones); actual != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.ipa, test.expected, actual)
+		}
+		for _, phone := range phones {
+			if p, ok := phone.(*IPAPhone); ok && p.Phone != DefaultVoice.Phones[p.Symbol] {
+				t.Errorf("%q: phone %s does not match its symbol", test.ipa, p.Symbol)
+			}
+		}
+	}
+}
+
+func TestParseIPAErrors(t *testing.T) {
+	for _, test := range []struct {
+		ipa    string
+		symbol rune
+		offset int
+	}{
+		{"x", 'x', 0},
+		{"həˈloʊx", 'x', 9},
+		{"ab dq", 'q', 4},
+		{"\u00e3", '\u00e3', 0},
+		{"fɹ\u00e3", '\u00e3', 3},
+		{"ːa", 'ː', 0},
+		{"ʰa", 'ʰ', 0},
+		{"a \u0303", '\u0303', 2},
+		{"\u0361ʃ", '\u0361', 0},
+		{"a \u035cʃ", '\u035c', 2},
+		{"ˈ1", '1', 2},
+		{"ɚx", 'x', 2},
+	} {
+		phones, err := ParseIPA(test.ipa)
+		if err == nil {
+			t.Errorf("%q: expected an error but got %q", test.ipa, describeIPA(phones))
+			continue
+		}
+		expected := "unknown IPA symbol " + strconv.QuoteRune(test.symbol) + " at byte " +
+			strconv.Itoa(test.offset)
+		if err.Error() != expected {
+			t.Errorf("%q: expected error %q but got %q", test.ipa, expected, err)
+		}
+	}
+}
+
+func TestParseIPATiedAffricate(t *testing.T) {
+	voice := DefaultVoice
+	voice.Phones = map[string]Phone{}
+	for symbol, phone := range DefaultVoice.Phones {
+		voice.Phones[symbol] = phone
+	}
+	voice.Phones["tʃ"] = DefaultVoice.Phones["ʃ"]
+
+	for _, test := range []struct {
+		ipa      string
+		expected string
+	}{
+		{"t\u0361ʃɪp", "tʃ:0:0 I:0:0 p:0:0"},
+		{"tʃɪp", "tʃ:0:0 I:0:0 p:0:0"},
+		{"ʧɪp", "tʃ:0:0 I:0:0 p:0:0"},
+		{"ˈt\u0361ʃʰ", "tʃ:0:2A"},
+		{"d\u0361ʒ", "d:0:0 ʒ:0:0"},
+	} {
+		phones, err := voice.ParseIPA(test.ipa)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.ipa, err)
+		} else if actual := describeIPA(phones); actual != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.ipa, test.expected, actual)
+		}
+	}
+}
+
+// describeIPA formats parsed phones in the notation of TestParseIPA, with pauses written as their
+// durations in parentheses.
+func describeIPA(phones []Phone) string {
+	var parts []string
+	for _, phone := range phones {
+		if pause, ok := phone.(Pause); ok {
+			parts = append(parts, "("+pause.Duration.String()+")")
+			continue
+		}
+		p, ok := phone.(*IPAPhone)
+		if !ok {
+			parts = append(parts, "|")
+			continue
+		}
+		part := p.Symbol + ":" + strconv.Itoa(p.Syllable) + ":" + strconv.Itoa(int(p.Stress))
+		if p.Long {
+			part += "L"
+		}
+		if p.Nasalized {
+			part += "N"
+		}
+		if p.Aspirated {
+			part += "A"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
@@ -1,6 +1,7 @@
 package gospeech
 
 import (
+	"math"
 	"time"
 
 	"github.com/unixpickle/gospeech/tracks"
@@ -54,6 +55,61 @@ func (v Vowel) TransitionTime() time.Duration {
 	return v.Duration / 2
 }
 
+// diphthongGlideSteps is the number of linear pieces which approximate the eased glide of a
+// Diphthong.
+const diphthongGlideSteps = 16
+
+// A Diphthong is a vowel whose formants glide from one target to another, as in "aɪ" or "oʊ".
+type Diphthong struct {
+	Start    FormantState
+	End      FormantState
+	Duration time.Duration
+
+	// Glide is the fraction of the duration spent gliding from Start to End.
+	Glide float64
+}
+
+func (d Diphthong) EncodeBeginning(system VocalSystem, lastPhone, nextPhone Phone) {
+	glideTime := time.Duration(float64(d.Duration) * d.Glide)
+	steadyTime := d.Duration - glideTime
+	if lastPhone != nil {
+		startFormant := lastPhone.FormantPull(d.Start)
+		system.AdjustFormants(startFormant, d.Duration/6)
+		system.AdjustFormants(d.Start, lastPhone.TransitionTime())
+	} else {
+		startFormants := d.Start
+		startFormants.Volumes = [3]float64{}
+		system.AdjustFormants(startFormants, d.Duration/6)
+		system.AdjustFormants(d.Start, d.Duration/2)
+	}
+	system.FormantsTrack().Continue(steadyTime / 3)
+	system.Turbulence().AdjustVolume(0, d.Duration/4)
+	system.ConsonantVoice().AdjustVolume(0, d.Duration/3)
+	system.Liquid().AdjustVolume(0, d.Duration/3)
+
+	// The glide eases in and out, so the formants do not turn corners at either end.
+	for i := 1; i <= diphthongGlideSteps; i++ {
+		frac := (1 - math.Cos(math.Pi*float64(i)/diphthongGlideSteps)) / 2
+		var state FormantState
+		for j := 0; j < 3; j++ {
+			state.Frequencies[j] = d.Start.Frequencies[j]*(1-frac) + d.End.Frequencies[j]*frac
+			state.Volumes[j] = d.Start.Volumes[j]*(1-frac) + d.End.Volumes[j]*frac
+		}
+		system.AdjustFormants(state, glideTime/diphthongGlideSteps)
+	}
+	system.EvenOut()
+}
+
+// FormantPull returns the end target, so that a following vowel glides on from it without
+// resetting.
+func (d Diphthong) FormantPull(nextFormant FormantState) FormantState {
+	return d.End
+}
+
+func (d Diphthong) TransitionTime() time.Duration {
+	return d.Duration / 2
+}
+
 // A BilabialPlosive represents a "b" or "p" sound.
 type BilabialPlosive struct {
 	Voiced bool
@@ -177,14 +233,30 @@ func (v VelarPlosive) TransitionTime() time.Duration {
 	return time.Millisecond * 30
 }
 
+// nasalAntiFormantDepth is the depth of the anti-formant in the murmur of a nasal.
+const nasalAntiFormantDepth = 0.9
+
 // A Nasal represents an "n", "m", or "ng" sound.
 type Nasal struct {
 	// Type is either "n", "m", or "ng", and dictates the formant pull technique.
 	Type     string
 	Formants FormantState
+
+	// AntiFormant and AntiFormantBandwidth are the center and bandwidth of the spectral zero
+	// which the nasal cavity cuts into the murmur, in Hz.
+	// If they are 0, defaults for the Type are used.
+	AntiFormant          float64
+	AntiFormantBandwidth float64
 }
 
 func (n Nasal) EncodeBeginning(system VocalSystem, lastPhone, nextPhone Phone) {
+	antiFormant := system.AntiFormant()
+	center, bandwidth := n.antiFormant()
+	if antiFormant.Depth() == 0 {
+		antiFormant.GlideNotch(center, bandwidth, 0, 0)
+	}
+	start := system.FormantsTrack().Duration()
+
 	if system.FormantsTrack().Volume() > 0 {
 		system.AdjustFormants(n.FormantPull(system.Formants()), time.Millisecond*50)
 	}
@@ -193,8 +265,34 @@ func (n Nasal) EncodeBeginning(system VocalSystem, lastPhone, nextPhone Phone) {
 	system.Liquid().AdjustVolume(0, time.Millisecond*50)
 
 	system.AdjustFormants(n.Formants, time.Millisecond*50)
+
+	// The anti-formant fades in over the transition out of the previous phone, so the
+	// nasalization spreads onto it.
+	antiFormant.GlideNotch(center, bandwidth, nasalAntiFormantDepth,
+		system.FormantsTrack().Duration()-start)
 	system.EvenOut()
-	system.Continue(time.Millisecond * 100)
+	system.Continue(time.Millisecond * 50)
+	antiFormant.AdjustNotch(center, bandwidth, 0, time.Millisecond*50)
+	system.EvenOut()
+}
+
+// antiFormant returns the center and bandwidth of the nasal's anti-formant.
+func (n Nasal) antiFormant() (center, bandwidth float64) {
+	center, bandwidth = n.AntiFormant, n.AntiFormantBandwidth
+	if center == 0 {
+		switch n.Type {
+		case "m":
+			center = 1000
+		case "n":
+			center = 1700
+		default:
+			center = 3000
+		}
+	}
+	if bandwidth == 0 {
+		bandwidth = center / 4
+	}
+	return
 }
 
 func (n Nasal) FormantPull(end FormantState) FormantState {
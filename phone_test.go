@@ -0,0 +1,161 @@
+package gospeech
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+func TestDiphthongF2Glide(t *testing.T) {
+	for _, test := range []struct {
+		ipa   string
+		start float64
+		end   float64
+	}{
+		{"aɪ", 1100, 1920},
+		{"oʊ", 700, 1030},
+		{"aɪi", 1100, 2250},
+	} {
+		f2, levels := formantTrajectory(t, test.ipa, 1)
+		if math.Abs(f2[0]-test.start) > 10 {
+			t.Errorf("%q: expected F2 to start at %f but got %f", test.ipa, test.start, f2[0])
+		}
+		if last := f2[len(f2)-1]; math.Abs(last-test.end) > 20 {
+			t.Errorf("%q: expected F2 to end at %f but got %f", test.ipa, test.end, last)
+		}
+		for i := 1; i < len(f2); i++ {
+			if f2[i] < f2[i-1]-2 {
+				t.Errorf("%q: frame %d: F2 fell from %f to %f", test.ipa, i, f2[i-1], f2[i])
+			}
+		}
+
+		// The glide eases in and out, so it moves faster in the middle than at either end.
+		if test.ipa != "aɪi" {
+			var maxStep, firstStep, lastStep float64
+			span := test.end - test.start
+			for i := 1; i < len(f2); i++ {
+				step := f2[i] - f2[i-1]
+				maxStep = math.Max(maxStep, step)
+				if firstStep == 0 && f2[i] > test.start+0.05*span {
+					firstStep = step
+				}
+				if f2[i-1] < test.end-0.05*span {
+					lastStep = step
+				}
+			}
+			if firstStep > maxStep/2 || lastStep > maxStep/2 {
+				t.Errorf("%q: expected gentle steps at the ends but got %f and %f (middle %f)",
+					test.ipa, firstStep, lastStep, maxStep)
+			}
+		}
+
+		// A following vowel continues the glide without the level dropping out.
+		if test.ipa == "aɪi" {
+			var peak float64
+			for _, level := range levels {
+				peak = math.Max(peak, level)
+			}
+			for i, level := range levels[len(levels)/4 : len(levels)*3/4] {
+				if level < peak/2 {
+					t.Errorf("%q: frame %d: the level dipped to %f of %f", test.ipa,
+						i+len(levels)/4, level, peak)
+				}
+			}
+		}
+	}
+}
+
+// formantTrajectory synthesizes IPA and estimates one of its formants in every frame of its
+// spectrogram where three formants are heard.
+// It also returns the strongest magnitude of each of those frames.
+func formantTrajectory(t *testing.T, ipa string, formant int) (freqs, levels []float64) {
+	const sampleRate = 16000
+	track := DefaultVoice.TimedTrack(timeIPA(t, DefaultVoice, ipa))
+	for _, frame := range tracks.TrackSpectrogram(track, sampleRate, 512, 128) {
+		var level float64
+		for _, m := range frame {
+			level = math.Max(level, m)
+		}
+		if level < 0.02 {
+			continue
+		}
+		if estimates := tracks.EstimateFormants(frame, sampleRate); len(estimates) == 3 {
+			freqs = append(freqs, estimates[formant])
+			levels = append(levels, level)
+		}
+	}
+	if len(freqs) == 0 {
+		t.Fatalf("%q: no formants were heard", ipa)
+	}
+	return
+}
+
+func TestNasalAntiFormant(t *testing.T) {
+	const sampleRate = 16000
+	centers := map[string]float64{"m": 1000, "n": 1700, "ŋ": 3000}
+
+	// level measures the murmur at a frequency while the anti-formant is at its full depth.
+	level := func(nasalType string, freq float64) float64 {
+		voice := DefaultVoice
+		voice.Phones = map[string]Phone{"m": Nasal{
+			Type:     nasalType,
+			Formants: NewFormantState(250, 0.3, freq, 0.3, 3800, 0.1),
+		}}
+		track := voice.TimedTrack(timeIPA(t, voice, "m"))
+		frame := tracks.TrackSpectrogram(track, sampleRate, 256, 64)[16]
+		var res float64
+		for k, m := range frame {
+			f := tracks.SpectrogramBinFrequency(k, len(frame), sampleRate)
+			if f > freq*0.95 && f < freq*1.05 {
+				res = math.Max(res, m)
+			}
+		}
+		return res
+	}
+	for nasalType, center := range centers {
+		cut := level(nasalType, center)
+		for other, otherCenter := range centers {
+			if other == nasalType {
+				continue
+			}
+			if kept := level(nasalType, otherCenter); cut > kept/5 {
+				t.Errorf("%s: expected a dip at %f Hz (%f) far below %f Hz (%f)", nasalType,
+					center, cut, otherCenter, kept)
+			}
+			if uncut := level(other, center); cut > uncut/5 {
+				t.Errorf("%s: expected a deeper dip at %f Hz than %s (%f vs %f)", nasalType,
+					center, other, cut, uncut)
+			}
+		}
+	}
+
+	// The nasals of the default voice only differ in their anti-formants, but still sound
+	// different.
+	sounds := map[string][]float64{}
+	for nasal := range centers {
+		track := DefaultVoice.TimedTrack(timeIPA(t, DefaultVoice, "a"+nasal+"a"))
+		for _, sample := range track.Encode(sampleRate) {
+			sounds[nasal] = append(sounds[nasal], float64(sample))
+		}
+	}
+	for a := range sounds {
+		for b := range sounds {
+			if a >= b {
+				continue
+			}
+			if len(sounds[a]) != len(sounds[b]) {
+				t.Fatalf("expected %s and %s to last equally long", a, b)
+			}
+			var diff, norm float64
+			for i, x := range sounds[a] {
+				diff += (x - sounds[b][i]) * (x - sounds[b][i])
+				norm += x * x
+			}
+			if diff < norm*0.01 {
+				t.Errorf("expected %s and %s to sound different, but they differ by %f of %f", a,
+					b, diff, norm)
+			}
+		}
+	}
+}
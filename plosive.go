@@ -0,0 +1,202 @@
+package gospeech
+
+import (
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+const (
+	// plosiveBurstVolume is the peak volume of the burst of a Plosive.
+	plosiveBurstVolume = 0.4
+
+	// plosiveBurstRise is the time the burst of a Plosive takes to reach its peak.
+	plosiveBurstRise = time.Millisecond
+
+	// plosiveAspirationVolume is the volume of the aspiration after the burst of a Plosive.
+	plosiveAspirationVolume = 0.15
+
+	// plosivePrevoicingVolume is the volume of the voice bar heard during the closure of a
+	// prevoiced Plosive.
+	plosivePrevoicingVolume = 0.1
+
+	// plosiveVoicingVolume is the volume which the voice of a voiced Plosive reaches at voicing
+	// onset.
+	plosiveVoicingVolume = 0.3
+
+	// maxPlosiveClosureFade is the longest time the closure of a Plosive takes to silence the
+	// previous phone.
+	maxPlosiveClosureFade = time.Millisecond * 30
+)
+
+// A PlosivePlace is the place of articulation of a Plosive.
+type PlosivePlace int
+
+const (
+	BilabialPlace PlosivePlace = iota
+	AlveolarPlace
+	VelarPlace
+)
+
+// A formantLocus is the frequency, per formant, which the transitions of a place of articulation
+// point toward, as in the locus equations of speech perception research.
+type formantLocus struct {
+	Frequencies [3]float64
+
+	// Slopes are the fractions of the distance from the locus to a vowel's formants at which
+	// the transitions start.
+	Slopes [3]float64
+}
+
+// onset returns the formants at which a transition between the locus and a vowel starts.
+func (f formantLocus) onset(vowel FormantState) FormantState {
+	res := vowel
+	for i, locus := range f.Frequencies {
+		res.Frequencies[i] = locus + f.Slopes[i]*(vowel.Frequencies[i]-locus)
+	}
+	return res
+}
+
+// plosiveLoci are the formant loci of the places of articulation of plosives.
+// Every closure lowers F1, while F2 rises into bilabials, points near 1800 Hz for alveolars, and
+// meets F3 in the "velar pinch" of velars.
+var plosiveLoci = map[PlosivePlace]formantLocus{
+	BilabialPlace: {
+		Frequencies: [3]float64{250, 700, 2100},
+		Slopes:      [3]float64{0.5, 0.75, 0.8},
+	},
+	AlveolarPlace: {
+		Frequencies: [3]float64{250, 1800, 2700},
+		Slopes:      [3]float64{0.5, 0.45, 0.5},
+	},
+	VelarPlace: {
+		Frequencies: [3]float64{250, 2300, 2500},
+		Slopes:      [3]float64{0.5, 0.7, 0.6},
+	},
+}
+
+// A Plosive is a stop consonant which is made of a closure, a burst, and the aspiration before
+// voicing starts.
+type Plosive struct {
+	// Place is the place of articulation, which dictates the formant transitions.
+	Place PlosivePlace
+
+	Voiced bool
+
+	// Closure is the length of the silence while the vocal tract is closed.
+	Closure time.Duration
+
+	// BurstFrequency and BurstBandwidth describe the spectrum of the burst of noise at the
+	// release, in Hz.
+	BurstFrequency float64
+	BurstBandwidth float64
+
+	// BurstDuration is the length of the burst.
+	BurstDuration time.Duration
+
+	// VoiceOnsetTime is the time from the release to the start of voicing.
+	// For voiceless plosives, the time after the burst is filled with aspiration.
+	VoiceOnsetTime time.Duration
+
+	// Prevoiced indicates that voicing continues through the closure.
+	Prevoiced bool
+
+	// UnreleasedFinal indicates that the plosive has no burst or aspiration at the end of a word.
+	UnreleasedFinal bool
+}
+
+// NewPlosive creates a Plosive with typical timing and burst spectrum for its place of
+// articulation.
+// Voiceless plosives get a long voice onset time, and voiced ones a short one with prevoicing.
+func NewPlosive(place PlosivePlace, voiced bool) Plosive {
+	res := Plosive{Place: place, Voiced: voiced, Prevoiced: voiced}
+	switch place {
+	case BilabialPlace:
+		res.BurstFrequency, res.BurstBandwidth = 800, 600
+		res.BurstDuration = time.Millisecond * 8
+		res.VoiceOnsetTime = time.Millisecond * 55
+	case AlveolarPlace:
+		res.BurstFrequency, res.BurstBandwidth = 4000, 1500
+		res.BurstDuration = time.Millisecond * 12
+		res.VoiceOnsetTime = time.Millisecond * 70
+	default:
+		res.BurstFrequency, res.BurstBandwidth = 1800, 500
+		res.BurstDuration = time.Millisecond * 15
+		res.VoiceOnsetTime = time.Millisecond * 80
+	}
+	if voiced {
+		res.Closure = time.Millisecond * 50
+		res.VoiceOnsetTime = time.Millisecond * 15
+	} else {
+		res.Closure = time.Millisecond * 60
+	}
+	return res
+}
+
+func (p Plosive) EncodeBeginning(system VocalSystem, lastPhone, nextPhone Phone) {
+	// The closure silences the previous phone with a formant transition toward the place of
+	// articulation.
+	fade := p.Closure
+	if fade > maxPlosiveClosureFade {
+		fade = maxPlosiveClosureFade
+	}
+	if system.FormantsTrack().Volume() > 0 {
+		system.AdjustFormants(p.FormantPull(system.Formants()), fade)
+	}
+	system.Turbulence().AdjustVolume(0, fade)
+	system.Liquid().AdjustVolume(0, fade)
+	if p.Prevoiced {
+		system.ConsonantVoice().AdjustVolume(plosivePrevoicingVolume, fade)
+	} else {
+		system.ConsonantVoice().AdjustVolume(0, fade)
+	}
+	system.EvenOut()
+	system.Continue(p.Closure - fade)
+
+	if nextPhone == nil && p.UnreleasedFinal {
+		return
+	}
+
+	burst := system.Turbulence()[tracks.TrackID("Burst")].(*tracks.ToneTrack)
+	burst.AdjustAll(p.BurstFrequency, 0, p.BurstBandwidth, 0)
+	burst.AdjustVolume(plosiveBurstVolume, plosiveBurstRise)
+	burst.AdjustVolume(0, p.BurstDuration-plosiveBurstRise)
+	system.EvenOut()
+
+	onset := p.VoiceOnsetTime - p.BurstDuration
+	if onset <= 0 {
+		return
+	}
+	if p.Voiced {
+		system.ConsonantVoice().AdjustVolume(plosiveVoicingVolume, onset)
+	} else {
+		// The aspiration carries on into the next phone, which fades it out.
+		aspiration := system.Turbulence()[tracks.TrackID("H")]
+		aspiration.AdjustVolume(plosiveAspirationVolume, onset/4)
+		aspiration.Continue(onset - onset/4)
+	}
+	system.EvenOut()
+}
+
+// FormantPull points the formants at the locus of the place of articulation and silences them.
+// The same transition leads into the closure from a previous vowel and out of the release into
+// the next one.
+func (p Plosive) FormantPull(end FormantState) FormantState {
+	locus, ok := plosiveLoci[p.Place]
+	if !ok {
+		locus = plosiveLoci[VelarPlace]
+	}
+	res := locus.onset(end)
+	res.Volumes = [3]float64{}
+	return res
+}
+
+func (p Plosive) TransitionTime() time.Duration {
+	switch p.Place {
+	case BilabialPlace:
+		return time.Millisecond * 40
+	case AlveolarPlace:
+		return time.Millisecond * 50
+	}
+	return time.Millisecond * 40
+}
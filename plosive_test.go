@@ -0,0 +1,197 @@
+package gospeech
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+func TestPlosiveTiming(t *testing.T) {
+	structures := map[string]plosiveStructure{}
+	for _, ipa := range []string{"ata", "ada"} {
+		s := measurePlosive(t, DefaultVoice, ipa)
+		structures[ipa] = s
+		plosive := DefaultVoice.Phones[ipa[1:2]].(Plosive)
+
+		// The closure fades the vowel out and holds silence until the release.
+		if s.gap < plosive.Closure-maxPlosiveClosureFade-time.Millisecond*2 ||
+			s.gap > plosive.Closure {
+			t.Errorf("%q: expected a closure gap of about %s but got %s", ipa,
+				plosive.Closure-maxPlosiveClosureFade, s.gap)
+		}
+		if s.burst < -time.Millisecond || s.burst > time.Millisecond*2 {
+			t.Errorf("%q: expected the burst right at the release, but it came at %s", ipa,
+				s.burst)
+		}
+		if s.burstLength < plosive.BurstDuration/2 || s.burstLength > plosive.BurstDuration*2 {
+			t.Errorf("%q: expected a burst of about %s but got %s", ipa, plosive.BurstDuration,
+				s.burstLength)
+		}
+	}
+
+	voiceless, voiced := structures["ata"], structures["ada"]
+	if voiceless.prevoiced || !voiced.prevoiced {
+		t.Errorf("expected only /d/ to be voiced during its closure")
+	}
+	if !voiceless.aspirated || voiced.aspirated {
+		t.Errorf("expected only /t/ to be aspirated")
+	}
+	if voiced.vot > time.Millisecond*20 {
+		t.Errorf("expected a short VOT for /d/ but got %s", voiced.vot)
+	}
+	if voiceless.vot < time.Millisecond*70 || voiceless.vot > time.Millisecond*120 {
+		t.Errorf("expected a long VOT for /t/ but got %s", voiceless.vot)
+	}
+}
+
+func TestPlosiveUnreleasedFinal(t *testing.T) {
+	voice := DefaultVoice
+	voice.Phones = map[string]Phone{}
+	for symbol, phone := range DefaultVoice.Phones {
+		voice.Phones[symbol] = phone
+	}
+	unreleased := NewPlosive(AlveolarPlace, false)
+	unreleased.UnreleasedFinal = true
+	voice.Phones["t"] = unreleased
+
+	for _, test := range []struct {
+		ipa      string
+		released bool
+	}{
+		{"at", false},
+		{"ata", true},
+	} {
+		timed := timeIPA(t, voice, test.ipa)
+		set := voice.TimedTrack(timed).(tracks.TrackSet)
+		turbulence := tracks.RMSSeries(set["Turbulence"], 16000, time.Millisecond)
+		var heard bool
+		for _, level := range turbulence {
+			if level > 0.02 {
+				heard = true
+			}
+		}
+		if heard != test.released {
+			t.Errorf("%q: expected a release %v but got %v", test.ipa, test.released, heard)
+		}
+	}
+}
+
+func TestPlosiveFormantTransitions(t *testing.T) {
+	const sampleRate = 16000
+	steady := DefaultVoice.Phones["a"].(Vowel).Formants.Frequencies[1]
+
+	// transition finds F2 at the edge of the vowel next to the plosive, relative to the steady
+	// state of the vowel.
+	transition := func(ipa string, initial bool) float64 {
+		timed := timeIPA(t, DefaultVoice, ipa)
+		track := DefaultVoice.TimedTrack(timed).(tracks.TrackSet)["Formants"]
+		var f2 []float64
+		for _, frame := range tracks.TrackSpectrogram(track, sampleRate, 256, 80) {
+			var level float64
+			for _, m := range frame {
+				level = math.Max(level, m)
+			}
+			estimates := tracks.EstimateFormants(frame, sampleRate)
+			if level > 0.03 && len(estimates) == 3 {
+				f2 = append(f2, estimates[1])
+			}
+		}
+		if len(f2) == 0 {
+			t.Fatalf("%q: no formants were heard", ipa)
+		}
+		if initial {
+			return f2[0] - steady
+		}
+		return f2[len(f2)-1] - steady
+	}
+
+	// F2 rises out of a bilabial and falls out of an alveolar or velar toward /a/.
+	if d := transition("ba", true); d > -50 {
+		t.Errorf("expected F2 to rise out of /b/, but it started %f Hz from %f", d, steady)
+	}
+	for _, ipa := range []string{"da", "ga"} {
+		if d := transition(ipa, true); d < 150 {
+			t.Errorf("%q: expected F2 to fall, but it started %f Hz from %f", ipa, d, steady)
+		}
+	}
+
+	// The same loci shape the transition into a closure.
+	if d := transition("ab", false); d > -30 {
+		t.Errorf("expected F2 to fall into /b/, but it ended %f Hz from %f", d, steady)
+	}
+	if d := transition("ad", false); d < 100 {
+		t.Errorf("expected F2 to rise into /d/, but it ended %f Hz from %f", d, steady)
+	}
+}
+
+// A plosiveStructure describes the parts of a plosive heard in a synthesized vowel-plosive-vowel
+// sequence.
+// The times of the burst and the voicing are relative to the release.
+type plosiveStructure struct {
+	gap         time.Duration
+	burst       time.Duration
+	burstLength time.Duration
+	vot         time.Duration
+	prevoiced   bool
+	aspirated   bool
+}
+
+// measurePlosive synthesizes a vowel-plosive-vowel sequence and measures the structure of the
+// plosive from the levels of the tracks in 1ms frames.
+// The release is expected after the closure, as scaled by the voice's rate.
+func measurePlosive(t *testing.T, v Voice, ipa string) plosiveStructure {
+	const sampleRate = 16000
+	timed := timeIPA(t, v, ipa)
+	plosive := timed[1].Phone.(*IPAPhone).Phone.(Plosive)
+	release := int((timed[1].Start + v.scaleArticulation(plosive.Closure)) / time.Millisecond)
+
+	set := v.TimedTrack(timed).(tracks.TrackSet)
+	mix := tracks.RMSSeries(set, sampleRate, time.Millisecond)
+	noise := tracks.RMSSeries(set["Turbulence"], sampleRate, time.Millisecond)
+	formants := tracks.RMSSeries(set["Formants"], sampleRate, time.Millisecond)
+	voice := tracks.RMSSeries(set["ConsonantVoice"], sampleRate, time.Millisecond)
+	ms := func(frames int) time.Duration {
+		return time.Duration(frames) * time.Millisecond
+	}
+
+	var res plosiveStructure
+	for i := release - 1; i > 0 && mix[i] < 0.005; i-- {
+		res.gap += time.Millisecond
+	}
+	if res.gap == 0 {
+		// A prevoiced closure is only silent in the formants and noise.
+		for i := release - 1; i > 0 && formants[i] < 0.005 && noise[i] < 0.005; i-- {
+			res.gap += time.Millisecond
+		}
+	}
+	for i := release - int(res.gap/time.Millisecond); i < release; i++ {
+		if voice[i] > 0.03 {
+			res.prevoiced = true
+		}
+	}
+
+	start := release - 5
+	for start < len(noise) && noise[start] < 0.1 {
+		start++
+	}
+	end := start
+	for end < len(noise) && noise[end] >= 0.1 {
+		end++
+	}
+	res.burst, res.burstLength = ms(start-release), ms(end-start)
+
+	// Aspiration is noise which lasts well past the burst, until the voicing starts.
+	onset := end
+	var aspiration int
+	for onset < len(formants) && formants[onset] < 0.02 && voice[onset] < 0.1 {
+		if noise[onset] > 0.02 {
+			aspiration++
+		}
+		onset++
+	}
+	res.vot = ms(onset - release)
+	res.aspirated = aspiration > 20
+	return res
+}
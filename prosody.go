@@ -0,0 +1,204 @@
+package gospeech
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+	"github.com/unixpickle/wav"
+)
+
+// DefaultPitchRange is the pitch range, in semitones, of a Voice which does not set one.
+const DefaultPitchRange = 4
+
+const (
+	// declinationStart and declinationEnd are the relative pitches at the start and end of a phrase.
+	declinationStart = 0.5
+	declinationEnd   = -0.25
+
+	// finalContourTime is how long the final fall or rise of a contour takes.
+	finalContourTime = time.Millisecond * 300
+)
+
+// A ContourType is the shape of the intonation across a sentence.
+type ContourType int
+
+const (
+	// FlatContour keeps the pitch at the voice's base pitch.
+	FlatContour ContourType = iota
+
+	// StatementContour declines across each phrase and falls to the bottom of the voice's range
+	// at the end.
+	StatementContour
+
+	// QuestionContour declines across each phrase like a statement, but rises to the top of the
+	// voice's range at the end, as in a yes/no question.
+	QuestionContour
+)
+
+// A TimedPhone is a phone along with its place in an utterance and its target pitch.
+type TimedPhone struct {
+	Phone
+
+	// Start is the time at which the phone begins.
+	Start time.Duration
+
+	// Duration is the time the phone lasts, including any silence that ends its word.
+	Duration time.Duration
+
+	// Pitch is the pitch which the phone glides to by its end, relative to the voice.
+	// A pitch of 0 is the voice's base pitch, and 1 and -1 are the top and bottom of its range.
+	Pitch float64
+}
+
+// TimePhones works out when each phone of a sequence would start if it were synthesized, setting
+// every pitch to the voice's base pitch.
+// The timing accounts for the lengthening of stressed vowels and for the voice's rate.
+func (v Voice) TimePhones(phones []Phone) []TimedPhone {
+	res := make([]TimedPhone, len(phones))
+	vocalSystem := v.newVocalSystem()
+	v.encodePhones(vocalSystem, phones, func(i int) {
+		start := vocalSystem.Duration()
+		if i > 0 {
+			res[i-1].Duration = start - res[i-1].Start
+		}
+		if i < len(res) {
+			res[i] = TimedPhone{Phone: phones[i], Start: start}
+		}
+	})
+	return res
+}
+
+// ApplyContour assigns target pitches to the phones of a sentence following an intonation
+// contour.
+//
+// Pauses split the sentence into phrases, and the declination restarts with each phrase.
+// The final fall or rise covers the end of the last phrase.
+func ApplyContour(phones []TimedPhone, contour ContourType) {
+	if len(phones) == 0 {
+		return
+	}
+
+	// The sentence ends with its last phone which is not a break.
+	var end time.Duration
+	for _, phone := range phones {
+		switch phone.Phone.(type) {
+		case WordBreak, Pause:
+		default:
+			end = phone.Start + phone.Duration
+		}
+	}
+
+	phraseStart := phones[0].Start
+	for i, phone := range phones {
+		if _, ok := phone.Phone.(Pause); ok {
+			// The pause glides back up to the start of the next phrase.
+			phraseStart = phone.Start + phone.Duration
+			phones[i].Pitch = contourPitch(contour, declinationStart, 0)
+			continue
+		}
+		phraseEnd := end
+		for _, next := range phones[i:] {
+			if _, ok := next.Phone.(Pause); ok {
+				phraseEnd = next.Start
+				break
+			}
+		}
+
+		t := phone.Start + phone.Duration
+		declination := declinationStart
+		if phraseEnd > phraseStart {
+			frac := math.Min(1, float64(t-phraseStart)/float64(phraseEnd-phraseStart))
+			declination += (declinationEnd - declinationStart) * frac
+		}
+		var final float64
+		if phraseEnd == end && t > end-finalContourTime {
+			final = math.Min(1, float64(t-(end-finalContourTime))/float64(finalContourTime))
+		}
+		phones[i].Pitch = contourPitch(contour, declination, final)
+	}
+}
+
+// contourPitch computes the relative pitch of a contour from its declination and the fraction of
+// the final fall or rise which has elapsed.
+func contourPitch(contour ContourType, declination, final float64) float64 {
+	switch contour {
+	case StatementContour:
+		return declination + (-1-declination)*final
+	case QuestionContour:
+		return declination + (1-declination)*final
+	}
+	return 0
+}
+
+// pitchRange returns the voice's pitch range in semitones.
+func (v Voice) pitchRange() float64 {
+	if v.PitchRange == 0 {
+		return DefaultPitchRange
+	}
+	return v.PitchRange
+}
+
+// SynthesizeTimed synthesizes a sequence of phones, gliding the pitch to the target of each phone
+// over the course of the phone.
+// The pitches are scaled to the voice's base pitch and range, and stressed vowels are raised
+// above them by the pitch accent of their stress.
+//
+// The phones are timed as they are synthesized, so their Start and Duration are ignored.
+func (v Voice) SynthesizeTimed(phones []TimedPhone) wav.Sound {
+	sound, _ := v.SynthesizeAligned(phones)
+	return sound
+}
+
+// SynthesizeAligned is like SynthesizeTimed, but it also returns when each phone and word of the
+// synthesized sound is heard.
+// The times account for the voice's rate, stress lengthening, and pauses, and they map onto
+// samples at any sample rate through PhoneTiming.Samples.
+func (v Voice) SynthesizeAligned(phones []TimedPhone) (wav.Sound, *Alignment) {
+	vocalSystem, alignment := v.encodeTimed(phones)
+	return v.encodeSound(vocalSystem), alignment
+}
+
+// TimedTrack is like SynthesizeTimed, but it returns the speech as a track rather than encoding
+// it, so that it can be encoded at any sample rate, streamed, or mixed with other tracks.
+func (v Voice) TimedTrack(phones []TimedPhone) tracks.Track {
+	vocalSystem, _ := v.encodeTimed(phones)
+	return v.mixTrack(vocalSystem)
+}
+
+// encodeTimed encodes timed phones into a new VocalSystem, as in SynthesizeAligned.
+func (v Voice) encodeTimed(phones []TimedPhone) (VocalSystem, *Alignment) {
+	vocalSystem := v.newVocalSystem()
+	base := vocalSystem.Pitch()
+	frequency := func(phone TimedPhone) float64 {
+		semitones := phone.Pitch*v.pitchRange() + v.pitchAccent(phone.Phone)
+		return base * math.Pow(2, semitones/12)
+	}
+
+	plain := make([]Phone, len(phones))
+	for i, phone := range phones {
+		plain[i] = phone.Phone
+	}
+	if len(phones) > 0 {
+		vocalSystem.AdjustPitch(frequency(phones[0]), 0)
+	}
+
+	var lastStart time.Duration
+	starts := make([]time.Duration, len(phones)+1)
+	v.encodePhones(vocalSystem, plain, func(i int) {
+		starts[i] = vocalSystem.Duration()
+		start := vocalSystem.ConsonantVoice().Duration()
+		if i > 0 {
+			vocalSystem.GlidePitch(frequency(phones[i-1]), start-lastStart)
+		}
+		lastStart = start
+	})
+	return vocalSystem, newAlignment(plain, starts, vocalSystem.Duration())
+}
+
+// SynthesizeContour synthesizes a sequence of phones with an intonation contour.
+func (v Voice) SynthesizeContour(phones []Phone, contour ContourType) wav.Sound {
+	timed := v.TimePhones(phones)
+	ApplyContour(timed, contour)
+	return v.SynthesizeTimed(timed)
+}
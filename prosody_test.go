@@ -0,0 +1,155 @@
+package gospeech
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+	"github.com/unixpickle/wav"
+)
+
+// contourTestIPA is a word of voiced fricatives, which hum throughout so that the pitch can be
+// followed from start to end.
+const contourTestIPA = "zvzvzvzvzvzvzvzv"
+
+func TestContourF0(t *testing.T) {
+	frequencies := map[ContourType][]float64{}
+	for _, contour := range []ContourType{FlatContour, StatementContour, QuestionContour} {
+		frequencies[contour] = contourFrequencies(t, DefaultVoice, contourTestIPA, contour)
+	}
+	n := len(frequencies[FlatContour])
+	for _, f := range frequencies[FlatContour] {
+		if math.Abs(f-350) > 1 {
+			t.Fatalf("expected a flat contour to stay at 350 Hz but got %f", f)
+		}
+	}
+
+	statement, question := frequencies[StatementContour], frequencies[QuestionContour]
+	if len(statement) != n || len(question) != n {
+		t.Fatalf("expected %d voiced frames but got %d and %d", n, len(statement), len(question))
+	}
+	for i := 1; i < n; i++ {
+		if statement[i] > statement[i-1]+1 {
+			t.Errorf("frame %d: expected the statement to keep falling, but it rose from %f to %f",
+				i, statement[i-1], statement[i])
+		}
+	}
+
+	// Both contours decline alike, and then diverge at the end.
+	top, bottom := 350*math.Pow(2, 4.0/12), 350*math.Pow(2, -4.0/12)
+	if f := statement[0]; f < 380 || f > top {
+		t.Errorf("expected the statement to start above the base pitch but got %f", f)
+	}
+	for i := 0; i < n/2; i++ {
+		if math.Abs(statement[i]-question[i]) > 1 {
+			t.Errorf("frame %d: expected the same declination but got %f and %f", i,
+				statement[i], question[i])
+		}
+	}
+	if f := statement[n-1]; math.Abs(f/bottom-1) > 0.02 {
+		t.Errorf("expected the statement to fall to %f Hz but got %f", bottom, f)
+	}
+	if f := question[n-1]; math.Abs(f/top-1) > 0.02 {
+		t.Errorf("expected the question to rise to %f Hz but got %f", top, f)
+	}
+	lowest := n - 1
+	for i, f := range question {
+		if f < question[lowest] {
+			lowest = i
+		}
+	}
+	if lowest < n/2 || lowest > n-3 {
+		t.Errorf("expected the question to bottom out shortly before its end, not frame %d of %d",
+			lowest, n)
+	}
+}
+
+func TestContourScalesWithVoice(t *testing.T) {
+	voice := DefaultVoice
+	voice.Pitch = 200
+	voice.PitchRange = 12
+	frequencies := contourFrequencies(t, voice, contourTestIPA, StatementContour)
+	if f := frequencies[len(frequencies)-1]; math.Abs(f/100-1) > 0.02 {
+		t.Errorf("expected the statement to fall an octave to 100 Hz but got %f", f)
+	}
+	if f := frequencies[0]; f < 200*math.Pow(2, 5.0/12) || f > 200*math.Pow(2, 6.0/12) {
+		t.Errorf("expected the statement to start about half an octave up but got %f", f)
+	}
+}
+
+func TestContourPhraseReset(t *testing.T) {
+	phones, err := ParseIPA("zvzv zvzv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	phones = append(phones[:4], append([]Phone{Pause{Duration: time.Millisecond * 200}},
+		phones[5:]...)...)
+	timed := DefaultVoice.TimePhones(phones)
+	ApplyContour(timed, StatementContour)
+
+	if _, ok := timed[4].Phone.(Pause); !ok {
+		t.Fatalf("expected phone 4 to be a pause but got %T", timed[4].Phone)
+	}
+	if p := timed[4].Pitch; p != declinationStart {
+		t.Errorf("expected the pause to return to %f but got %f", declinationStart, p)
+	}
+	for _, phrase := range [][]TimedPhone{timed[:4], timed[5:]} {
+		for i := 1; i < len(phrase); i++ {
+			if phrase[i].Pitch >= phrase[i-1].Pitch {
+				t.Errorf("expected each phrase to decline, but got %f then %f",
+					phrase[i-1].Pitch, phrase[i].Pitch)
+			}
+		}
+	}
+	if timed[3].Pitch <= timed[len(timed)-1].Pitch || timed[5].Pitch <= timed[3].Pitch {
+		t.Errorf("expected the declination to restart after the pause, but got %f, %f and %f",
+			timed[3].Pitch, timed[5].Pitch, timed[len(timed)-1].Pitch)
+	}
+	if p := timed[len(timed)-1].Pitch; p != -1 {
+		t.Errorf("expected the statement to end at the bottom of the range but got %f", p)
+	}
+
+	ApplyContour(nil, QuestionContour)
+}
+
+// contourFrequencies synthesizes IPA with a contour and estimates the pitch of its voicing in
+// consecutive 50ms frames, skipping frames where the voicing is not heard.
+func contourFrequencies(t *testing.T, v Voice, ipa string, contour ContourType) []float64 {
+	const sampleRate = 16000
+	phones, err := v.ParseIPA(ipa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timed := v.TimePhones(phones)
+	ApplyContour(timed, contour)
+	voicing := v.TimedTrack(timed).(tracks.TrackSet)["ConsonantVoice"].Encode(sampleRate)
+
+	var res []float64
+	frame := sampleRate / 20
+	for i := frame; i+frame <= len(voicing); i += frame {
+		if f := zeroCrossingFrequency(voicing[i:i+frame], sampleRate); f != 0 {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// zeroCrossingFrequency estimates the frequency of a tone from the spacing of its rising zero
+// crossings, or returns 0 if there are too few of them.
+func zeroCrossingFrequency(samples []wav.Sample, sampleRate int) float64 {
+	var first, last, count int
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			if count == 0 {
+				first = i
+			}
+			last = i
+			count++
+		}
+	}
+	if count < 3 {
+		return 0
+	}
+	return float64(count-1) * float64(sampleRate) / float64(last-first)
+}
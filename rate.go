@@ -0,0 +1,80 @@
+package gospeech
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultMinPause is the shortest that a fast rate shortens a Pause to, unless a Voice sets
+// its own MinPause.
+const DefaultMinPause = time.Millisecond * 100
+
+// rate returns the voice's rate of speech.
+func (v Voice) rate() float64 {
+	if v.Rate <= 0 {
+		return 1
+	}
+	return v.Rate
+}
+
+// scaleSteadyState scales a steady-state duration, such as the body of a vowel, by the rate.
+func (v Voice) scaleSteadyState(d time.Duration) time.Duration {
+	return time.Duration(math.Round(float64(d) / v.rate()))
+}
+
+// scaleArticulation scales the duration of an articulatory gesture, such as a closure.
+// Gestures compress less than steady states, so that fast speech stays intelligible.
+func (v Voice) scaleArticulation(d time.Duration) time.Duration {
+	return time.Duration(math.Round(float64(d) / math.Sqrt(v.rate())))
+}
+
+// applyRate scales the durations of a sequence of phones to the voice's rate.
+//
+// Vowels, diphthongs, and pauses scale fully, and the closures of plosives scale by the square
+// root of the rate.
+// Bursts and voice onset times are left alone, as are the phone types whose timing is fixed.
+// Pauses are never shortened below the voice's MinPause.
+func (v Voice) applyRate(phones []Phone) []Phone {
+	if v.rate() == 1 {
+		return phones
+	}
+	res := make([]Phone, len(phones))
+	for i, phone := range phones {
+		if ipaPhone, ok := phone.(*IPAPhone); ok {
+			scaled := *ipaPhone
+			scaled.Phone = v.scalePhone(ipaPhone.Phone)
+			res[i] = &scaled
+		} else {
+			res[i] = v.scalePhone(phone)
+		}
+	}
+	return res
+}
+
+func (v Voice) scalePhone(phone Phone) Phone {
+	switch phone := phone.(type) {
+	case Vowel:
+		phone.Duration = v.scaleSteadyState(phone.Duration)
+		return phone
+	case Diphthong:
+		phone.Duration = v.scaleSteadyState(phone.Duration)
+		return phone
+	case Plosive:
+		phone.Closure = v.scaleArticulation(phone.Closure)
+		return phone
+	case Pause:
+		minPause := v.MinPause
+		if minPause == 0 {
+			minPause = DefaultMinPause
+		}
+		if minPause > phone.Duration {
+			minPause = phone.Duration
+		}
+		phone.Duration = v.scaleSteadyState(phone.Duration)
+		if phone.Duration < minPause {
+			phone.Duration = minPause
+		}
+		return phone
+	}
+	return phone
+}
@@ -0,0 +1,115 @@
+package gospeech
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateScalesDuration(t *testing.T) {
+	const text = "Hello, how are you? I am good."
+	duration := func(rate float64) time.Duration {
+		voice := DefaultVoice
+		voice.Rate = rate
+		frontend := &TextFrontend{Dictionary: SampleDictionary(), Voice: voice}
+		timed, err := frontend.TimedPhones(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := timed[len(timed)-1]
+		return last.Start + last.Duration
+	}
+
+	normal := duration(1)
+	last := time.Duration(math.MaxInt64)
+	for _, rate := range []float64{0.5, 0.75, 1, 1.5, 2, 3} {
+		d := duration(rate)
+		if d >= last {
+			t.Errorf("rate %f: expected a duration below %s but got %s", rate, last, d)
+		}
+		last = d
+		if rate > 2 {
+			// Consonants and pauses keep most of their length, so very fast speech compresses
+			// less than the rate.
+			continue
+		}
+		if scaled := float64(d) / float64(normal) * rate; math.Abs(scaled-1) > 0.25 {
+			t.Errorf("rate %f: expected a duration near %s but got %s", rate,
+				time.Duration(float64(normal)/rate), d)
+		}
+	}
+	if d := duration(0); d != normal {
+		t.Errorf("expected a rate of 0 to mean 1, but got %s rather than %s", d, normal)
+	}
+}
+
+func TestRateScalesPhones(t *testing.T) {
+	voice := DefaultVoice
+	voice.Rate = 4
+	vowel := voice.scalePhone(DefaultVoice.Phones["a"]).(Vowel)
+	if vowel.Duration != time.Millisecond*50 {
+		t.Errorf("expected the vowel to last 50ms but got %s", vowel.Duration)
+	}
+	original := DefaultVoice.Phones["t"].(Plosive)
+	plosive := voice.scalePhone(original).(Plosive)
+	if plosive.Closure != original.Closure/2 {
+		t.Errorf("expected the closure to halve to %s but got %s", original.Closure/2,
+			plosive.Closure)
+	}
+	if plosive.BurstDuration != original.BurstDuration ||
+		plosive.VoiceOnsetTime != original.VoiceOnsetTime {
+		t.Errorf("expected the burst and VOT to keep their lengths, but got %s and %s",
+			plosive.BurstDuration, plosive.VoiceOnsetTime)
+	}
+
+	for _, test := range []struct {
+		rate     float64
+		minPause time.Duration
+		pause    time.Duration
+		expected time.Duration
+	}{
+		{2, 0, time.Millisecond * 400, time.Millisecond * 200},
+		{4, 0, time.Millisecond * 250, DefaultMinPause},
+		{4, time.Millisecond * 150, time.Millisecond * 400, time.Millisecond * 150},
+		{4, 0, time.Millisecond * 60, time.Millisecond * 60},
+		{0.5, 0, time.Millisecond * 250, time.Millisecond * 500},
+	} {
+		voice := DefaultVoice
+		voice.Rate = test.rate
+		voice.MinPause = test.minPause
+		pause := voice.scalePhone(Pause{Duration: test.pause}).(Pause)
+		if pause.Duration != test.expected {
+			t.Errorf("rate %f, floor %s: expected a %s pause to last %s, but got %s", test.rate,
+				test.minPause, test.pause, test.expected, pause.Duration)
+		}
+	}
+}
+
+func TestRateKeepsBursts(t *testing.T) {
+	for _, ipa := range []string{"apa", "ata", "aka"} {
+		normal := measurePlosive(t, DefaultVoice, ipa)
+		lastGap := time.Duration(math.MaxInt64)
+		for _, rate := range []float64{0.5, 1, 2, 3} {
+			voice := DefaultVoice
+			voice.Rate = rate
+			s := measurePlosive(t, voice, ipa)
+			if s.burstLength < time.Millisecond*3 || s.burstLength > time.Millisecond*20 {
+				t.Errorf("%q at rate %f: the burst lasts an unnatural %s", ipa, rate,
+					s.burstLength)
+			}
+			if diff := s.burstLength - normal.burstLength; diff < -time.Millisecond*2 ||
+				diff > time.Millisecond*2 {
+				t.Errorf("%q at rate %f: expected the burst to last about %s but got %s", ipa,
+					rate, normal.burstLength, s.burstLength)
+			}
+			if !s.aspirated {
+				t.Errorf("%q at rate %f: expected aspiration", ipa, rate)
+			}
+			if s.gap <= 0 || s.gap >= lastGap {
+				t.Errorf("%q at rate %f: expected a closure gap shorter than %s, but got %s",
+					ipa, rate, lastGap, s.gap)
+			}
+			lastGap = s.gap
+		}
+	}
+}
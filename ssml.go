@@ -0,0 +1,414 @@
+package gospeech
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/unixpickle/wav"
+)
+
+// ssmlStrongEmphasisRate is the rate by which strong emphasis slows down its words, on top of
+// stressing them.
+const ssmlStrongEmphasisRate = 0.8
+
+// ssmlRates maps the named rates of SSML prosody to multiples of the voice's rate.
+var ssmlRates = map[string]float64{
+	"x-slow":  0.5,
+	"slow":    0.75,
+	"medium":  1,
+	"fast":    1.5,
+	"x-fast":  2,
+	"default": 1,
+}
+
+// ssmlPitches maps the named pitches of SSML prosody to semitones above the voice's pitch.
+var ssmlPitches = map[string]float64{
+	"x-low":   -6,
+	"low":     -3,
+	"medium":  0,
+	"high":    3,
+	"x-high":  6,
+	"default": 0,
+}
+
+// ssmlBreaks maps the strengths of SSML breaks to pause durations.
+// A medium break is the frontend's punctuation pause.
+var ssmlBreaks = map[string]time.Duration{
+	"none":     0,
+	"x-weak":   time.Millisecond * 50,
+	"weak":     time.Millisecond * 125,
+	"strong":   time.Millisecond * 500,
+	"x-strong": time.Second,
+}
+
+// ssmlProsody is the prosody within an SSML element.
+type ssmlProsody struct {
+	// rate multiplies the voice's rate.
+	rate float64
+
+	// pitch is the number of semitones by which the pitch is raised above the voice's.
+	pitch float64
+
+	// emphasis is the level of the innermost emphasis element, or "" outside of one.
+	emphasis string
+}
+
+// ssmlElement is an open SSML element.
+type ssmlElement struct {
+	prosody ssmlProsody
+
+	// phoneme is the innermost phoneme element enclosing the element, whose text is collected
+	// instead of being spoken.
+	phoneme *ssmlElement
+
+	// pronunciation and text are the pronunciation and collected text of a phoneme element.
+	pronunciation []Phone
+	text          string
+}
+
+// ssmlSpan records the prosody of a range of phones.
+type ssmlSpan struct {
+	start   int
+	end     int
+	prosody ssmlProsody
+}
+
+// ParseSSML converts an SSML document into timed phones with intonation, like TimedPhones does
+// for plain text.
+//
+// A practical subset of SSML is supported:
+//
+//   - <speak> contains the document.
+//   - <break> inserts a Pause of its time, such as "300ms", or of its strength.
+//     A break next to punctuation replaces the punctuation's pause.
+//   - <prosody> changes the rate and pitch of its contents.
+//     Rates are names such as "slow", percentages, or multiples, and they multiply the rates
+//     of enclosing elements.
+//     Pitches are names such as "high", or changes relative to the enclosing element in
+//     semitones, percent, or Hz, such as "+2st".
+//   - <emphasis> gives its words primary stress, or takes all stress away at a level of
+//     "reduced".
+//     Strong emphasis also slows the words down.
+//   - <phoneme alphabet="ipa"> pronounces its ph attribute, parsed with ParseIPA, instead of
+//     its text.
+//
+// Other elements are skipped, but their text is spoken.
+// Malformed XML and invalid attributes produce errors giving their line and column.
+func (t *TextFrontend) ParseSSML(r io.Reader) ([]TimedPhone, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b := t.newTextBuilder()
+	stack := []*ssmlElement{{prosody: ssmlProsody{rate: 1}}}
+	var spans []ssmlSpan
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			if syntaxErr, ok := err.(*xml.SyntaxError); ok {
+				err = errors.New(syntaxErr.Msg)
+			}
+			return nil, ssmlError(data, decoder.InputOffset(), err)
+		}
+		if err := b.flushWord(); err != nil {
+			return nil, ssmlError(data, offset, err)
+		}
+
+		top := stack[len(stack)-1]
+		switch token := token.(type) {
+		case xml.StartElement:
+			element, err := openSSMLElement(b, top, token)
+			if err != nil {
+				return nil, ssmlError(data, offset, err)
+			}
+			stack = append(stack, element)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			if top.phoneme == top {
+				start := len(b.phones)
+				b.addWord(strings.TrimSpace(top.text), top.pronunciation)
+				spans = append(spans, ssmlSpan{start, len(b.phones), top.prosody})
+			}
+		case xml.CharData:
+			if top.phoneme != nil {
+				top.phoneme.text += string(token)
+				continue
+			}
+			start := len(b.phones)
+			if err := b.addText(string(token)); err != nil {
+				return nil, ssmlError(data, offset, err)
+			}
+			if err := b.flushWord(); err != nil {
+				return nil, ssmlError(data, offset, err)
+			}
+			spans = append(spans, ssmlSpan{start, len(b.phones), top.prosody})
+		}
+	}
+	if err := b.finish(); err != nil {
+		return nil, err
+	}
+
+	for _, span := range spans {
+		ssmlApplyEmphasis(b.phones[span.start:span.end], span.prosody.emphasis)
+		if span.prosody.rate != 1 {
+			v := Voice{Rate: span.prosody.rate, MinPause: b.voice.MinPause}
+			copy(b.phones[span.start:span.end], v.applyRate(b.phones[span.start:span.end]))
+		}
+	}
+	timed := b.timedPhones()
+	for _, span := range spans {
+		for i := span.start; i < span.end; i++ {
+			timed[i].Pitch += span.prosody.pitch / b.voice.pitchRange()
+		}
+	}
+	return timed, nil
+}
+
+// SynthesizeSSML parses an SSML document with ParseSSML and synthesizes it.
+func (t *TextFrontend) SynthesizeSSML(r io.Reader) (wav.Sound, error) {
+	phones, err := t.ParseSSML(r)
+	if err != nil {
+		return nil, err
+	}
+	return t.voice().SynthesizeTimed(phones), nil
+}
+
+// openSSMLElement opens an SSML element within its parent, adding the pause of a break.
+func openSSMLElement(b *textBuilder, parent *ssmlElement,
+	token xml.StartElement) (*ssmlElement, error) {
+	res := &ssmlElement{prosody: parent.prosody, phoneme: parent.phoneme}
+	attr := func(name string) (string, bool) {
+		for _, a := range token.Attr {
+			if a.Name.Local == name {
+				return strings.TrimSpace(a.Value), true
+			}
+		}
+		return "", false
+	}
+
+	switch token.Name.Local {
+	case "break":
+		d, err := ssmlBreakDuration(attr, b.pause)
+		if err != nil {
+			return nil, err
+		}
+		ssmlAddBreak(b, d)
+	case "prosody":
+		if value, ok := attr("rate"); ok {
+			rate, err := parseSSMLRate(value)
+			if err != nil {
+				return nil, err
+			}
+			res.prosody.rate *= rate
+		}
+		if value, ok := attr("pitch"); ok {
+			base := b.voice.newVocalSystem().Pitch()
+			pitch, err := parseSSMLPitch(value, parent.prosody.pitch, base)
+			if err != nil {
+				return nil, err
+			}
+			res.prosody.pitch = pitch
+		}
+	case "emphasis":
+		level, ok := attr("level")
+		if !ok {
+			level = "moderate"
+		}
+		switch level {
+		case "strong":
+			res.prosody.rate *= ssmlStrongEmphasisRate
+		case "moderate", "reduced", "none":
+		default:
+			return nil, errors.New("emphasis: unknown level: " + strconv.Quote(level))
+		}
+		res.prosody.emphasis = level
+	case "phoneme":
+		if alphabet, _ := attr("alphabet"); alphabet != "ipa" || parent.phoneme != nil {
+			break
+		}
+		ph, ok := attr("ph")
+		if !ok {
+			return nil, errors.New("phoneme: missing ph")
+		}
+		phones, err := b.voice.ParseIPA(ph)
+		if err != nil {
+			return nil, errors.New("phoneme: " + err.Error())
+		}
+		res.phoneme = res
+		res.pronunciation = phones
+	}
+	return res, nil
+}
+
+// ssmlBreakDuration returns the duration of a break from its time or strength attribute.
+// A break with neither is a medium break of the given duration.
+func ssmlBreakDuration(attr func(name string) (string, bool),
+	medium time.Duration) (time.Duration, error) {
+	if value, ok := attr("time"); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return 0, errors.New("break: invalid time: " + strconv.Quote(value))
+		}
+		return d, nil
+	}
+	strength, ok := attr("strength")
+	if !ok || strength == "medium" {
+		return medium, nil
+	}
+	d, ok := ssmlBreaks[strength]
+	if !ok {
+		return 0, errors.New("break: unknown strength: " + strconv.Quote(strength))
+	}
+	return d, nil
+}
+
+// ssmlAddBreak adds a pause, replacing the pause of any punctuation just before it.
+// A break of zero removes the pause, leaving just a WordBreak.
+func ssmlAddBreak(b *textBuilder, d time.Duration) {
+	if len(b.phones) > 0 {
+		if _, ok := b.phones[len(b.phones)-1].(Pause); ok {
+			if d == 0 {
+				b.phones[len(b.phones)-1] = WordBreak{}
+			} else {
+				b.phones[len(b.phones)-1] = Pause{Duration: d}
+			}
+			return
+		}
+	}
+	if d > 0 {
+		b.phones = append(b.phones, Pause{Duration: d})
+	}
+}
+
+// parseSSMLRate parses the rate of a prosody element as a multiple of the enclosing rate.
+func parseSSMLRate(value string) (float64, error) {
+	if rate, ok := ssmlRates[value]; ok {
+		return rate, nil
+	}
+	number := strings.TrimSuffix(value, "%")
+	rate, err := strconv.ParseFloat(number, 64)
+	if number != value {
+		rate /= 100
+	}
+	if err != nil || !(rate > 0) || math.IsInf(rate, 0) {
+		return 0, errors.New("prosody: invalid rate: " + strconv.Quote(value))
+	}
+	return rate, nil
+}
+
+// parseSSMLPitch parses the pitch of a prosody element, returning its semitones above the voice's
+// base pitch.
+// Relative pitches change the pitch of the enclosing element, and absolute ones in Hz replace
+// it.
+func parseSSMLPitch(value string, outer, base float64) (float64, error) {
+	if pitch, ok := ssmlPitches[value]; ok {
+		return pitch, nil
+	}
+	invalid := errors.New("prosody: invalid pitch: " + strconv.Quote(value))
+	relative := strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-")
+	var unit string
+	for _, suffix := range []string{"st", "Hz", "%"} {
+		if strings.HasSuffix(value, suffix) {
+			unit = suffix
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+	if err != nil || math.IsInf(number, 0) || unit == "" || (!relative && unit != "Hz") {
+		return 0, invalid
+	}
+
+	var frequency float64
+	switch unit {
+	case "st":
+		return outer + number, nil
+	case "%":
+		frequency = base * math.Pow(2, outer/12) * (1 + number/100)
+	case "Hz":
+		frequency = number
+		if relative {
+			frequency += base * math.Pow(2, outer/12)
+		}
+	}
+	if !(frequency > 0) {
+		return 0, invalid
+	}
+	return 12 * math.Log2(frequency/base), nil
+}
+
+// ssmlApplyEmphasis changes the stresses of the words in a range of phones to an emphasis
+// level.
+//
+// Emphasis gives primary stress to the most stressed vowels of each word, and "reduced"
+// emphasis makes every vowel unstressed.
+// Only an *IPAPhone carries a stress, and other phones are left as they are.
+func ssmlApplyEmphasis(phones []Phone, level string) {
+	if level == "" || level == "none" {
+		return
+	}
+	for start := 0; start < len(phones); {
+		end := start
+		maxStress := Unstressed
+		for ; end < len(phones); end++ {
+			if _, ok := phones[end].(WordBreak); ok {
+				break
+			} else if _, ok := phones[end].(Pause); ok {
+				break
+			}
+			if ipaPhone := ssmlVowel(phones[end]); ipaPhone != nil && ipaPhone.Stress > maxStress {
+				maxStress = ipaPhone.Stress
+			}
+		}
+		for i := start; i < end; i++ {
+			ipaPhone := ssmlVowel(phones[i])
+			if ipaPhone == nil {
+				continue
+			}
+			emphasized := *ipaPhone
+			if level == "reduced" {
+				emphasized.Stress = Unstressed
+			} else if ipaPhone.Stress == maxStress {
+				emphasized.Stress = PrimaryStress
+			}
+			phones[i] = &emphasized
+		}
+		start = end + 1
+	}
+}
+
+// ssmlVowel returns a phone as an *IPAPhone if it is a vowel or diphthong, or nil otherwise.
+func ssmlVowel(phone Phone) *IPAPhone {
+	ipaPhone, ok := phone.(*IPAPhone)
+	if !ok {
+		return nil
+	}
+	switch ipaPhone.Phone.(type) {
+	case Vowel, Diphthong:
+		return ipaPhone
+	}
+	return nil
+}
+
+// ssmlError annotates an error with the line and column of a byte offset in an SSML document.
+func ssmlError(data []byte, offset int64, err error) error {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := utf8.RuneCount(before[bytes.LastIndexByte(before, '\n')+1:]) + 1
+	return errors.New("ssml: line " + strconv.Itoa(line) + ", column " + strconv.Itoa(column) +
+		": " + err.Error())
+}
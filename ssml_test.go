@@ -0,0 +1,141 @@
+package gospeech
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSSMLPhones(t *testing.T) {
+	frontend := &TextFrontend{Dictionary: SampleDictionary()}
+	for _, test := range []struct {
+		doc      string
+		expected string
+	}{
+		{
+			`<speak>Hello <break time="300ms"/> <phoneme alphabet="ipa" ph="ˈwɝld">word` +
+				`</phoneme>, <emphasis level="reduced">good</emphasis> <emphasis>morning` +
+				`</emphasis>?</speak>`,
+			"h:0:0 ə:0:0 l:1:2 oʊ:1:2 (300ms) w:0:2 ə:0:2 ɹ:0:2 l:0:2 d:0:2 (250ms) " +
+				"g:0:2 ʊ:0:0 d:0:2 | m:0:2 ɔ:0:2 ɹ:0:2 n:1:0 I:1:0 ŋ:1:0 (250ms)",
+		},
+		{
+			`<speak><say-as interpret-as="x">hello</say-as> world</speak>`,
+			"h:0:0 ə:0:0 l:1:2 oʊ:1:2 | w:0:2 ə:0:2 ɹ:0:2 l:0:2 d:0:2",
+		},
+		{
+			// Adjacent breaks replace one another, and a break of zero removes a pause.
+			`<speak>hello<break strength="x-weak"/> <break strength="strong"/>world, ` +
+				`<break time="0s"/> good<break time="1.5s"/>.</speak>`,
+			"h:0:0 ə:0:0 l:1:2 oʊ:1:2 (500ms) w:0:2 ə:0:2 ɹ:0:2 l:0:2 d:0:2 | " +
+				"g:0:2 ʊ:0:2 d:0:2 (1.5s)",
+		},
+	} {
+		timed, err := frontend.ParseSSML(strings.NewReader(test.doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		phones := make([]Phone, len(timed))
+		for i, phone := range timed {
+			phones[i] = phone.Phone
+		}
+		if actual := describeIPA(phones); actual != test.expected {
+			t.Errorf("%s: expected %s but got %s", test.doc, test.expected, actual)
+		}
+	}
+}
+
+func TestParseSSMLProsody(t *testing.T) {
+	frontend := &TextFrontend{Dictionary: SampleDictionary()}
+	parse := func(doc string) []TimedPhone {
+		timed, err := frontend.ParseSSML(strings.NewReader("<speak>" + doc + "</speak>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return timed
+	}
+	vowelDuration := func(phone Phone) (time.Duration, bool) {
+		if ipa, ok := phone.(*IPAPhone); ok {
+			phone = ipa.Phone
+		}
+		switch phone := phone.(type) {
+		case Vowel:
+			return phone.Duration, true
+		case Diphthong:
+			return phone.Duration, true
+		}
+		return 0, false
+	}
+
+	// Nested rates multiply, so morning is spoken at a quarter of the usual rate.
+	plain := parse("good morning")
+	slow := parse(`<prosody rate="x-slow">good <prosody rate="50%">morning</prosody></prosody>`)
+	if len(slow) != len(plain) {
+		t.Fatalf("expected %d phones but got %d", len(plain), len(slow))
+	}
+	for i, phone := range slow {
+		expected, ok := vowelDuration(plain[i].Phone)
+		if !ok {
+			continue
+		}
+		if i < 3 {
+			expected *= 2
+		} else {
+			expected *= 4
+		}
+		if actual, _ := vowelDuration(phone.Phone); actual != expected {
+			t.Errorf("phone %d: expected a vowel of %s but got %s", i, expected, actual)
+		}
+	}
+	if slow[len(slow)-1].Start+slow[len(slow)-1].Duration <=
+		plain[len(plain)-1].Start+plain[len(plain)-1].Duration {
+		t.Error("expected the slow speech to last longer")
+	}
+
+	// Pitch offsets add up through nesting, in semitones of the voice's range.
+	plain = parse("hello good morning")
+	raised := parse(`hello <prosody pitch="+2st">good <prosody pitch="+3st">morning</prosody>` +
+		`</prosody>`)
+	for i, phone := range raised {
+		var semitones float64
+		if i >= 8 {
+			semitones = 5
+		} else if i >= 4 {
+			semitones = 2
+		}
+		expected := plain[i].Pitch + semitones/DefaultVoice.pitchRange()
+		if math.Abs(phone.Pitch-expected) > 1e-9 {
+			t.Errorf("phone %d: expected a pitch of %f but got %f", i, expected, phone.Pitch)
+		}
+	}
+}
+
+func TestParseSSMLErrors(t *testing.T) {
+	frontend := &TextFrontend{Dictionary: SampleDictionary()}
+	for _, test := range []struct {
+		doc string
+		err string
+	}{
+		{"<speak>\n  <break time=\"x\"/></speak>",
+			`ssml: line 2, column 3: break: invalid time: "x"`},
+		{`<speak><break strength="loud"/></speak>`,
+			`ssml: line 1, column 8: break: unknown strength: "loud"`},
+		{`<speak><prosody rate="quick">hello</prosody></speak>`,
+			`ssml: line 1, column 8: prosody: invalid rate: "quick"`},
+		{`<speak><prosody pitch="200">hello</prosody></speak>`,
+			`ssml: line 1, column 8: prosody: invalid pitch: "200"`},
+		{`<speak><emphasis level="huge">hello</emphasis></speak>`,
+			`ssml: line 1, column 8: emphasis: unknown level: "huge"`},
+		{`<speak><phoneme alphabet="ipa">hello</phoneme></speak>`,
+			"ssml: line 1, column 8: phoneme: missing ph"},
+		{"<speak>\n<prosody>hello</speak>",
+			"ssml: line 2, column 23: element <prosody> closed by </speak>"},
+		{"<speak>hello <bogus>", "ssml: line 1, column 21: unexpected EOF"},
+	} {
+		if _, err := frontend.ParseSSML(strings.NewReader(test.doc)); err == nil ||
+			err.Error() != test.err {
+			t.Errorf("%q: expected error %q but got %v", test.doc, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package gospeech
+
+import (
+	"math"
+	"time"
+)
+
+// A StressEffect describes how a level of lexical stress changes the vowels it applies to.
+type StressEffect struct {
+	// DurationScale multiplies the duration of the vowel.
+	// If it is 0, the duration is not changed.
+	DurationScale float64 `json:"duration_scale,omitempty"`
+
+	// PitchAccent is the number of semitones by which the vowel is raised above the intonation
+	// contour.
+	PitchAccent float64 `json:"pitch_accent,omitempty"`
+
+	// VolumeScale multiplies the volumes of the vowel's formants.
+	// If it is 0, the volumes are not changed.
+	VolumeScale float64 `json:"volume_scale,omitempty"`
+}
+
+// StressEffects gives the StressEffect of every level of stress.
+type StressEffects struct {
+	Primary    StressEffect `json:"primary"`
+	Secondary  StressEffect `json:"secondary"`
+	Unstressed StressEffect `json:"unstressed"`
+}
+
+// Effect returns the StressEffect for a level of stress.
+func (s StressEffects) Effect(stress Stress) StressEffect {
+	switch stress {
+	case PrimaryStress:
+		return s.Primary
+	case SecondaryStress:
+		return s.Secondary
+	}
+	return s.Unstressed
+}
+
+// DefaultStressEffects are the StressEffects of the DefaultVoice.
+var DefaultStressEffects = StressEffects{
+	Primary:   StressEffect{DurationScale: 1.3, PitchAccent: 2, VolumeScale: 1.15},
+	Secondary: StressEffect{DurationScale: 1.15, PitchAccent: 1, VolumeScale: 1.05},
+}
+
+// applyStress applies the voice's StressEffects to the vowels and diphthongs of a sequence of
+// phones.
+// Only an *IPAPhone carries a stress, and other phones are left as they are.
+func (v Voice) applyStress(phones []Phone) []Phone {
+	res := make([]Phone, len(phones))
+	for i, phone := range phones {
+		res[i] = phone
+		ipaPhone, ok := phone.(*IPAPhone)
+		if !ok {
+			continue
+		}
+		effect := v.Stress.Effect(ipaPhone.Stress)
+		var stressedPhone Phone
+		switch phone := ipaPhone.Phone.(type) {
+		case Vowel:
+			phone.Duration = effect.scaleDuration(phone.Duration)
+			phone.Formants = effect.scaleVolumes(phone.Formants)
+			stressedPhone = phone
+		case Diphthong:
+			phone.Duration = effect.scaleDuration(phone.Duration)
+			phone.Start = effect.scaleVolumes(phone.Start)
+			phone.End = effect.scaleVolumes(phone.End)
+			stressedPhone = phone
+		default:
+			continue
+		}
+		stressed := *ipaPhone
+		stressed.Phone = stressedPhone
+		res[i] = &stressed
+	}
+	return res
+}
+
+// pitchAccent returns the number of semitones by which a phone's stress raises its pitch.
+func (v Voice) pitchAccent(phone Phone) float64 {
+	ipaPhone, ok := phone.(*IPAPhone)
+	if !ok {
+		return 0
+	}
+	switch ipaPhone.Phone.(type) {
+	case Vowel, Diphthong:
+		return v.Stress.Effect(ipaPhone.Stress).PitchAccent
+	}
+	return 0
+}
+
+func (s StressEffect) scaleDuration(d time.Duration) time.Duration {
+	if s.DurationScale == 0 {
+		return d
+	}
+	return time.Duration(math.Round(float64(d) * s.DurationScale))
+}
+
+func (s StressEffect) scaleVolumes(f FormantState) FormantState {
+	if s.VolumeScale != 0 {
+		for i := range f.Volumes {
+			f.Volumes[i] *= s.VolumeScale
+		}
+	}
+	return f
+}
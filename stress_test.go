@@ -0,0 +1,116 @@
+package gospeech
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+func TestStressDuration(t *testing.T) {
+	durations := map[string]time.Duration{}
+	totals := map[string]time.Duration{}
+	tails := map[string]time.Duration{}
+	for _, ipa := range []string{"zaz", "ˌzaz", "ˈzaz"} {
+		timed := timeIPA(t, DefaultVoice, ipa)
+		durations[ipa] = timed[1].Duration
+		totals[ipa] = timed[2].Start + timed[2].Duration
+
+		// Every track of the system ends together, however long the vowel grew.
+		set := DefaultVoice.TimedTrack(timed).(tracks.TrackSet)
+		for id, track := range set {
+			if track.Duration() != set.Duration() {
+				t.Errorf("%q: track %s lasts %s rather than %s", ipa, id, track.Duration(),
+					set.Duration())
+			}
+		}
+		tails[ipa] = set.Duration() - totals[ipa]
+	}
+	if !(durations["zaz"] < durations["ˌzaz"] && durations["ˌzaz"] < durations["ˈzaz"]) {
+		t.Errorf("expected stress to lengthen the vowel, but got %v", durations)
+	}
+	for _, ipa := range []string{"ˌzaz", "ˈzaz"} {
+		if tails[ipa] != tails["zaz"] {
+			t.Errorf("%q: expected the silence after the word to last %s but got %s", ipa,
+				tails["zaz"], tails[ipa])
+		}
+		if diff := totals[ipa] - totals["zaz"]; diff != durations[ipa]-durations["zaz"] {
+			t.Errorf("%q: expected only the vowel to lengthen, but the word grew by %s", ipa, diff)
+		}
+	}
+
+	// A larger scale lengthens the vowel more, and reductions shorten it.
+	voice := DefaultVoice
+	voice.Stress.Primary.DurationScale = 2
+	voice.Stress.Unstressed.DurationScale = 0.5
+	if d := timeIPA(t, voice, "ˈzaz")[1].Duration; d <= durations["ˈzaz"] {
+		t.Errorf("expected a scale of 2 to lengthen the vowel past %s, but got %s",
+			durations["ˈzaz"], d)
+	}
+	if d := timeIPA(t, voice, "zaz")[1].Duration; d >= durations["zaz"] {
+		t.Errorf("expected a reduced vowel to be shorter than %s, but got %s", durations["zaz"], d)
+	}
+}
+
+func TestStressPitchAccent(t *testing.T) {
+	// The voicing of the final consonant starts at the pitch to which the vowel glided.
+	onset := func(v Voice, ipa string, contour ContourType) float64 {
+		const sampleRate = 16000
+		timed := timeIPA(t, v, ipa)
+		ApplyContour(timed, contour)
+		voicing := v.TimedTrack(timed).(tracks.TrackSet)["ConsonantVoice"].Encode(sampleRate)
+		start := int(timed[2].Start * sampleRate / time.Second)
+		return zeroCrossingFrequency(voicing[start:start+sampleRate/50], sampleRate)
+	}
+
+	unstressed := onset(DefaultVoice, "zaz", FlatContour)
+	secondary := onset(DefaultVoice, "ˌzaz", FlatContour)
+	primary := onset(DefaultVoice, "ˈzaz", FlatContour)
+	if math.Abs(unstressed/350-1) > 0.01 {
+		t.Errorf("expected an unstressed vowel to keep the base pitch, but got %f", unstressed)
+	}
+	semitones := func(f float64) float64 {
+		return 12 * math.Log2(f/unstressed)
+	}
+	if s := semitones(secondary); s < 0.6 || s > 1 {
+		t.Errorf("expected secondary stress to raise the pitch about a semitone, not %f", s)
+	}
+	if s := semitones(primary); s < 1.6 || s > 2 {
+		t.Errorf("expected primary stress to raise the pitch about two semitones, not %f", s)
+	}
+
+	// The accent rides on top of the contour rather than replacing it.
+	statement := onset(DefaultVoice, "zaz", StatementContour)
+	accented := onset(DefaultVoice, "ˈzaz", StatementContour)
+	if s := 12 * math.Log2(accented/statement); s < 1.2 || s > 2.4 {
+		t.Errorf("expected the accent to raise the statement about two semitones, not %f", s)
+	}
+	if math.Abs(statement-unstressed) < 5 {
+		t.Errorf("expected the statement contour to move the pitch from %f, but got %f",
+			unstressed, statement)
+	}
+}
+
+func TestStressVolume(t *testing.T) {
+	levels := map[string]float64{}
+	for _, ipa := range []string{"zaz", "ˈzaz"} {
+		set := DefaultVoice.TimedTrack(timeIPA(t, DefaultVoice, ipa)).(tracks.TrackSet)
+		for _, level := range tracks.RMSSeries(set["Formants"], 16000, time.Millisecond*20) {
+			levels[ipa] = math.Max(levels[ipa], level)
+		}
+	}
+	expected := DefaultStressEffects.Primary.VolumeScale
+	if ratio := levels["ˈzaz"] / levels["zaz"]; math.Abs(ratio/expected-1) > 0.02 {
+		t.Errorf("expected stress to scale the vowel's level by %f but got %f", expected, ratio)
+	}
+}
+
+// timeIPA parses and times IPA with a voice.
+func timeIPA(t *testing.T, v Voice, ipa string) []TimedPhone {
+	phones, err := v.ParseIPA(ipa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v.TimePhones(phones)
+}
@@ -0,0 +1,300 @@
+package gospeech
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/unixpickle/wav"
+)
+
+// DefaultPunctuationPause is the pause which a TextFrontend inserts at punctuation by default.
+const DefaultPunctuationPause = time.Millisecond * 250
+
+// pausePunctuation lists the punctuation marks at which a TextFrontend pauses.
+const pausePunctuation = ".,;:!?"
+
+// sentencePunctuation lists the punctuation marks at which a TextFrontend ends a sentence.
+const sentencePunctuation = ".!?"
+
+// A Pause is a Phone which ends the current word and holds silence for a duration.
+type Pause struct {
+	Duration time.Duration
+}
+
+func (p Pause) EncodeBeginning(system VocalSystem, lastPhone, nextPhone Phone) {
+	system.AdjustVolume(0, p.Duration)
+}
+
+func (p Pause) FormantPull(nextFormant FormantState) FormantState {
+	return nextFormant
+}
+
+func (p Pause) TransitionTime() time.Duration {
+	return 0
+}
+
+// Lookup finds the phones of a word, using the DefaultVoice.
+// It returns false if the word is not in the dictionary or its IPA cannot be parsed.
+func (d Dictionary) Lookup(word string) ([]Phone, bool) {
+	return d.lookup(DefaultVoice, word)
+}
+
+func (d Dictionary) lookup(v Voice, word string) ([]Phone, bool) {
+	word = strings.ToLower(word)
+	ipa, ok := d[word]
+	if !ok {
+		ipa, ok = d[strings.Replace(word, "'", "", -1)]
+	}
+	if !ok {
+		return nil, false
+	}
+	phones, err := v.ParseIPA(ipa)
+	if err != nil {
+		return nil, false
+	}
+	return phones, true
+}
+
+// A TextFrontend converts English text into phones by looking words up in a Dictionary.
+type TextFrontend struct {
+	Dictionary Dictionary
+
+	// Voice supplies the phones.
+	// If it has no phones, the DefaultVoice is used.
+	Voice Voice
+
+	// PunctuationPause is the length of the pause inserted at punctuation marks.
+	// If it is 0, DefaultPunctuationPause is used.
+	PunctuationPause time.Duration
+
+	// LetterToSound, if non-nil, produces IPA for words which are not in the dictionary.
+	// Otherwise, such words are reported as errors.
+	LetterToSound func(word string) (string, error)
+}
+
+// SynthesizeText synthesizes English text with the DefaultVoice and the default options of a
+// TextFrontend.
+func SynthesizeText(d Dictionary, text string) (wav.Sound, error) {
+	frontend := &TextFrontend{Dictionary: d}
+	return frontend.Synthesize(text)
+}
+
+// Phones converts text into phones.
+//
+// The text is split into words at whitespace and punctuation, and words are separated by
+// WordBreaks.
+// The punctuation marks ".,;:!?" also insert a Pause.
+func (t *TextFrontend) Phones(text string) ([]Phone, error) {
+	b, err := t.buildText(text)
+	if err != nil {
+		return nil, err
+	}
+	return b.phones, nil
+}
+
+// TimedPhones converts text into timed phones with intonation.
+//
+// Each sentence gets a StatementContour, unless it ends with a question mark, in which case it
+// gets a QuestionContour.
+func (t *TextFrontend) TimedPhones(text string) ([]TimedPhone, error) {
+	timed, _, err := t.timedPhones(text)
+	return timed, err
+}
+
+// timedPhones is like TimedPhones, but it also returns the text of each word which produced
+// phones.
+func (t *TextFrontend) timedPhones(text string) ([]TimedPhone, []string, error) {
+	b, err := t.buildText(text)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b.timedPhones(), b.words, nil
+}
+
+func (t *TextFrontend) buildText(text string) (*textBuilder, error) {
+	b := t.newTextBuilder()
+	if err := b.addText(text); err != nil {
+		return nil, err
+	}
+	if err := b.finish(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// A textSentence records where a sentence ends in a sequence of phones and its contour.
+type textSentence struct {
+	end     int
+	contour ContourType
+}
+
+// A textBuilder accumulates the phones of a text which is given in pieces.
+// A word may not span two pieces.
+type textBuilder struct {
+	frontend *TextFrontend
+	voice    Voice
+	pause    time.Duration
+
+	phones    []Phone
+	sentences []textSentence
+	words     []string
+	word      []rune
+}
+
+func (t *TextFrontend) newTextBuilder() *textBuilder {
+	pause := t.PunctuationPause
+	if pause == 0 {
+		pause = DefaultPunctuationPause
+	}
+	return &textBuilder{frontend: t, voice: t.voice(), pause: pause}
+}
+
+// addText adds the words of a piece of text, along with the pauses and sentence ends of its
+// punctuation.
+// The final word is not added until the next call, or until flushWord or finish.
+func (b *textBuilder) addText(text string) error {
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' {
+			b.word = append(b.word, r)
+			continue
+		}
+		if err := b.flushWord(); err != nil {
+			return err
+		}
+		if strings.ContainsRune(pausePunctuation, r) && len(b.phones) > 0 {
+			b.addPause(b.pause)
+		}
+		if strings.ContainsRune(sentencePunctuation, r) {
+			contour := StatementContour
+			if r == '?' {
+				contour = QuestionContour
+			}
+			b.endSentence(contour)
+		}
+	}
+	return nil
+}
+
+// flushWord adds the phones of the word being accumulated.
+func (b *textBuilder) flushWord() error {
+	if len(b.word) == 0 {
+		return nil
+	}
+	phones, err := b.frontend.wordPhones(b.voice, string(b.word))
+	if err != nil {
+		return err
+	}
+	b.addWord(string(b.word), phones)
+	b.word = b.word[:0]
+	return nil
+}
+
+// addWord adds the phones of a word, separating it from the previous word.
+func (b *textBuilder) addWord(text string, phones []Phone) {
+	if len(b.phones) > 0 {
+		switch b.phones[len(b.phones)-1].(type) {
+		case Pause, WordBreak:
+		default:
+			b.phones = append(b.phones, WordBreak{})
+		}
+	}
+	b.phones = append(b.phones, phones...)
+	if len(phones) > 0 {
+		b.words = append(b.words, text)
+	}
+}
+
+// addPause adds a pause after the previous word.
+// Consecutive pauses are not added, and the first pause is kept.
+func (b *textBuilder) addPause(d time.Duration) {
+	if len(b.phones) > 0 {
+		if _, ok := b.phones[len(b.phones)-1].(Pause); ok {
+			return
+		}
+	}
+	b.phones = append(b.phones, Pause{Duration: d})
+}
+
+// endSentence ends the current sentence with a contour.
+// If the sentence is empty, the contour replaces that of the previous sentence.
+func (b *textBuilder) endSentence(contour ContourType) {
+	if len(b.sentences) > 0 && b.sentences[len(b.sentences)-1].end == len(b.phones) {
+		b.sentences[len(b.sentences)-1].contour = contour
+	} else if len(b.phones) > 0 {
+		b.sentences = append(b.sentences, textSentence{end: len(b.phones), contour: contour})
+	}
+}
+
+// finish adds the final word and ends the final sentence with a StatementContour, unless the
+// text already ended it.
+func (b *textBuilder) finish() error {
+	if err := b.flushWord(); err != nil {
+		return err
+	}
+	if len(b.sentences) == 0 || b.sentences[len(b.sentences)-1].end < len(b.phones) {
+		b.sentences = append(b.sentences,
+			textSentence{end: len(b.phones), contour: StatementContour})
+	}
+	return nil
+}
+
+// timedPhones times the phones with the builder's voice and applies the contours of the
+// sentences.
+func (b *textBuilder) timedPhones() []TimedPhone {
+	timed := b.voice.TimePhones(b.phones)
+	var start int
+	for _, s := range b.sentences {
+		ApplyContour(timed[start:s.end], s.contour)
+		start = s.end
+	}
+	return timed
+}
+
+// Synthesize converts text into phones and synthesizes them with the intonation of TimedPhones.
+func (t *TextFrontend) Synthesize(text string) (wav.Sound, error) {
+	phones, err := t.TimedPhones(text)
+	if err != nil {
+		return nil, err
+	}
+	return t.voice().SynthesizeTimed(phones), nil
+}
+
+// SynthesizeAligned is like Synthesize, but it also returns when each phone and word is heard,
+// as in Voice.SynthesizeAligned.
+// The words of the alignment are labeled with their text as written.
+func (t *TextFrontend) SynthesizeAligned(text string) (wav.Sound, *Alignment, error) {
+	phones, words, err := t.timedPhones(text)
+	if err != nil {
+		return nil, nil, err
+	}
+	sound, alignment := t.voice().SynthesizeAligned(phones)
+	if len(words) == len(alignment.Words) {
+		for i, word := range words {
+			alignment.Words[i].Text = word
+		}
+	}
+	return sound, alignment, nil
+}
+
+func (t *TextFrontend) voice() Voice {
+	if len(t.Voice.Phones) == 0 {
+		return DefaultVoice
+	}
+	return t.Voice
+}
+
+func (t *TextFrontend) wordPhones(v Voice, word string) ([]Phone, error) {
+	if phones, ok := t.Dictionary.lookup(v, word); ok {
+		return phones, nil
+	}
+	if t.LetterToSound == nil {
+		return nil, errors.New("word not in dictionary: " + word)
+	}
+	ipa, err := t.LetterToSound(word)
+	if err != nil {
+		return nil, err
+	}
+	return v.ParseIPA(ipa)
+}
@@ -0,0 +1,146 @@
+package gospeech
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTextFrontendHelloWorld(t *testing.T) {
+	frontend := &TextFrontend{Dictionary: SampleDictionary()}
+	phones, err := frontend.Phones("Hello, world.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "h:0:0 ə:0:0 l:1:2 oʊ:1:2 (250ms) w:0:2 ə:0:2 ɹ:0:2 l:0:2 d:0:2 (250ms)"
+	if actual := describeIPA(phones); actual != expected {
+		t.Errorf("expected phones %q but got %q", expected, actual)
+	}
+
+	timed, err := frontend.TimedPhones("Hello, world.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timed) != len(phones) {
+		t.Fatalf("expected %d timed phones but got %d", len(phones), len(timed))
+	}
+	for i := 1; i < len(timed); i++ {
+		if end := timed[i-1].Start + timed[i-1].Duration; timed[i].Start != end {
+			t.Errorf("phone %d starts at %s but the previous phone ends at %s", i, timed[i].Start,
+				end)
+		}
+	}
+	last := timed[len(timed)-1]
+	total := last.Start + last.Duration
+	if total < time.Second*2 || total > time.Second*3 {
+		t.Errorf("unexpected duration: %s", total)
+	}
+
+	sound, err := frontend.Synthesize("Hello, world.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := sound.Duration() - total; diff < -time.Millisecond || diff > time.Millisecond {
+		t.Errorf("expected a duration of %s but got %s", total, sound.Duration())
+	}
+	var peak float64
+	for _, sample := range sound.Samples() {
+		peak = math.Max(peak, math.Abs(float64(sample)))
+	}
+	if peak < 0.05 {
+		t.Errorf("expected audible speech but the peak is %f", peak)
+	}
+
+	direct, err := SynthesizeText(SampleDictionary(), "Hello, world.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(direct.Samples()) != len(sound.Samples()) {
+		t.Errorf("expected SynthesizeText to give %d samples but got %d", len(sound.Samples()),
+			len(direct.Samples()))
+	}
+}
+
+func TestTextFrontendPunctuation(t *testing.T) {
+	frontend := &TextFrontend{
+		Dictionary:       SampleDictionary(),
+		PunctuationPause: time.Millisecond * 100,
+	}
+	for _, test := range []struct {
+		text     string
+		expected string
+	}{
+		{"no", "n:0:2 oʊ:0:2"},
+		{"No!", "n:0:2 oʊ:0:2 (100ms)"},
+		{"...no, two;  three?!", "n:0:2 oʊ:0:2 (100ms) t:0:2 u:0:2 (100ms) θ:0:2 ɹ:0:2 " +
+			"i:0:2 (100ms)"},
+		{"one\ttwo\n", "w:0:2 ʌ:0:2 n:0:2 | t:0:2 u:0:2"},
+		{"NO-TWO", "n:0:2 oʊ:0:2 | t:0:2 u:0:2"},
+		{"Don't", "d:0:2 oʊ:0:2 n:0:2 t:0:2"},
+		{"", ""},
+		{" ?", ""},
+	} {
+		phones, err := frontend.Phones(test.text)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.text, err)
+		} else if actual := describeIPA(phones); actual != test.expected {
+			t.Errorf("%q: expected %q but got %q", test.text, test.expected, actual)
+		}
+	}
+}
+
+func TestTextFrontendUnknownWords(t *testing.T) {
+	frontend := &TextFrontend{Dictionary: SampleDictionary()}
+	if _, err := frontend.Phones("hello xyzzy world"); err == nil {
+		t.Error("expected an error for an unknown word")
+	} else if err.Error() != "word not in dictionary: xyzzy" {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := frontend.Synthesize("Xyzzy."); err == nil {
+		t.Error("expected Synthesize to fail for an unknown word")
+	}
+
+	var guessed []string
+	frontend.LetterToSound = func(word string) (string, error) {
+		guessed = append(guessed, word)
+		if word == "plugh" {
+			return "", errors.New("cannot pronounce " + word)
+		}
+		return "ˈzIzi", nil
+	}
+	phones, err := frontend.Phones("hello Xyzzy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "h:0:0 ə:0:0 l:1:2 oʊ:1:2 | z:0:2 I:0:2 z:0:2 i:0:2"
+	if actual := describeIPA(phones); actual != expected {
+		t.Errorf("expected %q but got %q", expected, actual)
+	}
+	if len(guessed) != 1 || guessed[0] != "Xyzzy" {
+		t.Errorf("expected only Xyzzy to be guessed, but got %v", guessed)
+	}
+	if _, err := frontend.Phones("plugh"); err == nil || err.Error() != "cannot pronounce plugh" {
+		t.Errorf("expected the letter-to-sound error but got %v", err)
+	}
+}
+
+func TestDictionaryLookup(t *testing.T) {
+	dict := SampleDictionary()
+	phones, ok := dict.Lookup("WORLD")
+	if !ok {
+		t.Fatal("expected WORLD to be found")
+	}
+	if actual := describeIPA(phones); actual != "w:0:2 ə:0:2 ɹ:0:2 l:0:2 d:0:2" {
+		t.Errorf("unexpected phones for WORLD: %q", actual)
+	}
+	if _, ok := dict.Lookup("xyzzy"); ok {
+		t.Error("expected xyzzy to be missing")
+	}
+	if _, ok := (Dictionary{"dont": "doʊnt"}).Lookup("Don't"); !ok {
+		t.Error("expected Don't to be found without its apostrophe")
+	}
+	if _, ok := (Dictionary{"bad": "xq"}).Lookup("bad"); ok {
+		t.Error("expected a word with invalid IPA to be missing")
+	}
+}
@@ -0,0 +1,87 @@
+package tracks
+
+import "math"
+
+// butterworthQ is the Q of a second-order Butterworth filter.
+const butterworthQ = math.Sqrt2 / 2
+
+const (
+	// minBiquadQ keeps the bandwidth of a biquad finite.
+	minBiquadQ = 0.01
+
+	// maxBiquadFraction is the highest center frequency of a biquad as a fraction of the Nyquist
+	// frequency.
+	// At the Nyquist frequency itself, the poles of a low-pass filter reach the unit circle.
+	maxBiquadFraction = 0.98
+)
+
+// A FilterType selects the response of a biquad filter.
+type FilterType int
+
+const (
+	LowPassFilter FilterType = iota
+	HighPassFilter
+	BandPassFilter
+)
+
+// A biquad is a second-order IIR filter in transposed direct form II.
+// Its coefficients are normalized so that a0 is 1.
+type biquad struct {
+	b0, b1, b2 float64
+	a1, a2     float64
+
+	z1, z2 float64
+}
+
+// newLowPass creates a low-pass biquad using the formulas from the Audio EQ Cookbook.
+func newLowPass(freq, q float64, sampleRate int) *biquad {
+	res := &biquad{}
+	res.configure(LowPassFilter, freq, q, sampleRate)
+	return res
+}
+
+// newHighPass creates a high-pass biquad using the formulas from the Audio EQ Cookbook.
+func newHighPass(freq, q float64, sampleRate int) *biquad {
+	res := &biquad{}
+	res.configure(HighPassFilter, freq, q, sampleRate)
+	return res
+}
+
+// configure sets the coefficients of the biquad using the formulas from the Audio EQ Cookbook.
+// The band-pass response has a peak gain of 1.
+//
+// The filter state is kept, so a biquad can be reconfigured between samples to sweep it.
+// The frequency and Q are clamped to a range in which the filter is stable.
+func (b *biquad) configure(kind FilterType, freq, q float64, sampleRate int) {
+	nyquist := float64(sampleRate) / 2
+	freq = math.Max(math.Min(freq, nyquist*maxBiquadFraction), 1)
+	q = math.Max(q, minBiquadQ)
+
+	w0 := 2 * math.Pi * freq / float64(sampleRate)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * q)
+
+	var b0, b1, b2 float64
+	switch kind {
+	case LowPassFilter:
+		b0, b1, b2 = (1-cos)/2, 1-cos, (1-cos)/2
+	case HighPassFilter:
+		b0, b1, b2 = (1+cos)/2, -(1 + cos), (1+cos)/2
+	case BandPassFilter:
+		b0, b1, b2 = alpha, 0, -alpha
+	default:
+		// Unknown types pass the signal through unchanged.
+		b0, b1, b2 = 1+alpha, -2*cos, 1-alpha
+	}
+	a0 := 1 + alpha
+	b.b0, b.b1, b.b2 = b0/a0, b1/a0, b2/a0
+	b.a1, b.a2 = -2*cos/a0, (1-alpha)/a0
+}
+
+// process filters a single sample.
+func (b *biquad) process(x float64) float64 {
+	y := b.b0*x + b.z1
+	b.z1 = b.b1*x - b.a1*y + b.z2
+	b.z2 = b.b2*x - b.a2*y
+	return y
+}
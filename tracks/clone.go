@@ -0,0 +1,73 @@
+package tracks
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// A Cloneable is a Track which can create a deep copy of itself.
+//
+// Wrapper tracks are Cloneable regardless of the tracks they wrap, so their Clone method returns
+// nil if a wrapped track cannot be cloned.
+// Use the Clone function to get a descriptive error instead.
+type Cloneable interface {
+	Track
+
+	// Clone creates an independent copy of the track, including everything it has accumulated
+	// so far, so the copy sounds the same as the original until either track is modified.
+	Clone() Track
+}
+
+// Clone creates a deep copy of a Track.
+// Nested TrackSets are cloned member by member.
+func Clone(t Track) (Track, error) {
+	switch t := t.(type) {
+	case TrackSet:
+		return t.Clone()
+	case Cloneable:
+		if res := t.Clone(); res != nil {
+			return res, nil
+		}
+	}
+	return nil, errors.New("track cannot be cloned")
+}
+
+// Clone creates a deep copy of the set and its members, recursing into nested TrackSets.
+//
+// If any member cannot be cloned, the error lists the IDs of those members.
+// Members of nested sets are listed by their path of IDs separated by slashes.
+func (t TrackSet) Clone() (TrackSet, error) {
+	res, failed := t.clone("")
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return nil, errors.New("cannot clone tracks: " + strings.Join(failed, ", "))
+	}
+	return res, nil
+}
+
+func (t TrackSet) clone(prefix string) (res TrackSet, failed []string) {
+	res = TrackSet{}
+	for id, track := range t {
+		path := prefix + string(id)
+		if ts, ok := track.(TrackSet); ok {
+			var nestedFailed []string
+			res[id], nestedFailed = ts.clone(path + "/")
+			failed = append(failed, nestedFailed...)
+		} else if clone := cloneTrack(track); clone != nil {
+			res[id] = clone
+		} else {
+			failed = append(failed, path)
+		}
+	}
+	return
+}
+
+// cloneTrack is like Clone, but it returns nil if the track cannot be cloned.
+func cloneTrack(t Track) Track {
+	res, err := Clone(t)
+	if err != nil {
+		return nil
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCloneSawtooth(t *testing.T) {
+	saw := NewSawtoothTrack(110, 2)
+	params := saw.Parameters()
+	params.Volume = 0.3
+	params.Formants = []float64{500, 1500}
+	params.Strength = 0.01
+	saw.AdjustParameters(params, time.Millisecond*50)
+	saw.Continue(time.Millisecond * 50)
+
+	var track Track = FadeIn(saw, time.Millisecond*10)
+	clone, err := Clone(track)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := track.Encode(8000)
+	clone.Continue(time.Millisecond * 30)
+	assertSamplesClose(t, expected, track.Encode(8000), 0)
+	if clone.Duration() != track.Duration()+time.Millisecond*30 {
+		t.Errorf("unexpected clone duration %v", clone.Duration())
+	}
+	assertSamplesClose(t, expected, clone.Encode(8000)[:len(expected)], 0)
+}
+
+func TestCloneTrackSet(t *testing.T) {
+	tone := NewToneTrack(440, 0.2, 0)
+	tone.Continue(time.Millisecond * 20)
+	set := TrackSet{"tone": tone, "nested": TrackSet{"saw": NewSawtoothTrack(100, 1)}}
+	clone, err := set.Clone()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clone["tone"].AdjustVolume(0.5, time.Millisecond*20)
+	if tone.Duration() != time.Millisecond*20 {
+		t.Error("adjusting the clone changed the original")
+	}
+
+	set["bad"] = uncloneableTrack{NewSilenceTrack(0)}
+	if _, err := set.Clone(); err == nil {
+		t.Error("expected an error for an uncloneable member")
+	}
+}
+
+// uncloneableTrack hides the Clone method of a track.
+type uncloneableTrack struct {
+	Track
+}
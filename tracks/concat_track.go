@@ -0,0 +1,124 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A ConcatTrack plays a sequence of tracks end to end.
+//
+// Each piece starts on the sample at which a track as long as the preceding pieces would end,
+// so a ConcatTrack encodes to as many samples as any other track of its duration.
+// Continuing a ConcatTrack, or adjusting its volume, elongates its final piece.
+type ConcatTrack struct {
+	pieces []Track
+}
+
+// Concat creates a ConcatTrack which plays the given pieces in order.
+// The pieces should not be modified directly afterwards, except through the ConcatTrack.
+func Concat(pieces ...Track) *ConcatTrack {
+	return &ConcatTrack{pieces: append([]Track{}, pieces...)}
+}
+
+// Pieces returns the tracks in the sequence.
+func (c *ConcatTrack) Pieces() []Track {
+	return append([]Track{}, c.pieces...)
+}
+
+// Append adds a piece to the end of the sequence.
+// Later calls to Continue and AdjustVolume elongate the new piece.
+func (c *ConcatTrack) Append(t Track) {
+	c.pieces = append(c.pieces, t)
+}
+
+func (c *ConcatTrack) Duration() (res time.Duration) {
+	for _, piece := range c.pieces {
+		res += piece.Duration()
+	}
+	return
+}
+
+func (c *ConcatTrack) Encode(sampleRate int) []wav.Sample {
+	return c.encode(sampleRate, 1, func(t Track) []wav.Sample {
+		return t.Encode(sampleRate)
+	})
+}
+
+// EncodeStereo concatenates the stereo encodings of the pieces.
+func (c *ConcatTrack) EncodeStereo(sampleRate int) []wav.Sample {
+	return c.encode(sampleRate, 2, func(t Track) []wav.Sample {
+		return encodeStereo(t, sampleRate)
+	})
+}
+
+// encode places the encodings of the pieces on the concatenation's sample grid.
+//
+// A piece may encode to one sample more or less than the room it has on that grid.
+// Extra samples are dropped, and missing samples hold the piece's last frame rather than
+// dropping to silence, which would click.
+func (c *ConcatTrack) encode(sampleRate, channels int,
+	encode func(t Track) []wav.Sample) []wav.Sample {
+	res := make([]wav.Sample, sampleCount(c.Duration(), sampleRate)*channels)
+	var start time.Duration
+	for _, piece := range c.pieces {
+		end := start + piece.Duration()
+		offset := sampleCount(start, sampleRate) * channels
+		room := sampleCount(end, sampleRate)*channels - offset
+		start = end
+		if room <= 0 {
+			continue
+		}
+		samples := encode(piece)
+		n := copy(res[offset:offset+room], samples)
+		if n < channels {
+			continue
+		}
+		for i := n; i < room; i++ {
+			res[offset+i] = samples[n-channels+i%channels]
+		}
+	}
+	return res
+}
+
+// Continue elongates the final piece.
+// If there are no pieces, the track is elongated with silence.
+func (c *ConcatTrack) Continue(d time.Duration) {
+	c.lastPiece().Continue(d)
+}
+
+// Volume returns the volume of the final piece, or 0 if there are no pieces.
+func (c *ConcatTrack) Volume() float64 {
+	if len(c.pieces) == 0 {
+		return 0
+	}
+	return c.pieces[len(c.pieces)-1].Volume()
+}
+
+// AdjustVolume elongates the final piece while adjusting its volume.
+func (c *ConcatTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	c.lastPiece().AdjustVolume(newVolume, d)
+}
+
+// AdjustVolumeCurve is like AdjustVolume, but the volume follows the given curve.
+func (c *ConcatTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(c.lastPiece(), newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if some piece cannot be cloned.
+func (c *ConcatTrack) Clone() Track {
+	res := &ConcatTrack{pieces: make([]Track, len(c.pieces))}
+	for i, piece := range c.pieces {
+		if res.pieces[i] = cloneTrack(piece); res.pieces[i] == nil {
+			return nil
+		}
+	}
+	return res
+}
+
+func (c *ConcatTrack) lastPiece() Track {
+	if len(c.pieces) == 0 {
+		c.pieces = append(c.pieces, NewSilenceTrack(0))
+	}
+	return c.pieces[len(c.pieces)-1]
+}
@@ -0,0 +1,70 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConcatMatchesContinuous(t *testing.T) {
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.AdjustFrequency(500, time.Millisecond*200)
+	tone.Continue(time.Millisecond * 100)
+	bounds := []time.Duration{0, time.Microsecond * 3333, time.Microsecond * 51111,
+		time.Microsecond * 51112, time.Microsecond * 177777, time.Millisecond * 300}
+	var pieces []Track
+	for i := 1; i < len(bounds); i++ {
+		pieces = append(pieces, Slice(tone, bounds[i-1], bounds[i]))
+	}
+	concat := Concat(pieces...)
+	if concat.Duration() != tone.Duration() {
+		t.Fatalf("expected a duration of %s but got %s", tone.Duration(), concat.Duration())
+	}
+	for _, sampleRate := range []int{8000, 22050, 44100} {
+		expected := tone.Encode(sampleRate)
+		actual := concat.Encode(sampleRate)
+		if len(actual) != len(expected) {
+			t.Fatalf("%d Hz: expected %d samples but got %d", sampleRate, len(expected),
+				len(actual))
+		}
+		assertSamplesClose(t, expected, actual, 0)
+	}
+}
+
+func TestConcatNested(t *testing.T) {
+	const sampleRate = 16000
+	first := NewToneTrack(200, 0.3, 0)
+	first.Continue(time.Millisecond * 30)
+	second := NewToneTrack(400, 0.3, 0)
+	second.Continue(time.Millisecond * 20)
+	noise := NewNoiseTrack(WhiteNoise, 0.1, 1)
+	noise.Continue(time.Millisecond * 25)
+	last := TrackSet{"noise": noise}
+	concat := Concat(Concat(first, second), last)
+	if d := concat.Duration(); d != time.Millisecond*75 {
+		t.Fatalf("expected a duration of 75ms but got %s", d)
+	}
+	samples := concat.Encode(sampleRate)
+	assertSamplesClose(t, first.Encode(sampleRate), samples[:480], 0)
+	assertSamplesClose(t, second.Encode(sampleRate), samples[480:800], 0)
+	assertSamplesClose(t, last.Encode(sampleRate), samples[800:], 0)
+
+	// The concatenation grows through its final piece.
+	concat.AdjustVolume(0.05, time.Millisecond*10)
+	concat.Continue(time.Millisecond * 15)
+	if d := last.Duration(); d != time.Millisecond*50 {
+		t.Errorf("expected the final piece to last 50ms but got %s", d)
+	}
+	if v := concat.Volume(); v != last.Volume() {
+		t.Errorf("expected the volume of the final piece, %f, but got %f", last.Volume(), v)
+	}
+	if n := len(concat.Encode(sampleRate)); n != SampleCount(time.Millisecond*100, sampleRate) {
+		t.Errorf("expected %d samples but got %d", SampleCount(time.Millisecond*100, sampleRate),
+			n)
+	}
+
+	empty := Concat()
+	empty.Continue(time.Millisecond * 10)
+	if d := empty.Duration(); d != time.Millisecond*10 {
+		t.Errorf("expected an empty concatenation to grow with silence, but got %s", d)
+	}
+}
@@ -0,0 +1,134 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// DefaultDCCutoff is a cutoff frequency, in Hz, which removes DC offset and rumble without
+// audibly affecting speech.
+const DefaultDCCutoff = 15.0
+
+// A dcBlocker is a one-pole, one-zero high-pass filter.
+//
+// Its state starts at 0, as if the signal had been silent before the first sample, so the
+// filter never adds a step at the start of a signal.
+type dcBlocker struct {
+	pole    float64
+	lastIn  float64
+	lastOut float64
+}
+
+func newDCBlocker(cutoff float64, sampleRate int) *dcBlocker {
+	return &dcBlocker{pole: math.Exp(-2 * math.Pi * math.Max(cutoff, 0) / float64(sampleRate))}
+}
+
+func (d *dcBlocker) process(x float64) float64 {
+	d.lastOut = x - d.lastIn + d.pole*d.lastOut
+	d.lastIn = x
+	return d.lastOut
+}
+
+// BlockDC passes a signal through a DC-blocking high-pass filter in place.
+//
+// The filter has a single pole, so its phase shift is small above a few times the cutoff and
+// transients keep their shape.
+// A cutoff of 0 leaves the signal unchanged.
+func BlockDC(samples []wav.Sample, cutoff float64, sampleRate int) {
+	filter := newDCBlocker(cutoff, sampleRate)
+	for i, sample := range samples {
+		samples[i] = wav.Sample(filter.process(float64(sample)))
+	}
+}
+
+// A ConditionedTrack removes DC offset and sub-audible rumble from the encoded output of an
+// inner track, using the filter of BlockDC.
+//
+// Wrapping a TrackSet conditions its mix as a whole.
+type ConditionedTrack struct {
+	inner  Track
+	cutoff float64
+}
+
+// NewConditionedTrack creates a ConditionedTrack with the given cutoff frequency.
+// DefaultDCCutoff suits most uses.
+func NewConditionedTrack(inner Track, cutoff float64) *ConditionedTrack {
+	return &ConditionedTrack{inner: inner, cutoff: cutoff}
+}
+
+// Inner returns the track being conditioned.
+func (c *ConditionedTrack) Inner() Track {
+	return c.inner
+}
+
+// Cutoff returns the cutoff frequency of the filter.
+func (c *ConditionedTrack) Cutoff() float64 {
+	return c.cutoff
+}
+
+func (c *ConditionedTrack) Duration() time.Duration {
+	return c.inner.Duration()
+}
+
+func (c *ConditionedTrack) Encode(sampleRate int) []wav.Sample {
+	res := c.inner.Encode(sampleRate)
+	BlockDC(res, c.cutoff, sampleRate)
+	return res
+}
+
+// EncodeStereo encodes the inner track in stereo and filters each channel separately.
+func (c *ConditionedTrack) EncodeStereo(sampleRate int) []wav.Sample {
+	left, right := deinterleave(encodeStereo(c.inner, sampleRate))
+	BlockDC(left, c.cutoff, sampleRate)
+	BlockDC(right, c.cutoff, sampleRate)
+	return interleave(left, right)
+}
+
+// Stream filters a stream of the inner track, so conditioned tracks can still be exported
+// incrementally.
+func (c *ConditionedTrack) Stream(sampleRate int) SampleStream {
+	return &conditionedStream{
+		inner:  Stream(c.inner, sampleRate),
+		filter: newDCBlocker(c.cutoff, sampleRate),
+	}
+}
+
+func (c *ConditionedTrack) Continue(d time.Duration) {
+	c.inner.Continue(d)
+}
+
+func (c *ConditionedTrack) Volume() float64 {
+	return c.inner.Volume()
+}
+
+func (c *ConditionedTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	c.inner.AdjustVolume(newVolume, d)
+}
+
+func (c *ConditionedTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(c.inner, newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (c *ConditionedTrack) Clone() Track {
+	inner := cloneTrack(c.inner)
+	if inner == nil {
+		return nil
+	}
+	return &ConditionedTrack{inner: inner, cutoff: c.cutoff}
+}
+
+type conditionedStream struct {
+	inner  SampleStream
+	filter *dcBlocker
+}
+
+func (c *conditionedStream) Read(buf []wav.Sample) int {
+	n := c.inner.Read(buf)
+	for i, sample := range buf[:n] {
+		buf[i] = wav.Sample(c.filter.process(float64(sample)))
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestConditionedTrackRemovesDC(t *testing.T) {
+	const sampleRate = 16000
+	offset := make([]wav.Sample, sampleRate)
+	for i := range offset {
+		offset[i] = 0.2
+	}
+	tone := NewToneTrack(200, 0.3, 0)
+	tone.Continue(time.Second)
+	set := TrackSet{"offset": NewSampleTrack(offset, sampleRate), "tone": tone}
+	conditioned := NewConditionedTrack(set, DefaultDCCutoff)
+	samples := conditioned.Encode(sampleRate)
+
+	// The filter starts from silence, so the first sample is no louder than the input.
+	first := set.Encode(sampleRate)[0]
+	if math.Abs(float64(samples[0])) > math.Abs(float64(first)) {
+		t.Errorf("expected no click at the start, but got %f for an input of %f", samples[0], first)
+	}
+	var mean float64
+	for _, sample := range samples[sampleRate/2:] {
+		mean += float64(sample)
+	}
+	mean /= float64(sampleRate / 2)
+	if math.Abs(mean) > 0.002 {
+		t.Errorf("expected the mean to converge to 0 but got %f", mean)
+	}
+	if rms := RMSLevel(samples[sampleRate/2:]); math.Abs(rms-0.3/math.Sqrt2) > 0.005 {
+		t.Errorf("expected the tone to pass with an RMS of %f but got %f", 0.3/math.Sqrt2, rms)
+	}
+
+	// Streaming filters the same way as encoding.
+	streamed := readStream(conditioned.Stream(sampleRate), len(samples))
+	assertSamplesClose(t, samples, streamed, 1e-6)
+}
+
+func TestBlockDCShortSignal(t *testing.T) {
+	samples := []wav.Sample{0.5, 0.5, 0.5, 0.5, 0.5}
+	BlockDC(samples, DefaultDCCutoff, 16000)
+	for i, sample := range samples {
+		if math.IsNaN(float64(sample)) || sample > 0.5 || sample < 0 {
+			t.Errorf("sample %d: expected a smooth decay from 0.5 but got %f", i, sample)
+		}
+		if i > 0 && sample > samples[i-1] {
+			t.Errorf("sample %d: expected the offset to decay, but it rose to %f", i, sample)
+		}
+	}
+
+	unchanged := []wav.Sample{0.5, -0.25, 0.1}
+	BlockDC(unchanged, 0, 16000)
+	assertSamplesClose(t, []wav.Sample{0.5, -0.25, 0.1}, unchanged, 1e-6)
+}
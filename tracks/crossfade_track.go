@@ -0,0 +1,117 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A CrossfadeTrack plays one track followed by another, with an equal-power crossfade where the
+// two overlap.
+//
+// The second track is the live tail of a CrossfadeTrack: Continue, Volume, and AdjustVolume
+// all delegate to it.
+type CrossfadeTrack struct {
+	first   Track
+	second  Track
+	overlap time.Duration
+}
+
+// Crossfade creates a CrossfadeTrack which fades a out during the last overlap of its duration
+// while fading b in.
+//
+// If either track is shorter than the overlap, the overlap is reduced to the length of the
+// shorter track.
+// The overlap is fixed when the crossfade is created, so continuing the second track later only
+// extends the tail.
+func Crossfade(a, b Track, overlap time.Duration) *CrossfadeTrack {
+	if overlap < 0 {
+		overlap = 0
+	}
+	if d := a.Duration(); d < overlap {
+		overlap = d
+	}
+	if d := b.Duration(); d < overlap {
+		overlap = d
+	}
+	return &CrossfadeTrack{first: a, second: b, overlap: overlap}
+}
+
+// First returns the track which fades out.
+func (c *CrossfadeTrack) First() Track {
+	return c.first
+}
+
+// Second returns the track which fades in.
+func (c *CrossfadeTrack) Second() Track {
+	return c.second
+}
+
+// Duration returns the combined length of both tracks, minus the overlap.
+func (c *CrossfadeTrack) Duration() time.Duration {
+	return c.first.Duration() + c.second.Duration() - c.overlap
+}
+
+func (c *CrossfadeTrack) Encode(sampleRate int) []wav.Sample {
+	first := c.first.Encode(sampleRate)
+	second := c.second.Encode(sampleRate)
+
+	offset := sampleCount(c.first.Duration()-c.overlap, sampleRate)
+	fadeLength := sampleCount(c.overlap, sampleRate)
+	res := make([]wav.Sample, sampleCount(c.Duration(), sampleRate))
+	for i, sample := range first {
+		if i >= offset+fadeLength || i >= len(res) {
+			break
+		}
+		if i >= offset {
+			sample *= wav.Sample(math.Cos(math.Pi / 2 * crossfadeProgress(i-offset, fadeLength)))
+		}
+		res[i] = sample
+	}
+	for j, sample := range second {
+		if offset+j >= len(res) {
+			break
+		}
+		if j < fadeLength {
+			sample *= wav.Sample(math.Sin(math.Pi / 2 * crossfadeProgress(j, fadeLength)))
+		}
+		res[offset+j] += sample
+	}
+	return res
+}
+
+// Continue elongates the second track.
+func (c *CrossfadeTrack) Continue(d time.Duration) {
+	c.second.Continue(d)
+}
+
+// Volume returns the volume of the second track.
+func (c *CrossfadeTrack) Volume() float64 {
+	return c.second.Volume()
+}
+
+// AdjustVolume elongates the second track while adjusting its volume.
+func (c *CrossfadeTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	c.second.AdjustVolume(newVolume, d)
+}
+
+func (c *CrossfadeTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(c.second, newVolume, d, curve)
+}
+
+// Clone creates a copy of the crossfade, or returns nil if either track cannot be cloned.
+func (c *CrossfadeTrack) Clone() Track {
+	first := cloneTrack(c.first)
+	second := cloneTrack(c.second)
+	if first == nil || second == nil {
+		return nil
+	}
+	return &CrossfadeTrack{first: first, second: second, overlap: c.overlap}
+}
+
+// crossfadeProgress returns how far into a crossfade a sample is, from 0 to 1.
+// Samples are measured at their centers, so the fade is symmetric.
+func crossfadeProgress(index, length int) float64 {
+	return (float64(index) + 0.5) / float64(length)
+}
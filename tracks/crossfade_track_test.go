@@ -0,0 +1,47 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCrossfadeDuration(t *testing.T) {
+	a := NewSilenceTrack(time.Second)
+	b := NewSilenceTrack(time.Millisecond * 600)
+	if d := Crossfade(a, b, time.Millisecond*200).Duration(); d != time.Millisecond*1400 {
+		t.Errorf("expected duration 1.4s but got %v", d)
+	}
+	if d := Crossfade(a, b, time.Second*2).Duration(); d != time.Second {
+		t.Errorf("expected the overlap to be clamped to 600ms but got duration %v", d)
+	}
+	if d := Crossfade(a, b, -time.Second).Duration(); d != time.Millisecond*1600 {
+		t.Errorf("expected a negative overlap to be ignored but got duration %v", d)
+	}
+
+	short := NewSilenceTrack(time.Millisecond * 100)
+	fade := Crossfade(a, short, time.Millisecond*300)
+	fade.Continue(time.Second)
+	if d := fade.Duration(); d != time.Millisecond*2000 {
+		t.Errorf("expected continuing to keep the clamped overlap but got duration %v", d)
+	}
+	if n := len(fade.Encode(8000)); n != 16000 {
+		t.Errorf("expected 16000 samples but got %d", n)
+	}
+}
+
+func TestCrossfadeEqualPower(t *testing.T) {
+	const sampleRate = 44100
+	a := NewToneTrack(441, 0.5, 0)
+	a.Continue(time.Second)
+	b := NewToneTrack(661.5, 0.5, 0)
+	b.Continue(time.Second)
+	fade := Crossfade(a, b, time.Millisecond*400)
+
+	expected := 0.5 / math.Sqrt2
+	for i, rms := range RMSSeries(fade, sampleRate, time.Millisecond*40) {
+		if math.Abs(rms-expected) > expected*0.05 {
+			t.Errorf("window %d: expected RMS %f but got %f", i, expected, rms)
+		}
+	}
+}
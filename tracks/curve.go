@@ -0,0 +1,99 @@
+package tracks
+
+import (
+	"math"
+	"time"
+)
+
+// exponentialCurveFloor is the level, relative to the louder end of a transition, at which an
+// ExponentialCurve treats a volume as silent.
+// Without a floor, a logarithmic fade from or to zero would never move.
+const exponentialCurveFloor = 1e-3
+
+// A Curve shapes a transition from a start volume to an end volume.
+// It returns the volume after the fraction t of the transition, which runs from 0 to 1.
+type Curve func(start, end, t float64) float64
+
+var (
+	// LinearCurve moves the volume at a constant rate.
+	LinearCurve Curve = linearCurve
+
+	// ExponentialCurve moves the volume at a constant rate in decibels, which sounds even to the
+	// ear.
+	// Transitions from or to silence pass through 60dB below the louder end.
+	ExponentialCurve Curve = exponentialCurve
+
+	// CosineCurve moves the volume along a quarter of a sine wave so that the power is
+	// interpolated linearly.
+	// Two tracks which fade in and out with this curve over the same time keep a constant
+	// combined power.
+	CosineCurve Curve = cosineCurve
+)
+
+// EasingCurve creates a Curve from an easing function mapping the fraction of a transition that
+// has elapsed to the fraction of the volume change that has taken place.
+// The easing function should map 0 to 0 and 1 to 1.
+func EasingCurve(ease func(t float64) float64) Curve {
+	return func(start, end, t float64) float64 {
+		return start + (end-start)*ease(t)
+	}
+}
+
+// A CurvedTrack is a Track which can shape its volume transitions.
+type CurvedTrack interface {
+	Track
+
+	// AdjustVolumeCurve is like AdjustVolume, but the volume follows the given curve.
+	//
+	// Like every adjustment, the transition starts at the end of the track, from the volume
+	// which the previous transition reached.
+	// A zero transition time sets the volume immediately, regardless of the curve.
+	AdjustVolumeCurve(newVolume float64, transitionTime time.Duration, curve Curve)
+}
+
+// AdjustVolumeCurve adjusts the volume of a track along a curve.
+// If the track is not a CurvedTrack, this falls back on a linear AdjustVolume.
+func AdjustVolumeCurve(t Track, newVolume float64, d time.Duration, curve Curve) {
+	if ct, ok := t.(CurvedTrack); ok {
+		ct.AdjustVolumeCurve(newVolume, d, curve)
+	} else {
+		t.AdjustVolume(newVolume, d)
+	}
+}
+
+// evaluateCurve evaluates a curve, treating a nil curve as linear.
+// The ends of the transition are exact regardless of the curve.
+func evaluateCurve(curve Curve, start, end, t float64) float64 {
+	if t <= 0 {
+		return start
+	} else if t >= 1 {
+		return end
+	} else if curve == nil {
+		return linearCurve(start, end, t)
+	}
+	return curve(start, end, t)
+}
+
+func linearCurve(start, end, t float64) float64 {
+	return t*end + (1-t)*start
+}
+
+func exponentialCurve(start, end, t float64) float64 {
+	if start < 0 || end < 0 || start == end {
+		return linearCurve(start, end, t)
+	}
+	floor := math.Max(start, end) * exponentialCurveFloor
+	logStart := math.Log(math.Max(start, floor))
+	logEnd := math.Log(math.Max(end, floor))
+	return math.Exp(linearCurve(logStart, logEnd, t))
+}
+
+func cosineCurve(start, end, t float64) float64 {
+	if start < 0 || end < 0 {
+		return linearCurve(start, end, t)
+	}
+	angle := t * math.Pi / 2
+	startGain := start * math.Cos(angle)
+	endGain := end * math.Sin(angle)
+	return math.Sqrt(startGain*startGain + endGain*endGain)
+}
@@ -0,0 +1,87 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCurveShapes(t *testing.T) {
+	smoothstep := EasingCurve(func(t float64) float64 {
+		return t * t * (3 - 2*t)
+	})
+	for _, curve := range []Curve{nil, LinearCurve, ExponentialCurve, CosineCurve, smoothstep} {
+		if v := evaluateCurve(curve, 0.2, 0.8, 0); v != 0.2 {
+			t.Errorf("expected a curve to start at 0.2 but got %f", v)
+		}
+		if v := evaluateCurve(curve, 0.2, 0.8, 1); v != 0.8 {
+			t.Errorf("expected a curve to end at 0.8 but got %f", v)
+		}
+	}
+	for _, test := range []struct {
+		curve    Curve
+		expected float64
+	}{
+		{LinearCurve, 0.55},
+		{ExponentialCurve, math.Sqrt(0.1)},
+		{CosineCurve, math.Sqrt((0.01 + 1) / 2)},
+		{smoothstep, 0.55},
+	} {
+		if v := evaluateCurve(test.curve, 0.1, 1, 0.5); math.Abs(v-test.expected) > 1e-12 {
+			t.Errorf("expected a midpoint of %f but got %f", test.expected, v)
+		}
+	}
+	if v := ExponentialCurve(0, 1, 0.5); math.Abs(v-math.Sqrt(exponentialCurveFloor)) > 1e-12 {
+		t.Errorf("expected a fade in from silence to pass through the floor, but got %f", v)
+	}
+}
+
+func TestToneTrackVolumeCurve(t *testing.T) {
+	const sampleRate = 1000
+
+	// A tone with no frequency which starts a quarter turn in holds its volume as its sample
+	// value, so the encoding is the volume envelope.
+	newTone := func() *ToneTrack {
+		return NewToneTrackPhase(0, 0.1, 0, 0.25)
+	}
+	for _, curve := range []Curve{LinearCurve, ExponentialCurve, CosineCurve} {
+		tone := newTone()
+		tone.AdjustVolumeCurve(1, time.Second, curve)
+		samples := tone.Encode(sampleRate)
+		for _, i := range []int{0, 250, 500, 750} {
+			expected := curve(0.1, 1, float64(i)/sampleRate)
+			if math.Abs(float64(samples[i])-expected) > 1e-9 {
+				t.Errorf("sample %d: expected %f but got %f", i, expected, samples[i])
+			}
+		}
+	}
+
+	// A zero-length transition sets the volume immediately, and the next transition starts from
+	// there.
+	tone := newTone()
+	AdjustVolumeCurve(tone, 0.5, 0, ExponentialCurve)
+	tone.AdjustVolumeCurve(0.05, time.Second, ExponentialCurve)
+	samples := tone.Encode(sampleRate)
+	if math.Abs(float64(samples[0])-0.5) > 1e-9 {
+		t.Errorf("expected the fade to start from 0.5 but got %f", samples[0])
+	}
+	if v := tone.Volume(); v != 0.05 {
+		t.Errorf("expected volume 0.05 but got %f", v)
+	}
+}
+
+func TestTrackSetAdjustVolumeCurve(t *testing.T) {
+	set := TrackSet{
+		"tone":    NewToneTrackPhase(0, 0.1, 0, 0.25),
+		"silence": NewSilenceTrack(0),
+	}
+	set.AdjustVolumeCurve(1, time.Second, ExponentialCurve)
+	samples := set["tone"].Encode(1000)
+	if expected := math.Sqrt(0.1 * 0.5); math.Abs(float64(samples[500])-expected) > 1e-9 {
+		t.Errorf("expected the member to follow the curve to %f, but got %f", expected,
+			samples[500])
+	}
+	if d := set["silence"].Duration(); d != time.Second {
+		t.Errorf("expected tracks without curves to be elongated, but got duration %v", d)
+	}
+}
@@ -0,0 +1,32 @@
+package tracks
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+const dataURIPrefix = "data:audio/wav;base64,"
+
+// DataURI encodes a track as a mono WAV file and returns it as a base64 data URI, which can be
+// used directly as the src of an HTML <audio> element.
+// The bitDepth must be 8, 16, or 24.
+func DataURI(t Track, sampleRate, bitDepth int) (string, error) {
+	opts := EncodeOptions{SampleRate: sampleRate, BitDepth: bitDepth, Channels: 1}
+	if err := opts.validate(); err != nil {
+		return "", err
+	}
+
+	// The WAV header is 44 bytes, and base64 expands its input by a factor of 4/3.
+	var res strings.Builder
+	wavSize := 44 + sampleCount(t.Duration(), sampleRate)*bitDepth/8
+	res.Grow(len(dataURIPrefix) + wavSize*4/3 + 4)
+	res.WriteString(dataURIPrefix)
+	encoder := base64.NewEncoder(base64.StdEncoding, &res)
+	if err := WriteWAV(t, encoder, opts); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return res.String(), nil
+}
@@ -0,0 +1,42 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestDataURI(t *testing.T) {
+	tone := NewToneTrack(440, 0.5, 0)
+	tone.Continue(time.Millisecond * 250)
+	uri, err := DataURI(tone, 8000, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(uri, "data:audio/wav;base64,") {
+		t.Fatalf("unexpected URI prefix: %q", uri[:30])
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, "data:audio/wav;base64,"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sound, err := wav.ReadSound(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sound.SampleRate() != 8000 || sound.Channels() != 1 {
+		t.Errorf("expected mono 8000 Hz but got %d channels at %d Hz", sound.Channels(),
+			sound.SampleRate())
+	}
+	assertSamplesClose(t, tone.Encode(8000), sound.Samples(), 1e-4)
+}
+
+func TestDataURIInvalidBitDepth(t *testing.T) {
+	if _, err := DataURI(NewSilenceTrack(time.Second), 8000, 12); err == nil {
+		t.Error("expected an error for a 12-bit data URI")
+	}
+}
@@ -0,0 +1,187 @@
+package tracks
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A DroneTrack sustains a chord drawn from a scale and slowly evolves it by swapping one voice at
+// a time for another tone of the scale.
+//
+// Each period of the drone holds the current chord for the movement time and then crossfades one
+// voice into a new tone over the movement time.
+// The choice of voices is driven by a seed, so a drone is reproducible.
+type DroneTrack struct {
+	voices      []*ToneTrack
+	voiced      []bool
+	voiceVolume float64
+	movement    time.Duration
+	source      *seededSource
+	random      *rand.Rand
+
+	scheduled time.Duration
+	gain      *envelope
+}
+
+// NewDroneTrack creates a zero-length DroneTrack.
+//
+// The scale lists note names, as understood by NoteFrequency.
+// The density, between 0 and 1, is the fraction of the scale which sounds at once.
+// The movement is the duration of each hold and each crossfade.
+func NewDroneTrack(scale []string, density float64, movement time.Duration,
+	seed int64) (*DroneTrack, error) {
+	if len(scale) == 0 {
+		return nil, errors.New("empty scale")
+	}
+	if movement <= 0 {
+		return nil, errors.New("movement must be positive")
+	}
+	frequencies := make([]float64, len(scale))
+	for i, name := range scale {
+		freq, err := NoteFrequency(name)
+		if err != nil {
+			return nil, err
+		}
+		frequencies[i] = freq
+	}
+
+	voiceCount := int(math.Ceil(density * float64(len(scale))))
+	if voiceCount < 1 {
+		voiceCount = 1
+	} else if voiceCount > len(scale) {
+		voiceCount = len(scale)
+	}
+
+	source := newSeededSource(seed)
+	random := rand.New(source)
+	res := &DroneTrack{
+		voices:      make([]*ToneTrack, len(scale)),
+		voiced:      make([]bool, len(scale)),
+		voiceVolume: 1 / float64(voiceCount),
+		movement:    movement,
+		source:      source,
+		random:      random,
+		gain:        newEnvelope(1),
+	}
+	for _, idx := range random.Perm(len(scale))[:voiceCount] {
+		res.voiced[idx] = true
+	}
+	for i, freq := range frequencies {
+		var volume float64
+		if res.voiced[i] {
+			volume = res.voiceVolume
+		}
+		res.voices[i] = NewToneTrack(freq, volume, 0)
+	}
+	return res, nil
+}
+
+func (d *DroneTrack) Duration() time.Duration {
+	return d.gain.Duration()
+}
+
+func (d *DroneTrack) Encode(sampleRate int) []wav.Sample {
+	encodedVoices := make([][]wav.Sample, len(d.voices))
+	for i, voice := range d.voices {
+		encodedVoices[i] = voice.Encode(sampleRate)
+	}
+	mix := mixSamples(encodedVoices)
+
+	res := make([]wav.Sample, sampleCount(d.Duration(), sampleRate))
+	copy(res, mix)
+	for i, gain := range d.gain.Values(sampleRate, len(res)) {
+		res[i] *= wav.Sample(gain)
+	}
+	return res
+}
+
+// Continue elongates the drone, scheduling voice swaps as needed.
+func (d *DroneTrack) Continue(duration time.Duration) {
+	d.gain.Continue(duration)
+	d.schedule()
+}
+
+// Volume returns the overall gain of the drone.
+// At a gain of 1, the voices' amplitudes sum to 1.
+func (d *DroneTrack) Volume() float64 {
+	return d.gain.Value()
+}
+
+// AdjustVolume elongates the drone while adjusting its overall gain.
+func (d *DroneTrack) AdjustVolume(newVolume float64, duration time.Duration) {
+	d.gain.Adjust(newVolume, duration)
+	d.schedule()
+}
+
+// AdjustVolumeCurve is like AdjustVolume, but the gain follows the given curve.
+func (d *DroneTrack) AdjustVolumeCurve(newVolume float64, duration time.Duration, curve Curve) {
+	d.gain.AdjustCurve(newVolume, duration, curve)
+	d.schedule()
+}
+
+// Clone creates a copy of the drone which continues to evolve in the same way as the original.
+func (d *DroneTrack) Clone() Track {
+	source := d.source.clone()
+	res := &DroneTrack{
+		voices:      make([]*ToneTrack, len(d.voices)),
+		voiced:      append([]bool{}, d.voiced...),
+		voiceVolume: d.voiceVolume,
+		movement:    d.movement,
+		source:      source,
+		random:      rand.New(source),
+		scheduled:   d.scheduled,
+		gain:        d.gain.clone(),
+	}
+	for i, voice := range d.voices {
+		res.voices[i] = voice.Clone().(*ToneTrack)
+	}
+	return res
+}
+
+// schedule appends hold-and-swap periods until the voices cover the drone's duration.
+func (d *DroneTrack) schedule() {
+	for d.scheduled < d.Duration() {
+		for _, voice := range d.voices {
+			voice.Continue(d.movement)
+		}
+		d.swapVoice()
+		d.scheduled += d.movement * 2
+	}
+}
+
+// swapVoice crossfades one randomly chosen voice into a randomly chosen unused tone.
+func (d *DroneTrack) swapVoice() {
+	var voicedIndices, unvoicedIndices []int
+	for i, voiced := range d.voiced {
+		if voiced {
+			voicedIndices = append(voicedIndices, i)
+		} else {
+			unvoicedIndices = append(unvoicedIndices, i)
+		}
+	}
+	if len(unvoicedIndices) == 0 {
+		for _, voice := range d.voices {
+			voice.Continue(d.movement)
+		}
+		return
+	}
+
+	oldVoice := voicedIndices[d.random.Intn(len(voicedIndices))]
+	newVoice := unvoicedIndices[d.random.Intn(len(unvoicedIndices))]
+	for i, voice := range d.voices {
+		switch i {
+		case oldVoice:
+			voice.AdjustVolume(0, d.movement)
+		case newVoice:
+			voice.AdjustVolume(d.voiceVolume, d.movement)
+		default:
+			voice.Continue(d.movement)
+		}
+	}
+	d.voiced[oldVoice] = false
+	d.voiced[newVoice] = true
+}
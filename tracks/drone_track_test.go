@@ -0,0 +1,96 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+var testDroneScale = []string{"C4", "D4", "E4", "G4", "A4", "C5"}
+
+func TestDroneTrackDeterministic(t *testing.T) {
+	newDrone := func(seed int64) *DroneTrack {
+		res, err := NewDroneTrack(testDroneScale, 0.5, time.Millisecond*100, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	voicings := func(d *DroneTrack) [][]bool {
+		var res [][]bool
+		for i := 0; i < 10; i++ {
+			d.Continue(time.Millisecond * 200)
+			res = append(res, append([]bool{}, d.voiced...))
+		}
+		return res
+	}
+
+	first, second := newDrone(3), newDrone(3)
+	firstVoicings, secondVoicings := voicings(first), voicings(second)
+	for i := range firstVoicings {
+		for j := range firstVoicings[i] {
+			if firstVoicings[i][j] != secondVoicings[i][j] {
+				t.Fatalf("period %d: voicings differ for the same seed", i)
+			}
+		}
+	}
+	assertSamplesClose(t, first.Encode(8000), second.Encode(8000), 0)
+
+	clone := first.Clone()
+	clone.Continue(time.Second)
+	first.Continue(time.Second)
+	assertSamplesClose(t, first.Encode(8000), clone.Encode(8000), 1e-12)
+
+	other := newDrone(4)
+	voicings(other)
+	if samplesEqual(first.Encode(8000)[:16000], other.Encode(8000)) {
+		t.Error("expected different seeds to evolve differently")
+	}
+}
+
+func TestDroneTrackCrossfadesVoices(t *testing.T) {
+	const sampleRate = 44100
+	drone, err := NewDroneTrack(testDroneScale, 0.5, time.Millisecond*50, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	drone.Continue(time.Second * 2)
+
+	// A sine of amplitude a and frequency f changes by at most 2*pi*f*a/sampleRate per sample.
+	// While a voice fades out and another fades in, one extra voice sounds, so a jump from a
+	// voice switching abruptly would exceed this bound.
+	maxFreq, err := NoteFrequency("C5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	voiceCount := math.Round(1 / drone.voiceVolume)
+	bound := (voiceCount + 1) * drone.voiceVolume * 2 * math.Pi * maxFreq / sampleRate
+	samples := drone.Encode(sampleRate)
+	for i := 1; i < len(samples); i++ {
+		if diff := math.Abs(float64(samples[i] - samples[i-1])); diff > bound {
+			t.Fatalf("sample %d: jump of %f exceeds %f", i, diff, bound)
+		}
+	}
+}
+
+func TestNoteFrequency(t *testing.T) {
+	for name, expected := range map[string]float64{
+		"A4":  440,
+		"A3":  220,
+		"C4":  261.6256,
+		"C#3": 138.5913,
+		"Bb2": 116.5409,
+	} {
+		actual, err := NoteFrequency(name)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		} else if math.Abs(actual-expected) > 1e-3 {
+			t.Errorf("%s: expected %f but got %f", name, expected, actual)
+		}
+	}
+	for _, name := range []string{"", "H4", "A", "C#", "Ax"} {
+		if _, err := NoteFrequency(name); err == nil {
+			t.Errorf("expected an error for %q", name)
+		}
+	}
+}
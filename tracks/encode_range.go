@@ -0,0 +1,196 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A RangeTrack is a Track which can encode a window of its samples without encoding everything
+// before the window.
+type RangeTrack interface {
+	Track
+
+	// EncodeRange returns the samples of Encode whose timestamps fall within [from, to).
+	// The window is clamped to the encoding, and it is empty if from >= to.
+	EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample
+}
+
+// EncodeRange returns the samples of a track's encoding whose timestamps fall within [from, to).
+// The window is clamped to the encoding, and it is empty if from >= to.
+//
+// The samples match the corresponding slice of Encode exactly.
+// RangeTracks seek to the window directly, StreamingTracks are streamed up to the window, and
+// other tracks are encoded in full.
+func EncodeRange(t Track, sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	return encodeSampleRange(t, sampleRate, start, end)
+}
+
+// rangeSamples converts a window of time into the indices of the samples it spans.
+func rangeSamples(sampleRate int, from, to time.Duration) (start, end int) {
+	if from < 0 {
+		from = 0
+	}
+	start, end = sampleCount(from, sampleRate), sampleCount(to, sampleRate)
+	if end < start {
+		end = start
+	}
+	return
+}
+
+// encodeSampleRange is like EncodeRange, but the window is given by the indices of its first
+// sample and the sample after its last.
+func encodeSampleRange(t Track, sampleRate, start, end int) []wav.Sample {
+	if end <= start {
+		return []wav.Sample{}
+	}
+	if rt, ok := t.(RangeTrack); ok {
+		return rt.EncodeRange(sampleRate, sampleTime(start, sampleRate),
+			sampleTime(end, sampleRate))
+	}
+	if st, ok := t.(StreamingTrack); ok {
+		return streamRange(st.Stream(sampleRate), start, end)
+	}
+	return sliceRange(t.Encode(sampleRate), start, end)
+}
+
+// streamRange reads the samples [start, end) from a stream, discarding the ones before them.
+func streamRange(s SampleStream, start, end int) []wav.Sample {
+	scratch := make([]wav.Sample, streamChunkSize)
+	for skipped := 0; skipped < start; {
+		chunk := scratch
+		if start-skipped < len(chunk) {
+			chunk = chunk[:start-skipped]
+		}
+		n := s.Read(chunk)
+		skipped += n
+		if n < len(chunk) {
+			return []wav.Sample{}
+		}
+	}
+	res := make([]wav.Sample, end-start)
+	return res[:s.Read(res)]
+}
+
+// sliceRange copies the samples [start, end) of an encoding, clamped to its length.
+func sliceRange(samples []wav.Sample, start, end int) []wav.Sample {
+	if end > len(samples) {
+		end = len(samples)
+	}
+	if end <= start {
+		return []wav.Sample{}
+	}
+	return append([]wav.Sample{}, samples[start:end]...)
+}
+
+// EncodeRange is like Encode, but it only mixes the members' samples within [from, to), as in
+// the package-level EncodeRange.
+// Like Encode, the result is as long as the longest member's window.
+func (t TrackSet) EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	ids := t.sortedIDs()
+	encodedTracks := make([][]wav.Sample, len(ids))
+	for i, id := range ids {
+		encodedTracks[i] = encodeSampleRange(t[id], sampleRate, start, end)
+	}
+	return mixSamples(encodedTracks)
+}
+
+// EncodeRange is like TrackSet.EncodeRange, but each member's window is scaled by its gain.
+func (w *WeightedTrackSet) EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	ids := w.set.sortedIDs()
+	encodedTracks := make([][]wav.Sample, len(ids))
+	for i, id := range ids {
+		encodedTracks[i] = scaledSamples(encodeSampleRange(w.set[id], sampleRate, start, end),
+			w.Gain(id))
+	}
+	return mixSamples(encodedTracks)
+}
+
+// EncodeRange generates the zero samples within [from, to).
+func (s *SilenceTrack) EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	if total := sampleCount(s.duration, sampleRate); end > total {
+		end = total
+	}
+	if end <= start {
+		return []wav.Sample{}
+	}
+	return make([]wav.Sample, end-start)
+}
+
+// EncodeRange returns the samples within [from, to).
+// The tone is only generated up to the end of the window, and a later window picks up from there
+// rather than generating the tone from the start.
+func (s *ToneTrack) EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	if end <= start {
+		return []wav.Sample{}
+	}
+	return s.cursorRange(sampleRate, start, end)
+}
+
+// EncodeRange encodes the part of the source within [from, to), plus any of the silence the
+// track was continued with.
+func (s *SliceTrack) EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	if total := sampleCount(s.Duration(), sampleRate); end > total {
+		end = total
+	}
+	if end <= start {
+		return []wav.Sample{}
+	}
+	res := make([]wav.Sample, end-start)
+	offset := sampleCount(s.from, sampleRate)
+	sourceEnd := offset + end
+	if limit := sampleCount(s.to, sampleRate); sourceEnd > limit {
+		sourceEnd = limit
+	}
+	copy(res, encodeSampleRange(s.source, sampleRate, offset+start, sourceEnd))
+	return res
+}
+
+// EncodeRange encodes the parts of the pieces within [from, to), skipping the pieces outside of
+// it.
+func (c *ConcatTrack) EncodeRange(sampleRate int, from, to time.Duration) []wav.Sample {
+	start, end := rangeSamples(sampleRate, from, to)
+	if total := sampleCount(c.Duration(), sampleRate); end > total {
+		end = total
+	}
+	if end <= start {
+		return []wav.Sample{}
+	}
+	res := make([]wav.Sample, end-start)
+	var pieceStart time.Duration
+	for _, piece := range c.pieces {
+		pieceEnd := pieceStart + piece.Duration()
+		offset := sampleCount(pieceStart, sampleRate)
+		room := sampleCount(pieceEnd, sampleRate) - offset
+		pieceStart = pieceEnd
+		windowStart, windowEnd := start-offset, end-offset
+		if windowStart < 0 {
+			windowStart = 0
+		}
+		if windowEnd > room {
+			windowEnd = room
+		}
+		if windowEnd <= windowStart {
+			continue
+		}
+		samples := encodeSampleRange(piece, sampleRate, windowStart, windowEnd)
+		n := copy(res[offset+windowStart-start:], samples)
+		if n < windowEnd-windowStart {
+			// Like Encode, a piece which runs short holds its last frame.
+			full := piece.Encode(sampleRate)
+			if len(full) == 0 {
+				continue
+			}
+			for i := windowStart + n; i < windowEnd; i++ {
+				res[offset+i-start] = full[len(full)-1]
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,67 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEncodeRangeMatchesEncode(t *testing.T) {
+	tone := NewToneTrack(440, 0.3, 10)
+	tone.AdjustFrequency(220, time.Millisecond*250)
+	silent := NewSilenceTrack(time.Millisecond * 100)
+	slice := Slice(tone.Clone(), time.Millisecond*40, time.Millisecond*200)
+	concat := Concat(tone.Clone(), silent.Clone(), tone.Clone())
+	set := TrackSet{"tone": tone, "silence": silent, "slice": slice, "concat": concat}
+
+	for _, sampleRate := range []int{8000, 22050, 44100} {
+		full := set.Encode(sampleRate)
+		for _, window := range [][2]time.Duration{
+			{0, time.Millisecond * 10},
+			{time.Millisecond * 33, time.Millisecond * 170},
+			{time.Millisecond * 240, time.Second},
+			{-time.Millisecond, time.Millisecond * 2},
+		} {
+			start, end := rangeSamples(sampleRate, window[0], window[1])
+			if end > len(full) {
+				end = len(full)
+			}
+			actual := EncodeRange(set, sampleRate, window[0], window[1])
+			assertSamplesClose(t, full[start:end], actual, 0)
+		}
+	}
+}
+
+func TestEncodeRangeEmpty(t *testing.T) {
+	tone := NewToneTrack(440, 0.3, 0)
+	tone.Continue(time.Second)
+	if res := EncodeRange(tone, 8000, time.Millisecond*500, time.Millisecond*100); len(res) != 0 {
+		t.Errorf("expected an empty window but got %d samples", len(res))
+	}
+	if res := EncodeRange(tone, 8000, time.Second*2, time.Second*3); len(res) != 0 {
+		t.Errorf("expected an empty window past the end but got %d samples", len(res))
+	}
+}
+
+func TestToneTrackEncodeRangeStopsAtWindow(t *testing.T) {
+	tone := NewToneTrack(440, 0.3, 15)
+	tone.SetSeed(3)
+	tone.SetJitter(1)
+	tone.Continue(time.Second * 10)
+	EncodeRange(tone, 8000, 0, time.Millisecond*100)
+	if n := tone.cursor.stream.sampleIndex; n != 800 {
+		t.Errorf("expected the tone to be generated up to sample 800 but got %d", n)
+	}
+
+	// Consecutive windows carry on from the cursor, and they match a full encoding.
+	expected := tone.Clone().Encode(8000)
+	for i := 1; i < 5; i++ {
+		from, to := time.Millisecond*100*time.Duration(i), time.Millisecond*100*time.Duration(i+1)
+		assertSamplesClose(t, expected[800*i:800*(i+1)], EncodeRange(tone, 8000, from, to), 0)
+		if n := tone.cursor.stream.sampleIndex; n != 800*(i+1) {
+			t.Errorf("window %d: expected the cursor to stop at sample %d but got %d", i,
+				800*(i+1), n)
+		}
+	}
+	assertSamplesClose(t, expected[:800], EncodeRange(tone, 8000, 0, time.Millisecond*100), 0)
+	assertSamplesClose(t, expected, tone.Encode(8000), 0)
+}
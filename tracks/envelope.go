@@ -0,0 +1,104 @@
+package tracks
+
+import "time"
+
+// An envelope is a piecewise-linear curve which is elongated in the same way as a Track.
+// Wrapper tracks use it to automate parameters, such as a gain for implementing AdjustVolume on
+// top of material they do not generate.
+type envelope struct {
+	segments []*envelopeSegment
+}
+
+// newEnvelope creates a zero-length envelope which starts at the given value.
+func newEnvelope(value float64) *envelope {
+	return &envelope{
+		segments: []*envelopeSegment{
+			&envelopeSegment{startValue: value, endValue: value},
+		},
+	}
+}
+
+func (e *envelope) Duration() (res time.Duration) {
+	for _, seg := range e.segments {
+		res += seg.duration
+	}
+	return
+}
+
+// Value returns the value at the end of the envelope.
+func (e *envelope) Value() float64 {
+	return e.lastSegment().endValue
+}
+
+// Continue elongates the envelope without changing its value.
+func (e *envelope) Continue(d time.Duration) {
+	last := e.lastSegment()
+	if last.startValue == last.endValue {
+		last.duration += d
+	} else {
+		e.Adjust(last.endValue, d)
+	}
+}
+
+// Adjust elongates the envelope while moving it to a new value.
+func (e *envelope) Adjust(value float64, d time.Duration) {
+	e.segments = append(e.segments, &envelopeSegment{
+		duration:   d,
+		startValue: e.Value(),
+		endValue:   value,
+	})
+}
+
+// AdjustCurve is like Adjust, but the value follows the given curve.
+func (e *envelope) AdjustCurve(value float64, d time.Duration, curve Curve) {
+	e.Adjust(value, d)
+	e.lastSegment().curve = curve
+}
+
+// Values computes the envelope's value for count consecutive samples.
+// Samples past the end of the envelope use its final value.
+func (e *envelope) Values(sampleRate, count int) []float64 {
+	res := make([]float64, count)
+	var segStart time.Duration
+	var segIndex int
+	for i := range res {
+		t := time.Duration(float64(time.Second) * float64(i) / float64(sampleRate))
+		for segIndex < len(e.segments)-1 && t >= segStart+e.segments[segIndex].duration {
+			segStart += e.segments[segIndex].duration
+			segIndex++
+		}
+		res[i] = e.segments[segIndex].valueAtTime(t - segStart)
+	}
+	return res
+}
+
+// clone creates a copy of the envelope which can be elongated independently.
+func (e *envelope) clone() *envelope {
+	res := &envelope{segments: make([]*envelopeSegment, len(e.segments))}
+	for i, seg := range e.segments {
+		segCopy := *seg
+		res.segments[i] = &segCopy
+	}
+	return res
+}
+
+func (e *envelope) lastSegment() *envelopeSegment {
+	return e.segments[len(e.segments)-1]
+}
+
+type envelopeSegment struct {
+	duration   time.Duration
+	startValue float64
+	endValue   float64
+
+	// curve shapes the transition, which is linear if this is nil.
+	curve Curve
+}
+
+func (s *envelopeSegment) valueAtTime(t time.Duration) float64 {
+	if t >= s.duration {
+		return s.endValue
+	}
+	fracDone := float64(t) / float64(s.duration)
+	return evaluateCurve(s.curve, s.startValue, s.endValue, fracDone)
+}
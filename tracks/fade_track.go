@@ -0,0 +1,129 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A FadeTrack fades the encoded output of an inner track in at its beginning and out at its end.
+//
+// The fades are applied to the inner track's output rather than to its volumes, so fading a
+// TrackSet fades the mix as a whole, and the track is not elongated.
+// The fade out stays at the end of the track as the inner track is continued.
+// Fades longer than the track are shortened to the track's length.
+type FadeTrack struct {
+	inner   Track
+	fadeIn  time.Duration
+	fadeOut time.Duration
+}
+
+// NewFadeTrack creates a FadeTrack with the given fade lengths.
+func NewFadeTrack(inner Track, fadeIn, fadeOut time.Duration) *FadeTrack {
+	return &FadeTrack{inner: inner, fadeIn: fadeIn, fadeOut: fadeOut}
+}
+
+// FadeIn creates a FadeTrack which fades the first d of a track in from silence.
+// If t is already a FadeTrack, the result keeps its fade out.
+func FadeIn(t Track, d time.Duration) *FadeTrack {
+	if f, ok := t.(*FadeTrack); ok {
+		return &FadeTrack{inner: f.inner, fadeIn: d, fadeOut: f.fadeOut}
+	}
+	return NewFadeTrack(t, d, 0)
+}
+
+// FadeOut creates a FadeTrack which fades the last d of a track out to silence, so that the
+// track does not end with a click.
+// If t is already a FadeTrack, the result keeps its fade in.
+func FadeOut(t Track, d time.Duration) *FadeTrack {
+	if f, ok := t.(*FadeTrack); ok {
+		return &FadeTrack{inner: f.inner, fadeIn: f.fadeIn, fadeOut: d}
+	}
+	return NewFadeTrack(t, 0, d)
+}
+
+// FadeInDuration returns the length of the fade in.
+func (f *FadeTrack) FadeInDuration() time.Duration {
+	return f.fadeIn
+}
+
+// FadeOutDuration returns the length of the fade out.
+func (f *FadeTrack) FadeOutDuration() time.Duration {
+	return f.fadeOut
+}
+
+// Inner returns the track being faded.
+func (f *FadeTrack) Inner() Track {
+	return f.inner
+}
+
+func (f *FadeTrack) Duration() time.Duration {
+	return f.inner.Duration()
+}
+
+func (f *FadeTrack) Encode(sampleRate int) []wav.Sample {
+	res := f.inner.Encode(sampleRate)
+	f.applyFades(res, 1, sampleRate)
+	return res
+}
+
+// EncodeStereo encodes the inner track in stereo and fades both channels together.
+func (f *FadeTrack) EncodeStereo(sampleRate int) []wav.Sample {
+	res := encodeStereo(f.inner, sampleRate)
+	f.applyFades(res, 2, sampleRate)
+	return res
+}
+
+func (f *FadeTrack) Continue(d time.Duration) {
+	f.inner.Continue(d)
+}
+
+func (f *FadeTrack) Volume() float64 {
+	return f.inner.Volume()
+}
+
+func (f *FadeTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	f.inner.AdjustVolume(newVolume, d)
+}
+
+func (f *FadeTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(f.inner, newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (f *FadeTrack) Clone() Track {
+	inner := cloneTrack(f.inner)
+	if inner == nil {
+		return nil
+	}
+	return &FadeTrack{inner: inner, fadeIn: f.fadeIn, fadeOut: f.fadeOut}
+}
+
+// applyFades scales interleaved frames by the fade envelope.
+// The envelope is 0 at the first frame of the fade in and at the last frame of the fade out.
+func (f *FadeTrack) applyFades(samples []wav.Sample, channels, sampleRate int) {
+	frames := len(samples) / channels
+	fadeIn := sampleCount(f.fadeIn, sampleRate)
+	if fadeIn > frames {
+		fadeIn = frames
+	}
+	fadeOut := sampleCount(f.fadeOut, sampleRate)
+	if fadeOut > frames {
+		fadeOut = frames
+	}
+	for i := 0; i < frames; i++ {
+		gain := 1.0
+		if i < fadeIn {
+			gain = float64(i) / float64(fadeIn)
+		}
+		if fromEnd := frames - 1 - i; fromEnd < fadeOut {
+			gain *= float64(fromEnd) / float64(fadeOut)
+		}
+		if gain == 1 {
+			continue
+		}
+		for j := 0; j < channels; j++ {
+			samples[i*channels+j] *= wav.Sample(gain)
+		}
+	}
+}
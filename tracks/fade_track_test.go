@@ -0,0 +1,90 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestFadeEnvelope(t *testing.T) {
+	const sampleRate = 8000
+	constant := make([]wav.Sample, 1000)
+	for i := range constant {
+		constant[i] = 0.5
+	}
+	for _, test := range []struct {
+		fadeIn  time.Duration
+		fadeOut time.Duration
+		rampIn  int
+		rampOut int
+	}{
+		{0, time.Millisecond * 50, 0, 400},
+		{time.Millisecond * 20, 0, 160, 0},
+		{time.Millisecond * 20, time.Millisecond * 50, 160, 400},
+
+		// Fades longer than the track are shortened to fit.
+		{0, time.Second, 0, 1000},
+		{time.Second, 0, 1000, 0},
+	} {
+		track := FadeIn(FadeOut(NewSampleTrack(constant, sampleRate), test.fadeOut), test.fadeIn)
+		if track.Duration() != time.Millisecond*125 {
+			t.Errorf("%v: expected the fade to keep the duration, but got %s", test,
+				track.Duration())
+		}
+		samples := track.Encode(sampleRate)
+		if test.rampOut > 0 && samples[len(samples)-1] != 0 {
+			t.Errorf("%v: expected the last sample to be 0 but got %f", test,
+				samples[len(samples)-1])
+		}
+		if test.rampIn > 0 && samples[0] != 0 {
+			t.Errorf("%v: expected the first sample to be 0 but got %f", test, samples[0])
+		}
+		for i := 1; i < len(samples); i++ {
+			if i < test.rampIn && samples[i] <= samples[i-1] {
+				t.Fatalf("%v: sample %d: expected the fade in to rise, but got %f after %f", test,
+					i, samples[i], samples[i-1])
+			}
+			if i >= len(samples)-test.rampOut && i > test.rampIn && samples[i] >= samples[i-1] {
+				t.Fatalf("%v: sample %d: expected the fade out to fall, but got %f after %f",
+					test, i, samples[i], samples[i-1])
+			}
+			if i >= test.rampIn && i < len(samples)-test.rampOut && samples[i] != 0.5 {
+				t.Fatalf("%v: sample %d: expected no fade but got %f", test, i, samples[i])
+			}
+		}
+	}
+}
+
+func TestFadeTrackSet(t *testing.T) {
+	const sampleRate = 16000
+	low := NewToneTrack(200, 0.6, 0)
+	high := NewToneTrack(700, 0.6, 0)
+	set := TrackSet{"low": low, "high": high}
+	set.Continue(time.Millisecond * 200)
+	faded := FadeOut(set, time.Millisecond*30)
+
+	// The fade stays at the end as the mix is continued.
+	faded.Continue(time.Millisecond * 100)
+	if faded.Duration() != time.Millisecond*300 || set.Duration() != time.Millisecond*300 {
+		t.Fatalf("expected both tracks to last 300ms, but got %s and %s", faded.Duration(),
+			set.Duration())
+	}
+	mix := set.Encode(sampleRate)
+	samples := faded.Encode(sampleRate)
+	if len(samples) != len(mix) {
+		t.Fatalf("expected %d samples but got %d", len(mix), len(samples))
+	}
+	fade := sampleCount(time.Millisecond*30, sampleRate)
+	assertSamplesClose(t, mix[:len(mix)-fade], samples[:len(mix)-fade], 0)
+	for i := len(mix) - fade; i < len(mix); i++ {
+		gain := float64(len(mix)-1-i) / float64(fade)
+		if diff := float64(samples[i]) - float64(mix[i])*gain; diff > 1e-6 || diff < -1e-6 {
+			t.Fatalf("sample %d: expected the mix scaled by %f, but got %f rather than %f", i,
+				gain, samples[i], float64(mix[i])*gain)
+		}
+	}
+	if samples[len(samples)-1] != 0 {
+		t.Errorf("expected the mix to end at 0 but got %f", samples[len(samples)-1])
+	}
+}
@@ -0,0 +1,57 @@
+package tracks
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// fft computes the discrete Fourier transform of a signal in place.
+// The length of the signal must be a power of two.
+func fft(data []complex128) {
+	fftDirection(data, -1)
+}
+
+// ifft computes the inverse of fft in place, including the 1/N scale.
+func ifft(data []complex128) {
+	fftDirection(data, 1)
+	scale := complex(1/float64(len(data)), 0)
+	for i := range data {
+		data[i] *= scale
+	}
+}
+
+func fftDirection(data []complex128, sign float64) {
+	n := len(data)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Rect(1, sign*2*math.Pi/float64(size))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				a := data[start+k]
+				b := data[start+k+size/2] * w
+				data[start+k] = a + b
+				data[start+k+size/2] = a - b
+				w *= step
+			}
+		}
+	}
+}
+
+// nextPowerOfTwo returns the smallest power of two which is at least n.
+func nextPowerOfTwo(n int) int {
+	res := 1
+	for res < n {
+		res <<= 1
+	}
+	return res
+}
@@ -0,0 +1,111 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A FilterTrack passes an inner track through a biquad filter whose center frequency can glide
+// over time.
+//
+// For low-pass and high-pass filters, the center frequency is the cutoff.
+// The filter is kept stable by limiting the center frequency to just below the Nyquist frequency
+// of the sample rate being encoded.
+type FilterTrack struct {
+	inner   Track
+	kind    FilterType
+	q       float64
+	centers *envelope
+}
+
+// NewFilterTrack creates a FilterTrack which applies the given filter to inner.
+func NewFilterTrack(inner Track, kind FilterType, center, q float64) *FilterTrack {
+	centers := newEnvelope(center)
+	centers.Continue(inner.Duration())
+	return &FilterTrack{inner: inner, kind: kind, q: q, centers: centers}
+}
+
+// Kind returns the type of the filter.
+func (f *FilterTrack) Kind() FilterType {
+	return f.kind
+}
+
+// Q returns the quality factor of the filter.
+func (f *FilterTrack) Q() float64 {
+	return f.q
+}
+
+// Center returns the center frequency at the end of the track.
+func (f *FilterTrack) Center() float64 {
+	return f.centers.Value()
+}
+
+// Inner returns the track being filtered.
+func (f *FilterTrack) Inner() Track {
+	return f.inner
+}
+
+func (f *FilterTrack) Duration() time.Duration {
+	return f.inner.Duration()
+}
+
+func (f *FilterTrack) Encode(sampleRate int) []wav.Sample {
+	res := f.inner.Encode(sampleRate)
+	filter := &biquad{}
+	var lastCenter float64
+	for i, center := range f.centers.Values(sampleRate, len(res)) {
+		if i == 0 || center != lastCenter {
+			filter.configure(f.kind, center, f.q, sampleRate)
+			lastCenter = center
+		}
+		res[i] = wav.Sample(filter.process(float64(res[i])))
+	}
+	return res
+}
+
+func (f *FilterTrack) Continue(d time.Duration) {
+	f.syncCenters()
+	f.inner.Continue(d)
+	f.centers.Continue(d)
+}
+
+func (f *FilterTrack) Volume() float64 {
+	return f.inner.Volume()
+}
+
+func (f *FilterTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	f.syncCenters()
+	f.inner.AdjustVolume(newVolume, d)
+	f.centers.Continue(d)
+}
+
+func (f *FilterTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	f.syncCenters()
+	AdjustVolumeCurve(f.inner, newVolume, d, curve)
+	f.centers.Continue(d)
+}
+
+// AdjustFilter elongates the track while gliding the center frequency to a new value.
+func (f *FilterTrack) AdjustFilter(newCenter float64, transition time.Duration) {
+	f.syncCenters()
+	f.inner.Continue(transition)
+	f.centers.Adjust(newCenter, transition)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (f *FilterTrack) Clone() Track {
+	inner := cloneTrack(f.inner)
+	if inner == nil {
+		return nil
+	}
+	return &FilterTrack{inner: inner, kind: f.kind, q: f.q, centers: f.centers.clone()}
+}
+
+// syncCenters holds the center frequency over any time the inner track was elongated by
+// without going through the FilterTrack.
+func (f *FilterTrack) syncCenters() {
+	if d := f.inner.Duration() - f.centers.Duration(); d > 0 {
+		f.centers.Continue(d)
+	}
+}
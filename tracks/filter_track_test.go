@@ -0,0 +1,84 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestFilterTrackPassBand(t *testing.T) {
+	const sampleRate = 16000
+	for _, test := range []struct {
+		kind   FilterType
+		center float64
+		pass   [2]float64
+		stop   [2]float64
+	}{
+		{LowPassFilter, 1000, [2]float64{0, 500}, [2]float64{3000, 8000}},
+		{HighPassFilter, 3000, [2]float64{5000, 8000}, [2]float64{0, 1000}},
+		{BandPassFilter, 2000, [2]float64{1800, 2200}, [2]float64{4000, 8000}},
+		{BandPassFilter, 2000, [2]float64{1800, 2200}, [2]float64{0, 800}},
+	} {
+		noise := NewNoiseTrack(WhiteNoise, 0.3, 1)
+		noise.Continue(time.Second * 2)
+		filtered := NewFilterTrack(noise, test.kind, test.center, 3)
+		spectrum := averageSpectrum(filtered.Encode(sampleRate), sampleRate)
+		pass := bandPower(spectrum, sampleRate, test.pass[0], test.pass[1])
+		stop := bandPower(spectrum, sampleRate, test.stop[0], test.stop[1])
+		if pass < stop*30 {
+			t.Errorf("filter %d at %f Hz: pass band power %f is not far above stop band power %f",
+				test.kind, test.center, pass, stop)
+		}
+	}
+}
+
+func TestFilterTrackGlide(t *testing.T) {
+	const sampleRate = 16000
+	noise := NewNoiseTrack(WhiteNoise, 0.3, 1)
+	noise.Continue(time.Second)
+	filtered := NewFilterTrack(noise, BandPassFilter, 1000, 5)
+	filtered.AdjustFilter(4000, time.Millisecond*500)
+	filtered.Continue(time.Second)
+	if d := filtered.Duration(); d != time.Millisecond*2500 {
+		t.Fatalf("expected duration 2.5s but got %v", d)
+	}
+	if c := filtered.Center(); c != 4000 {
+		t.Errorf("expected center 4000 but got %f", c)
+	}
+	samples := filtered.Encode(sampleRate)
+	head := averageSpectrum(samples[:sampleRate], sampleRate)
+	tail := averageSpectrum(samples[len(samples)-sampleRate:], sampleRate)
+	if bandPower(head, sampleRate, 800, 1200) < bandPower(head, sampleRate, 3800, 4200)*10 {
+		t.Error("expected the start of the track to be centered at 1000 Hz")
+	}
+	if bandPower(tail, sampleRate, 3800, 4200) < bandPower(tail, sampleRate, 800, 1200)*10 {
+		t.Error("expected the end of the track to be centered at 4000 Hz")
+	}
+}
+
+// averageSpectrum averages the power spectra of the frames of a signal.
+func averageSpectrum(samples []wav.Sample, sampleRate int) []float64 {
+	frames := Spectrogram(samples, sampleRate, 512, 256)
+	res := make([]float64, len(frames[0]))
+	for _, frame := range frames {
+		for k, mag := range frame {
+			res[k] += mag * mag / float64(len(frames))
+		}
+	}
+	return res
+}
+
+// bandPower computes the mean power of the bins of a spectrum within a frequency range.
+func bandPower(spectrum []float64, sampleRate int, minFreq, maxFreq float64) float64 {
+	var sum float64
+	var count int
+	for k, power := range spectrum {
+		freq := SpectrogramBinFrequency(k, len(spectrum), sampleRate)
+		if freq >= minFreq && freq <= maxFreq {
+			sum += power
+			count++
+		}
+	}
+	return sum / float64(count)
+}
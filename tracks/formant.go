@@ -0,0 +1,41 @@
+package tracks
+
+import "time"
+
+// A FormantTarget describes a formant which a ToneTrack glides to.
+type FormantTarget struct {
+	// Frequency is the center frequency of the formant, in Hz.
+	Frequency float64
+
+	// Bandwidth is the random spread of the tone, in Hz, which widens the formant.
+	Bandwidth float64
+
+	// Amplitude is the volume of the tone.
+	Amplitude float64
+}
+
+// FormantTarget returns the tone's current characteristics as a FormantTarget.
+func (s *ToneTrack) FormantTarget() FormantTarget {
+	return FormantTarget{Frequency: s.Frequency(), Bandwidth: s.Spread(), Amplitude: s.Volume()}
+}
+
+// AdjustFormant elongates the track while gliding the tone to a formant target.
+//
+// Like AdjustVolume, the glide starts from the tone's characteristics at the end of the track,
+// so a new target takes over from wherever the previous glide left off.
+func (s *ToneTrack) AdjustFormant(target FormantTarget, d time.Duration) {
+	s.AdjustAll(target.Frequency, target.Amplitude, target.Bandwidth, d)
+}
+
+// AdjustFormants elongates a group of formant tracks together while gliding each one to the
+// target at the same index.
+// Tracks without a target keep their characteristics, and extra targets are ignored.
+func AdjustFormants(formants []*ToneTrack, targets []FormantTarget, d time.Duration) {
+	for i, formant := range formants {
+		if i < len(targets) {
+			formant.AdjustFormant(targets[i], d)
+		} else {
+			formant.AdjustFormant(formant.FormantTarget(), d)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdjustFormants(t *testing.T) {
+	f1 := NewToneTrack(700, 0.3, 0)
+	f2 := NewToneTrack(1100, 0.2, 0)
+	f3 := NewToneTrack(2500, 0.1, 0)
+	formants := []*ToneTrack{f1, f2, f3}
+	targets := []FormantTarget{
+		{Frequency: 300, Bandwidth: 20, Amplitude: 0.4},
+		{Frequency: 2200, Bandwidth: 40, Amplitude: 0.25},
+	}
+	AdjustFormants(formants, append(targets, FormantTarget{Frequency: 1}, FormantTarget{}),
+		time.Millisecond*50)
+	for i, formant := range formants {
+		if formant.Duration() != time.Millisecond*50 {
+			t.Errorf("F%d: expected the glide to elongate the track to 50ms, but got %s", i+1,
+				formant.Duration())
+		}
+	}
+	for i, target := range targets {
+		if actual := formants[i].FormantTarget(); actual != target {
+			t.Errorf("F%d: expected %v but got %v", i+1, target, actual)
+		}
+	}
+	if actual := f3.FormantTarget(); actual.Frequency != 1 {
+		t.Errorf("expected F3 to glide to the third target but got %v", actual)
+	}
+
+	// A new glide starts where the previous one ended, and tracks without targets hold still.
+	AdjustFormants(formants, targets[:1], time.Millisecond*20)
+	if f1.FormantTarget() != targets[0] || f2.FormantTarget() != targets[1] {
+		t.Errorf("expected the formants to hold their targets, but got %v and %v",
+			f1.FormantTarget(), f2.FormantTarget())
+	}
+	for i, formant := range formants {
+		if formant.Duration() != time.Millisecond*70 {
+			t.Errorf("F%d: expected a duration of 70ms but got %s", i+1, formant.Duration())
+		}
+	}
+}
+
+func TestAdjustFormantGlide(t *testing.T) {
+	const sampleRate = 16000
+	tone := NewToneTrack(1000, 0.4, 0)
+	tone.Continue(time.Millisecond * 100)
+	tone.AdjustFormant(FormantTarget{Frequency: 2000, Amplitude: 0.4}, time.Millisecond*200)
+	tone.Continue(time.Millisecond * 100)
+	tone.AdjustFormant(FormantTarget{Frequency: 500, Amplitude: 0.4}, time.Millisecond*200)
+	tone.Continue(time.Millisecond * 100)
+
+	spectrogram := TrackSpectrogram(tone, sampleRate, 512, 160)
+	peak := func(ms int) float64 {
+		frame := spectrogram[ms/10]
+		var best int
+		for k, m := range frame {
+			if m > frame[best] {
+				best = k
+			}
+		}
+		return SpectrogramBinFrequency(best, len(frame), sampleRate)
+	}
+	last := peak(100)
+	for ms := 110; ms <= 270; ms += 10 {
+		if f := peak(ms); f < last {
+			t.Fatalf("%dms: expected the tone to rise, but it fell from %f to %f Hz", ms, last, f)
+		} else {
+			last = f
+		}
+	}
+	if last = peak(330); last < 1950 {
+		t.Errorf("expected the tone to reach 2000 Hz but got %f", last)
+	}
+	for ms := 410; ms <= 570; ms += 10 {
+		if f := peak(ms); f > last {
+			t.Fatalf("%dms: expected the tone to fall, but it rose from %f to %f Hz", ms, last, f)
+		} else {
+			last = f
+		}
+	}
+	if f := peak(620); f > 530 {
+		t.Errorf("expected the tone to reach 500 Hz but got %f", f)
+	}
+}
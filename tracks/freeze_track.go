@@ -0,0 +1,236 @@
+package tracks
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// freezeFrameDuration is the approximate length of the frame that a FreezeTrack captures.
+// The actual frame is rounded up to a power of two samples.
+const freezeFrameDuration = time.Millisecond * 50
+
+// freezeMeterRate is the sample rate at which a FreezeTrack measures its frozen frame for Volume,
+// which has no sample rate of its own.
+const freezeMeterRate = 16000
+
+// A FreezeTrack captures the spectrum of an inner track at a freeze point and sustains it,
+// turning a transient into a steady pad.
+//
+// Before the freeze point, a FreezeTrack sounds like its inner track.
+// After the freeze point, it resynthesizes the captured spectrum with randomized phases for as
+// long as the track is continued, so the sustained sound does not buzz.
+type FreezeTrack struct {
+	inner      Track
+	freezeTime time.Duration
+	gain       *envelope
+	seed       int64
+
+	// meter caches the level of the frozen frame, which clones share since their inner tracks
+	// sound the same.
+	meter *freezeMeter
+}
+
+type freezeMeter struct {
+	once  sync.Once
+	level float64
+}
+
+// NewFreezeTrack creates a FreezeTrack which freezes inner at freezeTime and sustains the frozen
+// spectrum for the duration d.
+// The inner track should not be modified after it is frozen.
+func NewFreezeTrack(inner Track, freezeTime, d time.Duration) *FreezeTrack {
+	gain := newEnvelope(1)
+	gain.Continue(d)
+	return &FreezeTrack{inner: inner, freezeTime: freezeTime, gain: gain, meter: &freezeMeter{}}
+}
+
+// Inner returns the track being frozen.
+func (f *FreezeTrack) Inner() Track {
+	return f.inner
+}
+
+// Duration returns the freeze time plus the duration of the frozen sound.
+func (f *FreezeTrack) Duration() time.Duration {
+	return f.freezeTime + f.gain.Duration()
+}
+
+func (f *FreezeTrack) Encode(sampleRate int) []wav.Sample {
+	innerSamples := f.inner.Encode(sampleRate)
+	res := make([]wav.Sample, sampleCount(f.Duration(), sampleRate))
+	freezeIndex := sampleCount(f.freezeTime, sampleRate)
+
+	frameSize := freezeFrameSize(sampleRate)
+	hop := frameSize / 2
+	magnitudes := f.capture(innerSamples, freezeIndex, frameSize)
+	random := rand.New(rand.NewSource(f.seed))
+
+	// The inner track fades out with the falling half of a sine window while the first
+	// resynthesized frame fades in, giving an equal-power crossfade that ends at freezeIndex.
+	fadeStart := freezeIndex - hop
+	for i := 0; i < freezeIndex && i < len(innerSamples) && i < len(res); i++ {
+		res[i] = innerSamples[i]
+		if i >= fadeStart {
+			res[i] *= wav.Sample(sineWindow(i-fadeStart+hop, frameSize))
+		}
+	}
+
+	gains := f.gain.Values(sampleRate, len(res)-freezeIndex+1)
+	frame := make([]complex128, frameSize)
+	for frameStart := fadeStart; frameStart < len(res); frameStart += hop {
+		randomPhaseFrame(frame, magnitudes, random)
+		for j, value := range frame {
+			idx := frameStart + j
+			if idx < 0 || idx >= len(res) {
+				continue
+			}
+			g := gains[0]
+			if idx > freezeIndex {
+				g = gains[idx-freezeIndex]
+			}
+			res[idx] += wav.Sample(real(value) * sineWindow(j, frameSize) * g)
+		}
+	}
+
+	return res
+}
+
+// Continue elongates the frozen sound.
+func (f *FreezeTrack) Continue(d time.Duration) {
+	f.gain.Continue(d)
+}
+
+// Volume returns the level of the frozen sound, as the amplitude of a sine with the same RMS
+// level, scaled by the gain currently applied to it.
+func (f *FreezeTrack) Volume() float64 {
+	return f.frozenLevel() * f.gain.Value()
+}
+
+// Gain returns the gain currently applied to the frozen sound.
+func (f *FreezeTrack) Gain() float64 {
+	return f.gain.Value()
+}
+
+// AdjustVolume elongates the frozen sound while adjusting its gain so that Volume reaches
+// newVolume.
+// A silent frozen sound is simply elongated.
+func (f *FreezeTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	f.gain.Adjust(f.targetGain(newVolume), d)
+}
+
+// AdjustVolumeCurve is like AdjustVolume, but the gain follows the given curve.
+func (f *FreezeTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	f.gain.AdjustCurve(f.targetGain(newVolume), d, curve)
+}
+
+// AdjustGain elongates the frozen sound while adjusting the gain applied to it.
+func (f *FreezeTrack) AdjustGain(gain float64, d time.Duration) {
+	f.gain.Adjust(gain, d)
+}
+
+func (f *FreezeTrack) targetGain(newVolume float64) float64 {
+	if level := f.frozenLevel(); level > 0 {
+		return newVolume / level
+	}
+	return f.gain.Value()
+}
+
+// frozenLevel measures the frame which is frozen, encoding the inner track the first time.
+// The resynthesized frames have the same power as the Hann-windowed frame they are made from.
+func (f *FreezeTrack) frozenLevel() float64 {
+	f.meter.once.Do(func() {
+		samples := f.inner.Encode(freezeMeterRate)
+		frameSize := freezeFrameSize(freezeMeterRate)
+		start := sampleCount(f.freezeTime, freezeMeterRate) - frameSize/2
+		var power, windowPower float64
+		for i := 0; i < frameSize; i++ {
+			hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frameSize))
+			windowPower += hann * hann
+			if idx := start + i; idx >= 0 && idx < len(samples) {
+				power += math.Pow(float64(samples[idx])*hann, 2)
+			}
+		}
+		f.meter.level = math.Sqrt(power/windowPower) * math.Sqrt2
+	})
+	return f.meter.level
+}
+
+// Seed returns the seed from which the random phases of the frozen sound are generated.
+func (f *FreezeTrack) Seed() int64 {
+	return f.seed
+}
+
+// SetSeed sets the seed from which the random phases of the frozen sound are generated.
+func (f *FreezeTrack) SetSeed(seed int64) {
+	f.seed = seed
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (f *FreezeTrack) Clone() Track {
+	inner := cloneTrack(f.inner)
+	if inner == nil {
+		return nil
+	}
+	return &FreezeTrack{inner: inner, freezeTime: f.freezeTime, gain: f.gain.clone(),
+		seed: f.seed, meter: f.meter}
+}
+
+// freezeFrameSize returns the number of samples in the frame which is frozen.
+func freezeFrameSize(sampleRate int) int {
+	return nextPowerOfTwo(int(freezeFrameDuration.Seconds() * float64(sampleRate)))
+}
+
+// capture computes the magnitude spectrum of a Hann-windowed frame centered at freezeIndex.
+// The magnitudes are scaled so that resynthesis restores the power lost to the window.
+func (f *FreezeTrack) capture(samples []wav.Sample, freezeIndex, frameSize int) []float64 {
+	frame := make([]complex128, frameSize)
+	start := freezeIndex - frameSize/2
+	for i := range frame {
+		idx := start + i
+		if idx < 0 || idx >= len(samples) {
+			continue
+		}
+		hann := 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(frameSize))
+		frame[i] = complex(float64(samples[idx])*hann, 0)
+	}
+	fft(frame)
+
+	// The mean squared value of a Hann window is 3/8.
+	scale := math.Sqrt(8.0 / 3.0)
+	res := make([]float64, frameSize/2+1)
+	for i := range res {
+		res[i] = cmplx.Abs(frame[i]) * scale
+	}
+	return res
+}
+
+// randomPhaseFrame fills frame with a real signal which has the given magnitude spectrum and
+// uniformly random phases.
+func randomPhaseFrame(frame []complex128, magnitudes []float64, random *rand.Rand) {
+	n := len(frame)
+	for i := range frame {
+		frame[i] = 0
+	}
+	for k, mag := range magnitudes {
+		if k == 0 || k == n/2 {
+			if random.Intn(2) == 0 {
+				mag = -mag
+			}
+			frame[k] = complex(mag, 0)
+		} else {
+			value := cmplx.Rect(mag, random.Float64()*2*math.Pi)
+			frame[k] = value
+			frame[n-k] = cmplx.Conj(value)
+		}
+	}
+	ifft(frame)
+}
+
+// sineWindow evaluates a sine window, whose squares overlap-add to one at 50% overlap.
+func sineWindow(i, size int) float64 {
+	return math.Sin(math.Pi * (float64(i) + 0.5) / float64(size))
+}
@@ -0,0 +1,130 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestFreezeTrackSustainsSpectrum(t *testing.T) {
+	const sampleRate = 16000
+	low := NewToneTrack(500, 0.3, 0)
+	low.Continue(time.Millisecond * 300)
+	high := NewToneTrack(1500, 0.15, 0)
+	high.Continue(time.Millisecond * 300)
+	freeze := NewFreezeTrack(TrackSet{"low": low, "high": high}, time.Millisecond*200,
+		time.Second*2)
+	if d := freeze.Duration(); d != time.Millisecond*2200 {
+		t.Fatalf("expected duration 2.2s but got %v", d)
+	}
+
+	// The frozen sound starts once the crossfade out of the inner track has finished.
+	samples := freeze.Encode(sampleRate)
+	frozen := samples[sampleRate/4:]
+	frames := Spectrogram(frozen, sampleRate, 1024, 512)
+	frames = frames[:len(frames)-1]
+	bandLevel := func(frame []float64, freq float64) float64 {
+		var sum float64
+		for k, mag := range frame {
+			if math.Abs(SpectrogramBinFrequency(k, len(frame), sampleRate)-freq) < 100 {
+				sum += mag * mag
+			}
+		}
+		return sum
+	}
+
+	// Random phases make single frames fluctuate, so the levels are compared on average over
+	// each half of the frozen sound.
+	var halves [2][2]float64
+	frames = frames[:len(frames)/2*2]
+	for i, frame := range frames {
+		lowLevel, highLevel := bandLevel(frame, 500), bandLevel(frame, 1500)
+		if (lowLevel+highLevel)/squareNorm(frame) < 0.99 {
+			t.Errorf("frame %d: expected the energy to stay in the frozen partials", i)
+		}
+		half := i * 2 / len(frames)
+		halves[half][0] += lowLevel / float64(len(frames)/2)
+		halves[half][1] += highLevel / float64(len(frames)/2)
+	}
+	original := Spectrogram(samples[:1024], sampleRate, 1024, 512)[0]
+	expectedLow := math.Sqrt(bandLevel(original, 500))
+	expectedHigh := math.Sqrt(bandLevel(original, 1500))
+	for i, half := range halves {
+		lowMag, highMag := math.Sqrt(half[0]), math.Sqrt(half[1])
+		if math.Abs(lowMag/expectedLow-1) > 0.25 || math.Abs(highMag/expectedHigh-1) > 0.25 {
+			t.Errorf("half %d: expected magnitudes near %f and %f but got %f and %f", i,
+				expectedLow, expectedHigh, lowMag, highMag)
+		}
+	}
+
+	// The resynthesized frames have random phases, so the output never repeats a frame.
+	start := frozen[:1024]
+	for lag := 512; lag+1024 <= len(frozen); lag += 512 {
+		if c := correlation(start, frozen[lag:lag+1024]); c > 0.9 {
+			t.Errorf("lag %d: expected the frozen sound not to repeat, but got correlation %f",
+				lag, c)
+		}
+	}
+}
+
+func TestFreezeTrackVolume(t *testing.T) {
+	const sampleRate = 16000
+	tone := NewToneTrack(500, 0.3, 0)
+	tone.Continue(time.Millisecond * 300)
+	freeze := NewFreezeTrack(tone, time.Millisecond*200, time.Second)
+	if v := freeze.Volume(); math.Abs(v-0.3) > 0.01 {
+		t.Errorf("expected the frozen tone to have a volume of 0.3 but got %f", v)
+	}
+
+	// The volume matches the level of the resynthesized sound, at any gain.
+	freeze.AdjustVolume(0.15, 0)
+	freeze.Continue(time.Second)
+	if g := freeze.Gain(); math.Abs(g-0.5) > 0.02 {
+		t.Errorf("expected a gain of 0.5 but got %f", g)
+	}
+	samples := freeze.Encode(sampleRate)
+	for _, part := range []struct {
+		start, end time.Duration
+		expected   float64
+	}{
+		{time.Millisecond * 250, time.Millisecond * 1150, 0.3},
+		{time.Millisecond * 1250, time.Millisecond * 2150, 0.15},
+	} {
+		steady := samples[SampleCount(part.start, sampleRate):SampleCount(part.end, sampleRate)]
+		level := RMSLevel(steady) * math.Sqrt2
+		if math.Abs(level-part.expected) > part.expected*0.1 {
+			t.Errorf("expected the frozen sound to measure %f but got %f", part.expected, level)
+		}
+	}
+	if v := freeze.Clone().Volume(); math.Abs(v-0.15) > 0.005 {
+		t.Errorf("expected the clone to have a volume of 0.15 but got %f", v)
+	}
+
+	silent := NewFreezeTrack(NewSilenceTrack(time.Millisecond*300), time.Millisecond*200,
+		time.Second)
+	silent.AdjustVolume(0.5, time.Millisecond*100)
+	if v, g := silent.Volume(), silent.Gain(); v != 0 || g != 1 {
+		t.Errorf("expected a silent freeze to keep its gain, but got volume %f and gain %f", v, g)
+	}
+}
+
+func squareNorm(frame []float64) float64 {
+	var sum float64
+	for _, mag := range frame {
+		sum += mag * mag
+	}
+	return sum
+}
+
+// correlation computes the normalized correlation of two equal-length signals.
+func correlation(a, b []wav.Sample) float64 {
+	var ab, aa, bb float64
+	for i := range a {
+		ab += float64(a[i]) * float64(b[i])
+		aa += float64(a[i]) * float64(a[i])
+		bb += float64(b[i]) * float64(b[i])
+	}
+	return ab / math.Sqrt(aa*bb)
+}
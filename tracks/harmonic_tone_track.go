@@ -0,0 +1,138 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A HarmonicToneTrack generates a periodic tone made up of a fundamental and its harmonics.
+//
+// The harmonics are synthesized from a single accumulated phase, so gliding the fundamental keeps
+// every harmonic phase-continuous.
+// Harmonics at or above the Nyquist frequency of the sample rate being encoded are left out, so
+// the tone does not alias.
+type HarmonicToneTrack struct {
+	amplitudes []float64
+	frequency  *envelope
+	volume     *envelope
+}
+
+// NewHarmonicToneTrack creates a zero-length HarmonicToneTrack.
+//
+// The amplitudes give the relative strength of each harmonic, starting with the fundamental.
+// They are normalized so that the tone's peak never exceeds its volume.
+func NewHarmonicToneTrack(freq, volume float64, amplitudes []float64) *HarmonicToneTrack {
+	var sum float64
+	for _, amp := range amplitudes {
+		sum += math.Abs(amp)
+	}
+	normalized := make([]float64, len(amplitudes))
+	if sum > 0 {
+		for i, amp := range amplitudes {
+			normalized[i] = amp / sum
+		}
+	}
+	return &HarmonicToneTrack{
+		amplitudes: normalized,
+		frequency:  newEnvelope(freq),
+		volume:     newEnvelope(volume),
+	}
+}
+
+// HarmonicRolloff generates amplitudes for count harmonics which fall off by a constant number of
+// decibels per octave.
+// For example, a rolloff of -6 approximates a sawtooth wave.
+func HarmonicRolloff(dbPerOctave float64, count int) []float64 {
+	res := make([]float64, count)
+	for i := range res {
+		octaves := math.Log2(float64(i + 1))
+		res[i] = math.Pow(10, dbPerOctave*octaves/20)
+	}
+	return res
+}
+
+// Harmonics returns a copy of the normalized harmonic amplitudes.
+func (h *HarmonicToneTrack) Harmonics() []float64 {
+	return append([]float64{}, h.amplitudes...)
+}
+
+func (h *HarmonicToneTrack) Duration() time.Duration {
+	return h.volume.Duration()
+}
+
+func (h *HarmonicToneTrack) Encode(sampleRate int) []wav.Sample {
+	res := make([]wav.Sample, sampleCount(h.Duration(), sampleRate))
+	frequencies := h.frequency.Values(sampleRate, len(res))
+	volumes := h.volume.Values(sampleRate, len(res))
+	nyquist := float64(sampleRate) / 2
+
+	var phase float64
+	for i := range res {
+		freq := frequencies[i]
+		count := len(h.amplitudes)
+		if freq > 0 {
+			if limit := int(math.Ceil(nyquist/freq)) - 1; limit < count {
+				count = limit
+			}
+		}
+		sin1, cos1 := math.Sincos(2 * math.Pi * phase)
+		res[i] = wav.Sample(sineSeries(h.amplitudes, count, sin1, cos1) * volumes[i])
+		phase = wrapPhase(phase + freq/float64(sampleRate))
+	}
+	return res
+}
+
+func (h *HarmonicToneTrack) Continue(d time.Duration) {
+	h.frequency.Continue(d)
+	h.volume.Continue(d)
+}
+
+// Volume returns the tone's current peak amplitude.
+func (h *HarmonicToneTrack) Volume() float64 {
+	return h.volume.Value()
+}
+
+// RMSRatio returns the RMS level of the current sound at a volume of 1, which counts only the
+// harmonics below the Nyquist frequency.
+func (h *HarmonicToneTrack) RMSRatio(sampleRate int) float64 {
+	var power float64
+	for i, amp := range h.amplitudes {
+		if float64(i+1)*h.frequency.Value() >= float64(sampleRate)/2 {
+			break
+		}
+		power += amp * amp
+	}
+	return math.Sqrt(power / 2)
+}
+
+// AdjustVolume elongates the track while adjusting the tone's peak amplitude.
+func (h *HarmonicToneTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	h.volume.Adjust(newVolume, d)
+	h.frequency.Continue(d)
+}
+
+func (h *HarmonicToneTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	h.volume.AdjustCurve(newVolume, d, curve)
+	h.frequency.Continue(d)
+}
+
+// Frequency returns the tone's current fundamental frequency.
+func (h *HarmonicToneTrack) Frequency() float64 {
+	return h.frequency.Value()
+}
+
+// AdjustFrequency elongates the track while gliding the fundamental frequency.
+func (h *HarmonicToneTrack) AdjustFrequency(newFrequency float64, d time.Duration) {
+	h.frequency.Adjust(newFrequency, d)
+	h.volume.Continue(d)
+}
+
+func (h *HarmonicToneTrack) Clone() Track {
+	return &HarmonicToneTrack{
+		amplitudes: h.amplitudes,
+		frequency:  h.frequency.clone(),
+		volume:     h.volume.clone(),
+	}
+}
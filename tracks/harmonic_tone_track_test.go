@@ -0,0 +1,57 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHarmonicToneTrackSpectrum(t *testing.T) {
+	amplitudes := make([]float64, 10)
+	for i := range amplitudes {
+		amplitudes[i] = 1
+	}
+	tone := NewHarmonicToneTrack(1000, 0.5, amplitudes)
+	tone.Continue(time.Second)
+
+	// Bins are 7.8125 Hz apart in a 1024-sample frame at 8000 Hz or 15.625 Hz apart at 16000 Hz,
+	// so every harmonic falls on a bin.
+	for _, sampleRate := range []int{8000, 16000} {
+		frame := TrackSpectrogram(tone, sampleRate, 1024, 1024)[2]
+		for k, mag := range frame {
+			freq := SpectrogramBinFrequency(k, len(frame), sampleRate)
+			harmonic := math.Round(freq / 1000)
+			expected := 0.0
+			if freq == harmonic*1000 && harmonic > 0 && freq < float64(sampleRate)/2 {
+				expected = 0.05
+			}
+			if math.Abs(freq-harmonic*1000) > 24 {
+				// Bins away from the harmonics only get window leakage.
+				if mag > 1e-3 {
+					t.Errorf("%d Hz: unexpected magnitude %f at %f Hz", sampleRate, mag, freq)
+				}
+			} else if freq == harmonic*1000 && math.Abs(mag-expected) > 1e-3 {
+				t.Errorf("%d Hz: expected magnitude %f at %f Hz but got %f", sampleRate,
+					expected, freq, mag)
+			}
+		}
+	}
+}
+
+func TestHarmonicRolloff(t *testing.T) {
+	amps := HarmonicRolloff(-6, 8)
+	for octaves, idx := range []int{0, 1, 3, 7} {
+		expected := math.Pow(10, -6*float64(octaves)/20)
+		if math.Abs(amps[idx]-expected) > 1e-12 {
+			t.Errorf("harmonic %d: expected %f but got %f", idx+1, expected, amps[idx])
+		}
+	}
+	tone := NewHarmonicToneTrack(100, 1, amps)
+	var sum float64
+	for _, amp := range tone.Harmonics() {
+		sum += amp
+	}
+	if math.Abs(sum-1) > 1e-12 {
+		t.Errorf("expected the harmonics to be normalized, but they sum to %f", sum)
+	}
+}
@@ -1,6 +1,8 @@
 package tracks
 
 import (
+	"runtime"
+	"sort"
 	"time"
 
 	"github.com/unixpickle/wav"
@@ -16,6 +18,10 @@ import (
 // Different concrete implementations of Track will produce different
 // classes of sounds.
 type Track interface {
+	// Duration returns the length of the track.
+	// Durations add up exactly, and a track encodes to the number of samples that its total
+	// duration covers, so tracks continued by identical durations encode to identical lengths
+	// at any sample rate.
 	Duration() time.Duration
 	Encode(sampleRate int) []wav.Sample
 
@@ -30,6 +36,57 @@ type Track interface {
 	AdjustVolume(newVolume float64, transitionTime time.Duration)
 }
 
+// A StereoTrack is a Track which can also be encoded in stereo.
+type StereoTrack interface {
+	Track
+
+	// EncodeStereo generates interleaved left and right samples.
+	EncodeStereo(sampleRate int) []wav.Sample
+}
+
+// A PitchedTrack is a Track whose current sound has a frequency.
+type PitchedTrack interface {
+	Track
+
+	// Frequency returns the frequency of the current sound.
+	Frequency() float64
+
+	// AdjustFrequency elongates the track while gliding the
+	// frequency of the current sound.
+	// The waveform stays continuous throughout the glide.
+	AdjustFrequency(newFrequency float64, transitionTime time.Duration)
+}
+
+// A GlidingTrack is a PitchedTrack whose frequency can glide over the last part of the track
+// without elongating it.
+type GlidingTrack interface {
+	PitchedTrack
+
+	// GlideFrequency glides the frequency linearly to a new frequency over the last part of the
+	// track, which lasts for the given duration.
+	GlideFrequency(newFrequency float64, duration time.Duration)
+}
+
+// GlideAll glides the frequency of every GlidingTrack in a track over its last part, recursing
+// into nested TrackSets, SyncTrackSets and WeightedTrackSets.
+// Other tracks are left alone, and no track is elongated.
+func GlideAll(t Track, freq float64, d time.Duration) {
+	switch t := t.(type) {
+	case TrackSet:
+		for _, track := range t {
+			GlideAll(track, freq, d)
+		}
+	case *SyncTrackSet:
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		GlideAll(t.set, freq, d)
+	case *WeightedTrackSet:
+		GlideAll(t.Tracks(), freq, d)
+	case GlidingTrack:
+		t.GlideFrequency(freq, d)
+	}
+}
+
 // A TrackID is a string used to identify tracks in a TrackSet.
 type TrackID string
 
@@ -52,6 +109,50 @@ OuterLoop:
 	return res
 }
 
+// IncludeTracks returns a TrackSet that only contains tracks
+// with the given track IDs.
+func (t TrackSet) IncludeTracks(ids ...TrackID) TrackSet {
+	res := TrackSet{}
+	for _, id := range ids {
+		if val, ok := t[id]; ok {
+			res[id] = val
+		}
+	}
+	return res
+}
+
+// Filter returns a TrackSet that only contains the tracks
+// for which pred returns true.
+// Nested TrackSets are treated like any other track.
+func (t TrackSet) Filter(pred func(id TrackID, track Track) bool) TrackSet {
+	res := TrackSet{}
+	for id, val := range t {
+		if pred(id, val) {
+			res[id] = val
+		}
+	}
+	return res
+}
+
+// FilterDeep is like Filter, but it filters the members of
+// nested TrackSets instead of the nested sets themselves.
+//
+// The nesting structure is rebuilt with new TrackSets, and
+// nested sets with no remaining members are left out.
+func (t TrackSet) FilterDeep(pred func(id TrackID, track Track) bool) TrackSet {
+	res := TrackSet{}
+	for id, val := range t {
+		if ts, ok := val.(TrackSet); ok {
+			if filtered := ts.FilterDeep(pred); len(filtered) > 0 {
+				res[id] = filtered
+			}
+		} else if pred(id, val) {
+			res[id] = val
+		}
+	}
+	return res
+}
+
 // Duration returns the duration of the longest track in the set.
 func (t TrackSet) Duration() (maxDur time.Duration) {
 	for _, track := range t {
@@ -64,30 +165,64 @@ func (t TrackSet) Duration() (maxDur time.Duration) {
 
 // Encode generates samples by encoding every track in the set and
 // summing up the signals.
-func (t TrackSet) Encode(sampleRate int) (res []wav.Sample) {
-	sampleCount := 0
-	encodedTracks := make([][]wav.Sample, 0, len(t))
-	for _, track := range t {
-		encodedTrack := track.Encode(sampleRate)
-		encodedTracks = append(encodedTracks, encodedTrack)
-		if len(encodedTrack) > sampleCount {
-			sampleCount = len(encodedTrack)
-		}
-	}
+//
+// Member tracks are encoded concurrently, using up to GOMAXPROCS
+// goroutines for the entire tree of nested TrackSets.
+// Sets nested inside wrappers, such as WeightedTrackSets, share those
+// goroutines rather than starting more of their own.
+func (t TrackSet) Encode(sampleRate int) []wav.Sample {
+	return t.EncodeWorkers(sampleRate, runtime.GOMAXPROCS(0))
+}
 
-	res = make([]wav.Sample, sampleCount)
-	for i := range res {
-		for _, enc := range encodedTracks {
-			if i >= len(enc) {
-				continue
-			}
-			res[i] += enc[i]
+// EncodeWorkers is like Encode, but it uses at most the given number
+// of goroutines to encode and mix the tracks.
+//
+// The output does not depend on the number of workers.
+func (t TrackSet) EncodeWorkers(sampleRate, workers int) []wav.Sample {
+	_, encodedTracks := t.encodeMembers(sampleRate, workers)
+	return mixSamplesWorkers(encodedTracks, workers)
+}
+
+// EncodeWithStems is like Encode, but it also returns the encoded
+// signal of each track, padded with silence to the length of the mix.
+//
+// Nested TrackSets are returned as a single stem.
+func (t TrackSet) EncodeWithStems(sampleRate int) (mix []wav.Sample,
+	stems map[TrackID][]wav.Sample) {
+	workers := runtime.GOMAXPROCS(0)
+	ids, encodedTracks := t.encodeMembers(sampleRate, workers)
+	mix = mixSamplesWorkers(encodedTracks, workers)
+	stems = make(map[TrackID][]wav.Sample, len(ids))
+	used := map[*wav.Sample]bool{}
+	for i, id := range ids {
+		stem := encodedTracks[i]
+		// A track which is in the set under several IDs is only encoded once, so each of its
+		// stems gets a copy of its own.
+		if len(stem) < len(mix) || (len(stem) > 0 && used[&stem[0]]) {
+			padded := make([]wav.Sample, len(mix))
+			copy(padded, stem)
+			stem = padded
+		}
+		if len(stem) > 0 {
+			used[&stem[0]] = true
 		}
+		stems[id] = stem
 	}
-
 	return
 }
 
+// sortedIDs returns the IDs of the tracks in the set in ascending order.
+func (t TrackSet) sortedIDs() []TrackID {
+	ids := make([]TrackID, 0, len(t))
+	for id := range t {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
 // Continue elongates all of the set's tracks by a given duration.
 func (t TrackSet) Continue(duration time.Duration) {
 	for _, track := range t {
@@ -99,12 +234,17 @@ func (t TrackSet) Continue(duration time.Duration) {
 // in line with the longest track in the set.
 //
 // This is recursive with other TrackSets.
-// TrackSets that belong to this TrackSet will be evened out as well.
+// TrackSets, SyncTrackSets, and WeightedTrackSets that belong to this TrackSet will be evened
+// out as well.
 func (t TrackSet) EvenOut() {
 	dur := t.Duration()
 	for _, track := range t {
 		if ts, ok := track.(TrackSet); ok {
 			ts.EvenOut()
+		} else if ss, ok := track.(*SyncTrackSet); ok {
+			ss.EvenOut()
+		} else if ws, ok := track.(*WeightedTrackSet); ok {
+			ws.EvenOut()
 		}
 		if track.Duration() < dur {
 			track.Continue(dur - track.Duration())
@@ -128,3 +268,35 @@ func (t TrackSet) AdjustVolume(newVolume float64, duration time.Duration) {
 		track.AdjustVolume(vol, duration)
 	}
 }
+
+// AdjustVolumeCurve is like AdjustVolume, but every track's volume follows the given curve.
+// Tracks which are not CurvedTracks transition linearly.
+func (t TrackSet) AdjustVolumeCurve(newVolume float64, duration time.Duration, curve Curve) {
+	vol := newVolume / float64(len(t))
+	for _, track := range t {
+		AdjustVolumeCurve(track, vol, duration, curve)
+	}
+}
+
+// AdjustVolumeProportional is like AdjustVolume, but it preserves the balance of the set.
+// Every track's volume is scaled by the same factor, so that each track keeps its fraction of
+// the total while the sum of the volumes transitions to newVolume.
+//
+// This is recursive with other TrackSets.
+// If the set is currently silent, this falls back on AdjustVolume.
+func (t TrackSet) AdjustVolumeProportional(newVolume float64, duration time.Duration) {
+	currentVolume := t.Volume()
+	if currentVolume == 0 {
+		t.AdjustVolume(newVolume, duration)
+		return
+	}
+	scale := newVolume / currentVolume
+	for _, track := range t {
+		target := track.Volume() * scale
+		if ts, ok := track.(TrackSet); ok {
+			ts.AdjustVolumeProportional(target, duration)
+		} else {
+			track.AdjustVolume(target, duration)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package tracks
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestEncodeWithStems(t *testing.T) {
+	newTone := func(freq float64, d time.Duration) *ToneTrack {
+		res := NewToneTrack(freq, 0.2, 10)
+		res.Continue(d)
+		return res
+	}
+	set := TrackSet{
+		"F1":    newTone(300, time.Millisecond*200),
+		"F2":    newTone(900, time.Millisecond*120),
+		"noise": NewNoiseTrack(PinkNoise, 0.05, 1),
+		"nested": TrackSet{
+			"a": newTone(150, time.Millisecond*250),
+			"b": newTone(2500, time.Millisecond*50),
+		},
+	}
+	set["noise"].Continue(time.Millisecond * 80)
+
+	mix, stems := set.EncodeWithStems(16000)
+	assertSamplesClose(t, set.Encode(16000), mix, 0)
+	if len(stems) != len(set) {
+		t.Fatalf("expected %d stems but got %d", len(set), len(stems))
+	}
+	sum := make([]wav.Sample, len(mix))
+	for _, id := range set.sortedIDs() {
+		stem := stems[id]
+		if len(stem) != len(mix) {
+			t.Fatalf("stem %s: expected %d samples but got %d", id, len(mix), len(stem))
+		}
+		for i, sample := range stem {
+			sum[i] += sample
+		}
+	}
+	assertSamplesClose(t, mix, sum, 0)
+	assertSamplesClose(t, set["nested"].Encode(16000)[:4000], stems["nested"][:4000], 0)
+}
+
+func TestAdjustVolumeProportional(t *testing.T) {
+	newTone := func(volume float64) *ToneTrack {
+		res := NewToneTrack(440, volume, 0)
+		res.Continue(time.Millisecond * 50)
+		return res
+	}
+	set := TrackSet{
+		"a": newTone(0.3),
+		"b": newTone(0.1),
+		"nested": TrackSet{
+			"c": newTone(0.2),
+			"d": newTone(0.05),
+		},
+	}
+	before := map[string]float64{}
+	for _, path := range []string{"a", "b", "nested", "nested/c", "nested/d"} {
+		track, _ := set.Lookup(path)
+		before[path] = track.Volume() / set.Volume()
+	}
+	set.AdjustVolumeProportional(0.325, time.Millisecond*100)
+	if v := set.Volume(); math.Abs(v-0.325) > 1e-12 {
+		t.Errorf("expected total volume 0.325 but got %f", v)
+	}
+	for path, ratio := range before {
+		track, _ := set.Lookup(path)
+		if actual := track.Volume() / set.Volume(); math.Abs(actual-ratio) > 1e-12 {
+			t.Errorf("%s: expected fraction %f but got %f", path, ratio, actual)
+		}
+	}
+	if d := set.Duration(); d != time.Millisecond*150 {
+		t.Errorf("expected duration 150ms but got %v", d)
+	}
+
+	silent := TrackSet{"a": newTone(0), "b": newTone(0)}
+	silent.AdjustVolumeProportional(0.4, time.Millisecond*10)
+	if v := silent.Volume(); math.Abs(v-0.4) > 1e-12 {
+		t.Errorf("expected a silent set to fall back on AdjustVolume, but got volume %f", v)
+	}
+}
+
+func filterTestSet() TrackSet {
+	newTone := func(volume float64) *ToneTrack {
+		return NewToneTrack(440, volume, 0)
+	}
+	return TrackSet{
+		"formant1": newTone(0.3),
+		"formant2": newTone(0.05),
+		"noise":    newTone(0.2),
+		"voice": TrackSet{
+			"formant1": newTone(0.1),
+			"breath":   newTone(0.01),
+			"inner": TrackSet{
+				"formant3": newTone(0.4),
+			},
+		},
+		"empty": TrackSet{
+			"breath": newTone(0.02),
+		},
+	}
+}
+
+func TestIncludeTracks(t *testing.T) {
+	set := filterTestSet()
+	included := set.IncludeTracks("noise", "voice", "missing")
+	if len(included) != 2 || included["noise"] != set["noise"] {
+		t.Errorf("expected the two existing tracks to be included but got %v", included)
+	}
+	if _, ok := included["voice"].(TrackSet); !ok {
+		t.Error("expected the nested set to be included as is")
+	}
+	if empty := set.IncludeTracks(); empty == nil || len(empty) != 0 {
+		t.Errorf("expected an empty non-nil set but got %v", empty)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	set := filterTestSet()
+	isFormant := func(id TrackID, track Track) bool {
+		return strings.HasPrefix(string(id), "formant")
+	}
+
+	shallow := set.Filter(isFormant)
+	if len(shallow) != 2 || shallow["formant1"] != set["formant1"] ||
+		shallow["formant2"] != set["formant2"] {
+		t.Errorf("unexpected shallow filter result: %v", shallow)
+	}
+
+	deep := set.FilterDeep(isFormant)
+	kept := []string{"formant1", "formant2", "voice/formant1", "voice/inner/formant3"}
+	for _, path := range kept {
+		expected, _ := set.Lookup(path)
+		if actual, ok := deep.Lookup(path); !ok || actual != expected {
+			t.Errorf("expected %s to be kept", path)
+		}
+	}
+	if _, ok := deep.Lookup("voice/breath"); ok {
+		t.Error("expected voice/breath to be filtered out")
+	}
+	if _, ok := deep["empty"]; ok {
+		t.Error("expected a nested set with no remaining members to be left out")
+	}
+	if len(set["voice"].(TrackSet)) != 3 {
+		t.Error("expected filtering not to modify the original set")
+	}
+
+	loud := set.FilterDeep(func(id TrackID, track Track) bool {
+		return track.Volume() > 0.15
+	})
+	if len(loud) != 3 || len(loud["voice"].(TrackSet)) != 1 {
+		t.Errorf("unexpected volume filter result: %v", loud)
+	}
+	if none := set.Filter(func(TrackID, Track) bool { return false }); none == nil ||
+		len(none) != 0 {
+		t.Errorf("expected an empty non-nil set but got %v", none)
+	}
+}
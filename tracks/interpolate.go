@@ -0,0 +1,74 @@
+package tracks
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/unixpickle/wav"
+)
+
+// sincHalfWidth is the number of zero crossings on each side of the interpolation kernel.
+const sincHalfWidth = 16
+
+// interpolateSinc reads a signal at a fractional sample position using a Hann-windowed sinc
+// kernel, treating samples outside the signal as silence.
+//
+// The cutoff is the bandwidth to keep as a fraction of the Nyquist frequency.
+// Reading a signal faster than its sample rate requires a cutoff below 1 to avoid aliasing.
+func interpolateSinc(samples []wav.Sample, pos, cutoff float64) float64 {
+	if cutoff > 1 {
+		cutoff = 1
+	}
+	radius := sincHalfWidth / cutoff
+	start := int(math.Ceil(pos - radius))
+	end := int(math.Floor(pos + radius))
+	if start < 0 {
+		start = 0
+	}
+	if end >= len(samples) {
+		end = len(samples) - 1
+	}
+
+	var res float64
+	for i := start; i <= end; i++ {
+		x := pos - float64(i)
+		window := 0.5 + 0.5*math.Cos(math.Pi*x/radius)
+		res += float64(samples[i]) * cutoff * sinc(cutoff*x) * window
+	}
+	return res
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// Resample converts a signal from one sample rate to another using windowed-sinc interpolation.
+//
+// When downsampling, the interpolation kernel doubles as a low-pass filter at the new Nyquist
+// frequency, so frequencies which the new rate cannot represent do not alias.
+// The result covers the same span of time as the input: it contains every output sample whose
+// timestamp falls before the end of the input.
+// If the rates are equal, the result is a copy of the input.
+// An error is returned if either rate is not positive.
+func Resample(samples []wav.Sample, fromRate, toRate int) ([]wav.Sample, error) {
+	if fromRate <= 0 {
+		return nil, errors.New("invalid sample rate: " + strconv.Itoa(fromRate))
+	} else if toRate <= 0 {
+		return nil, errors.New("invalid sample rate: " + strconv.Itoa(toRate))
+	}
+	if fromRate == toRate {
+		return append([]wav.Sample{}, samples...), nil
+	}
+	count := (int64(len(samples))*int64(toRate) + int64(fromRate) - 1) / int64(fromRate)
+	cutoff := float64(toRate) / float64(fromRate)
+	res := make([]wav.Sample, count)
+	for i := range res {
+		pos := float64(int64(i)*int64(fromRate)) / float64(toRate)
+		res[i] = wav.Sample(interpolateSinc(samples, pos, cutoff))
+	}
+	return res, nil
+}
@@ -0,0 +1,85 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestResampleLength(t *testing.T) {
+	for _, test := range []struct {
+		inLength int
+		fromRate int
+		toRate   int
+		expected int
+	}{
+		{44100, 44100, 48000, 48000},
+		{48000, 48000, 44100, 44100},
+		{10, 3, 7, 24},
+		{3, 3, 2, 2},
+		{1, 44100, 8000, 1},
+		{0, 8000, 44100, 0},
+	} {
+		res, err := Resample(make([]wav.Sample, test.inLength), test.fromRate, test.toRate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != test.expected {
+			t.Errorf("%d samples from %d to %d Hz: expected %d samples but got %d",
+				test.inLength, test.fromRate, test.toRate, test.expected, len(res))
+		}
+	}
+}
+
+func TestResampleSine(t *testing.T) {
+	input := make([]wav.Sample, 44100)
+	for i := range input {
+		input[i] = wav.Sample(math.Sin(2 * math.Pi * 1000 * float64(i) / 44100))
+	}
+	res, err := Resample(input, 44100, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The edges are skipped, since the kernel treats the samples past them as silence.
+	for i := 1000; i < len(res)-1000; i++ {
+		expected := math.Sin(2 * math.Pi * 1000 * float64(i) / 48000)
+		if math.Abs(float64(res[i])-expected) > 1e-3 {
+			t.Fatalf("sample %d: expected %f but got %f", i, expected, res[i])
+		}
+	}
+}
+
+func TestResampleAntialiasing(t *testing.T) {
+	input := make([]wav.Sample, 44100)
+	for i := range input {
+		input[i] = wav.Sample(math.Sin(2 * math.Pi * 15000 * float64(i) / 44100))
+	}
+	res, err := Resample(input, 44100, 16000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rms := RMSLevel(res[1000 : len(res)-1000]); rms > 0.01 {
+		t.Errorf("expected a tone above the new Nyquist frequency to be removed, but got RMS %f",
+			rms)
+	}
+}
+
+func TestResampleEdgeCases(t *testing.T) {
+	input := []wav.Sample{0.1, 0.2, 0.3}
+	res, err := Resample(input, 8000, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSamplesClose(t, input, res, 0)
+	res[0] = 1
+	if input[0] != 0.1 {
+		t.Error("expected resampling at the same rate to copy the input")
+	}
+
+	for _, rates := range [][2]int{{0, 8000}, {8000, 0}, {-8000, 8000}, {0, 0}} {
+		if _, err := Resample(input, rates[0], rates[1]); err == nil {
+			t.Errorf("expected an error resampling from %d to %d Hz", rates[0], rates[1])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tracks
+
+import (
+	"math"
+
+	"github.com/unixpickle/wav"
+)
+
+// NormalizePeak scales a signal down in place so that it fits in the range [-1, 1].
+// Signals which already fit, including silent ones, are left unchanged.
+func NormalizePeak(samples []wav.Sample) {
+	peak := PeakLevel(samples)
+	if peak <= 1 {
+		return
+	}
+	scale := wav.Sample(1 / peak)
+	for i := range samples {
+		samples[i] *= scale
+	}
+}
+
+// SoftLimit passes a signal through a soft-knee limiter in place.
+//
+// Samples within [-threshold, threshold] are left unchanged, while larger samples are
+// compressed smoothly so that they approach, but never reach, the range [-1, 1].
+// The threshold should be between 0 and 1.
+func SoftLimit(samples []wav.Sample, threshold float64) {
+	threshold = math.Max(0, math.Min(1, threshold))
+	headroom := 1 - threshold
+	for i, sample := range samples {
+		value := math.Abs(float64(sample))
+		if value <= threshold {
+			continue
+		}
+		// The tanh curve has a slope of 1 at the threshold, so the knee is smooth.
+		limited := threshold
+		if headroom > 0 {
+			limited += headroom * math.Tanh((value-threshold)/headroom)
+		}
+		samples[i] = wav.Sample(math.Copysign(limited, float64(sample)))
+	}
+}
+
+// EncodeNormalized is like Encode, but if the mix exceeds the range [-1, 1], the entire mix is
+// scaled down to fit.
+//
+// This preserves the shape of the signal, but changes its loudness depending on its loudest
+// peak.
+func (t TrackSet) EncodeNormalized(sampleRate int) []wav.Sample {
+	res := t.Encode(sampleRate)
+	NormalizePeak(res)
+	return res
+}
+
+// EncodeLimited is like Encode, but the mix is passed through SoftLimit with the given threshold.
+//
+// This keeps the loudness of quiet passages consistent, at the cost of compressing peaks.
+func (t TrackSet) EncodeLimited(sampleRate int, threshold float64) []wav.Sample {
+	res := t.Encode(sampleRate)
+	SoftLimit(res, threshold)
+	return res
+}
@@ -0,0 +1,70 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func loudTestSet() TrackSet {
+	set := TrackSet{}
+	for i, freq := range []float64{200, 300, 400, 500} {
+		tone := NewToneTrack(freq, 0.6, 0)
+		tone.Continue(time.Millisecond * 200)
+		set[TrackID(rune('a'+i))] = tone
+	}
+	return set
+}
+
+func TestEncodeNormalized(t *testing.T) {
+	set := loudTestSet()
+	mix := set.Encode(8000)
+	normalized := set.EncodeNormalized(8000)
+	peak := PeakLevel(mix)
+	if peak <= 1 {
+		t.Fatalf("expected the test mix to clip, but its peak is %f", peak)
+	}
+	if p := PeakLevel(normalized); math.Abs(p-1) > 1e-12 {
+		t.Errorf("expected a peak of 1 but got %f", p)
+	}
+	for i := range mix {
+		if math.Abs(float64(mix[i])/peak-float64(normalized[i])) > 1e-12 {
+			t.Fatalf("sample %d: expected the mix to be scaled uniformly", i)
+		}
+	}
+
+	quiet := TrackSet{"a": set["a"]}
+	assertSamplesClose(t, quiet.Encode(8000), quiet.EncodeNormalized(8000), 0)
+	silent := TrackSet{"a": NewSilenceTrack(time.Millisecond * 10)}
+	for i, sample := range silent.EncodeNormalized(8000) {
+		if sample != 0 {
+			t.Fatalf("sample %d: expected silence but got %f", i, sample)
+		}
+	}
+}
+
+func TestSoftLimit(t *testing.T) {
+	set := loudTestSet()
+	mix := set.Encode(8000)
+	limited := set.EncodeLimited(8000, 0.8)
+	for i, sample := range limited {
+		if math.Abs(float64(sample)) >= 1 {
+			t.Fatalf("sample %d: expected %f to be limited below 1", i, sample)
+		}
+		if math.Abs(float64(mix[i])) <= 0.8 && sample != mix[i] {
+			t.Fatalf("sample %d: expected %f below the threshold to pass through", i, mix[i])
+		}
+	}
+
+	// The limiter's curve is continuous and keeps a slope of 1 at the knee.
+	curve := []wav.Sample{0.8, 0.8 + 1e-6, 0.9, 3, -3, 100}
+	SoftLimit(curve, 0.8)
+	if slope := float64(curve[1]-curve[0]) / 1e-6; math.Abs(slope-1) > 1e-3 {
+		t.Errorf("expected a slope of 1 at the knee but got %f", slope)
+	}
+	if !(curve[2] < 0.9 && curve[3] > curve[2] && curve[5] > curve[3] && curve[4] == -curve[3]) {
+		t.Errorf("expected a monotonic, symmetric curve but got %v", curve)
+	}
+}
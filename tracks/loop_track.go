@@ -0,0 +1,140 @@
+package tracks
+
+import (
+	"errors"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// loopCrossfadeDuration is the length of the crossfade which hides the seam of a LoopTrack.
+const loopCrossfadeDuration = time.Millisecond * 5
+
+// A LoopTrack repeats a region of an inner track, turning a short segment into a texture of any
+// length.
+//
+// A LoopTrack plays its inner track up to the end of the loop region, then repeats the region
+// for as long as the track is continued.
+// The end of the region is crossfaded into the material just before the region, so the loop
+// wraps around without a click.
+// Regions which start at the beginning of the inner track have no such material, so the caller
+// should make them seamless.
+type LoopTrack struct {
+	inner Track
+	from  time.Duration
+	to    time.Duration
+	gain  *envelope
+}
+
+// NewLoopTrack creates a LoopTrack which repeats the range [from, to) of inner for the
+// duration d after the first pass through the region.
+// The inner track should not be modified after this.
+//
+// An error is returned if the region is empty or extends past the inner track.
+func NewLoopTrack(inner Track, from, to, d time.Duration) (*LoopTrack, error) {
+	if from < 0 || to <= from {
+		return nil, errors.New("invalid loop region")
+	}
+	if to > inner.Duration() {
+		return nil, errors.New("loop region exceeds track")
+	}
+	gain := newEnvelope(1)
+	gain.Continue(d)
+	return &LoopTrack{inner: inner, from: from, to: to, gain: gain}, nil
+}
+
+// Inner returns the track being looped.
+func (l *LoopTrack) Inner() Track {
+	return l.inner
+}
+
+// Duration returns the end of the loop region plus the duration of the repetitions.
+func (l *LoopTrack) Duration() time.Duration {
+	return l.to + l.gain.Duration()
+}
+
+// Encode renders the inner track and the repetitions of its loop region.
+//
+// If the region does not span a single sample at the sample rate, the repetitions are silent.
+// CheckSampleRate reports this case as an error.
+func (l *LoopTrack) Encode(sampleRate int) []wav.Sample {
+	res := make([]wav.Sample, sampleCount(l.Duration(), sampleRate))
+	innerSamples := l.inner.Encode(sampleRate)
+	start := sampleCount(l.from, sampleRate)
+	end := sampleCount(l.to, sampleRate)
+	if end > len(innerSamples) {
+		end = len(innerSamples)
+	}
+	copy(res, innerSamples[:start])
+	if end <= start {
+		return res
+	}
+
+	loop := crossfadedLoop(innerSamples, start, end, sampleRate)
+	gains := l.gain.Values(sampleRate, len(res)-end+1)
+	for i := start; i < len(res); i++ {
+		sample := loop[(i-start)%len(loop)]
+		if i > end {
+			sample *= wav.Sample(gains[i-end])
+		}
+		res[i] = sample
+	}
+	return res
+}
+
+// CheckSampleRate returns an error if the loop region does not span a single sample at the
+// given sample rate.
+func (l *LoopTrack) CheckSampleRate(sampleRate int) error {
+	if sampleCount(l.to, sampleRate) <= sampleCount(l.from, sampleRate) {
+		return errors.New("loop region is shorter than one sample")
+	}
+	return nil
+}
+
+// crossfadedLoop returns one period of a loop over the samples [start, end), with its tail
+// crossfaded into the samples before the region so that it wraps around without a click.
+func crossfadedLoop(innerSamples []wav.Sample, start, end, sampleRate int) []wav.Sample {
+	loop := append([]wav.Sample{}, innerSamples[start:end]...)
+	fade := sampleCount(loopCrossfadeDuration, sampleRate)
+	if fade > len(loop)/2 {
+		fade = len(loop) / 2
+	}
+	if fade > start {
+		fade = start
+	}
+	tail := len(loop) - fade
+	for i := 0; i < fade; i++ {
+		progress := wav.Sample(i+1) / wav.Sample(fade+1)
+		loop[tail+i] = loop[tail+i]*(1-progress) + innerSamples[start-fade+i]*progress
+	}
+	return loop
+}
+
+// Continue elongates the track with more repetitions of the loop region.
+func (l *LoopTrack) Continue(d time.Duration) {
+	l.gain.Continue(d)
+}
+
+// Volume returns the gain currently applied to the repetitions.
+func (l *LoopTrack) Volume() float64 {
+	return l.gain.Value()
+}
+
+// AdjustVolume elongates the repetitions while adjusting their gain.
+func (l *LoopTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	l.gain.Adjust(newVolume, d)
+}
+
+// AdjustVolumeCurve is like AdjustVolume, but the gain follows the given curve.
+func (l *LoopTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	l.gain.AdjustCurve(newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (l *LoopTrack) Clone() Track {
+	inner := cloneTrack(l.inner)
+	if inner == nil {
+		return nil
+	}
+	return &LoopTrack{inner: inner, from: l.from, to: l.to, gain: l.gain.clone()}
+}
@@ -0,0 +1,99 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestLoopTrackRepeats(t *testing.T) {
+	const sampleRate = 8000
+	ramp := make([]wav.Sample, 80)
+	for i := range ramp {
+		ramp[i] = wav.Sample(i) / 100
+	}
+	inner := NewSampleTrack(ramp, sampleRate)
+
+	// The region starts at the beginning of the track, so it is repeated verbatim.
+	loop, err := NewLoopTrack(inner, 0, time.Millisecond*5, time.Millisecond*12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loop.Continue(time.Millisecond * 3)
+	if d := loop.Duration(); d != time.Millisecond*20 {
+		t.Fatalf("expected a duration of 20ms but got %s", d)
+	}
+	samples := loop.Encode(sampleRate)
+	if len(samples) != 160 {
+		t.Fatalf("expected 160 samples but got %d", len(samples))
+	}
+	for i, sample := range samples {
+		if sample != ramp[i%40] {
+			t.Fatalf("sample %d: expected %f but got %f", i, ramp[i%40], sample)
+		}
+	}
+
+	// Volume adjustments apply to the repetitions from then on.
+	loop.AdjustVolume(0.5, 0)
+	loop.Continue(time.Millisecond * 5)
+	samples = loop.Encode(sampleRate)
+	for i := 160; i < len(samples); i++ {
+		if expected := ramp[i%40] * 0.5; math.Abs(float64(samples[i]-expected)) > 1e-6 {
+			t.Fatalf("sample %d: expected %f but got %f", i, expected, samples[i])
+		}
+	}
+	if v := loop.Volume(); v != 0.5 {
+		t.Errorf("expected a volume of 0.5 but got %f", v)
+	}
+}
+
+func TestLoopTrackSeam(t *testing.T) {
+	const sampleRate = 16000
+	tone := NewToneTrack(310, 0.5, 0)
+	tone.Continue(time.Millisecond * 100)
+	loop, err := NewLoopTrack(tone, time.Millisecond*50, time.Millisecond*83, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a crossfade, the loop would jump wherever the region cuts the tone mid-cycle.
+	maxStep := 0.5 * 2 * math.Pi * 310 / sampleRate
+	samples := loop.Encode(sampleRate)
+	for i := 1; i < len(samples); i++ {
+		if step := math.Abs(float64(samples[i] - samples[i-1])); step > maxStep*1.5 {
+			t.Fatalf("sample %d: the loop jumped by %f", i, step)
+		}
+	}
+}
+
+func TestLoopTrackErrors(t *testing.T) {
+	tone := NewToneTrack(310, 0.5, 0)
+	tone.Continue(time.Millisecond * 100)
+	for _, test := range []struct {
+		from time.Duration
+		to   time.Duration
+		err  string
+	}{
+		{time.Millisecond * 10, time.Millisecond * 10, "invalid loop region"},
+		{-time.Millisecond, time.Millisecond * 10, "invalid loop region"},
+		{time.Millisecond * 50, time.Millisecond * 101, "loop region exceeds track"},
+	} {
+		if _, err := NewLoopTrack(tone, test.from, test.to, time.Second); err == nil ||
+			err.Error() != test.err {
+			t.Errorf("[%s, %s): expected error %q but got %v", test.from, test.to, test.err, err)
+		}
+	}
+
+	tiny, err := NewLoopTrack(tone, time.Microsecond*10010, time.Microsecond*10020, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tiny.CheckSampleRate(16000); err == nil {
+		t.Error("expected a region shorter than a sample to fail")
+	}
+	if err := tiny.CheckSampleRate(1000000); err != nil {
+		t.Errorf("expected a region of ten samples to pass at 1 MHz, but got %v", err)
+	}
+}
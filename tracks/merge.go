@@ -0,0 +1,45 @@
+package tracks
+
+import (
+	"errors"
+	"strings"
+)
+
+// Merge inserts every track from another set into this one, under its ID with a prefix
+// prepended, so that independently built sets can be mixed together.
+//
+// The merged set stays flat: a track "F1" merged with the prefix "speaker2/" is stored under the
+// ID "speaker2/F1" rather than in a nested set.
+// Since Lookup matches a whole path as a literal ID first, Lookup("speaker2/F1") finds it, and
+// ExcludeTracks and IncludeTracks take the prefixed IDs.
+//
+// If any prefixed ID is already in use, nothing is inserted and the error lists the conflicting
+// IDs.
+func (t TrackSet) Merge(other TrackSet, prefix TrackID) error {
+	var conflicts []string
+	for _, id := range other.sortedIDs() {
+		if _, ok := t[prefix+id]; ok {
+			conflicts = append(conflicts, string(prefix+id))
+		}
+	}
+	if len(conflicts) > 0 {
+		return errors.New("conflicting track IDs: " + strings.Join(conflicts, ", "))
+	}
+	for id, track := range other {
+		t[prefix+id] = track
+	}
+	return nil
+}
+
+// Unmerge undoes Merge by removing the tracks whose IDs start with a prefix and returning them in
+// a new set, under their IDs with the prefix removed.
+func (t TrackSet) Unmerge(prefix TrackID) TrackSet {
+	res := TrackSet{}
+	for id, track := range t {
+		if strings.HasPrefix(string(id), string(prefix)) {
+			res[id[len(prefix):]] = track
+			delete(t, id)
+		}
+	}
+	return res
+}
@@ -0,0 +1,74 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeRoundTrip(t *testing.T) {
+	const sampleRate = 8000
+	newSet := func(frequency float64) TrackSet {
+		f1 := NewToneTrack(frequency, 0.2, 0)
+		f1.Continue(time.Millisecond * 100)
+		noise := NewNoiseTrack(WhiteNoise, 0.05, 1)
+		noise.Continue(time.Millisecond * 80)
+		return TrackSet{"F1": f1, "noise": noise}
+	}
+	speaker1, speaker2 := newSet(200), newSet(300)
+	expected := speaker1.Encode(sampleRate)
+	for i, sample := range speaker2.Encode(sampleRate) {
+		expected[i] += sample
+	}
+
+	merged := TrackSet{}
+	if err := merged.Merge(speaker1, "speaker1/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := merged.Merge(speaker2, "speaker2/"); err != nil {
+		t.Fatal(err)
+	}
+	if len(merged) != 4 {
+		t.Fatalf("expected 4 tracks but got %d", len(merged))
+	}
+	if track, ok := merged.Lookup("speaker2/F1"); !ok || track != speaker2["F1"] {
+		t.Errorf("expected to find the prefixed track, but got %v", track)
+	}
+	assertSamplesClose(t, expected, merged.Encode(sampleRate), 1e-6)
+
+	unmerged := merged.Unmerge("speaker1/")
+	if len(unmerged) != 2 || unmerged["F1"] != speaker1["F1"] ||
+		unmerged["noise"] != speaker1["noise"] {
+		t.Errorf("expected to recover the first set but got %v", unmerged)
+	}
+	if len(merged) != 2 || merged["speaker2/F1"] != speaker2["F1"] {
+		t.Errorf("expected only the second set to remain but got %v", merged)
+	}
+	if empty := merged.Unmerge("speaker3/"); len(empty) != 0 || len(merged) != 2 {
+		t.Errorf("expected an unknown prefix to remove nothing, but removed %v", empty)
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	tone := NewToneTrack(200, 0.2, 0)
+	set := TrackSet{"F1": tone, "b/F2": tone, "b/F3": tone}
+	other := TrackSet{"F1": NewToneTrack(300, 0.2, 0), "F2": NewToneTrack(300, 0.2, 0),
+		"F3": NewToneTrack(300, 0.2, 0), "F4": NewToneTrack(300, 0.2, 0)}
+	for _, test := range []struct {
+		prefix TrackID
+		err    string
+	}{
+		{"", "conflicting track IDs: F1"},
+		{"b/", "conflicting track IDs: b/F2, b/F3"},
+	} {
+		if err := set.Merge(other, test.prefix); err == nil || err.Error() != test.err {
+			t.Errorf("prefix %q: expected error %q but got %v", test.prefix, test.err, err)
+		}
+		if len(set) != 3 || set["F1"] != tone {
+			t.Errorf("prefix %q: expected a failed merge to leave the set alone, but got %v",
+				test.prefix, set)
+		}
+	}
+	if err := set.Merge(other, "c/"); err != nil || len(set) != 7 {
+		t.Errorf("expected a distinct prefix to merge, but got %v with %d tracks", err, len(set))
+	}
+}
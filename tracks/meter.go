@@ -0,0 +1,154 @@
+package tracks
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// DefaultMeterWindow is a window size for RMSSeries which is short enough to follow syllables.
+const DefaultMeterWindow = time.Millisecond * 10
+
+// Peak returns the largest absolute sample value of a track's rendered signal.
+//
+// For a TrackSet, this measures the summed mix, so it accounts for clipping and phase
+// cancellation between the members.
+func Peak(t Track, sampleRate int) float64 {
+	var peak float64
+	meterStream(t, sampleRate, func(chunk []wav.Sample) {
+		peak = math.Max(peak, PeakLevel(chunk))
+	})
+	return peak
+}
+
+// RMS returns the root mean square of a track's rendered signal.
+// A full-scale sine has an RMS of 1/sqrt(2).
+//
+// Like Peak, this measures the mix of a TrackSet rather than its members.
+func RMS(t Track, sampleRate int) float64 {
+	var sum float64
+	var count int
+	meterStream(t, sampleRate, func(chunk []wav.Sample) {
+		sum += squareSum(chunk)
+		count += len(chunk)
+	})
+	if count == 0 {
+		return 0
+	}
+	return math.Sqrt(sum / float64(count))
+}
+
+// RMSSeries returns the RMS of a track's rendered signal in consecutive windows of the given
+// length.
+// The last window may be shorter than the others.
+// A window shorter than one sample is treated as one sample long.
+func RMSSeries(t Track, sampleRate int, window time.Duration) []float64 {
+	size := sampleCount(window, sampleRate)
+	if size < 1 {
+		size = 1
+	}
+	var res []float64
+	var sum float64
+	var count int
+	meterStream(t, sampleRate, func(chunk []wav.Sample) {
+		for len(chunk) > 0 {
+			n := size - count
+			if n > len(chunk) {
+				n = len(chunk)
+			}
+			sum += squareSum(chunk[:n])
+			count += n
+			chunk = chunk[n:]
+			if count == size {
+				res = append(res, math.Sqrt(sum/float64(count)))
+				sum, count = 0, 0
+			}
+		}
+	})
+	if count > 0 {
+		res = append(res, math.Sqrt(sum/float64(count)))
+	}
+	return res
+}
+
+// PeakLevel returns the largest absolute value in a signal.
+func PeakLevel(samples []wav.Sample) float64 {
+	var peak float64
+	for _, sample := range samples {
+		peak = math.Max(peak, math.Abs(float64(sample)))
+	}
+	return peak
+}
+
+// RMSLevel returns the root mean square of a signal, or 0 for an empty signal.
+func RMSLevel(samples []wav.Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	return math.Sqrt(squareSum(samples) / float64(len(samples)))
+}
+
+// Peak is like the Peak function, measuring the mix of the set.
+func (t TrackSet) Peak(sampleRate int) float64 {
+	return Peak(t, sampleRate)
+}
+
+// RMS is like the RMS function, measuring the mix of the set.
+func (t TrackSet) RMS(sampleRate int) float64 {
+	return RMS(t, sampleRate)
+}
+
+// A MeteredTrack is an oscillator whose RMS level follows from its Volume, so that VerifyVolume
+// can check its output against it.
+type MeteredTrack interface {
+	Track
+
+	// RMSRatio returns the ratio of the RMS level of the current sound to its Volume, when it is
+	// encoded at the given sample rate.
+	RMSRatio(sampleRate int) float64
+}
+
+// VerifyVolume checks that the measured RMS of an oscillator matches the RMS implied by its
+// Volume and RMSRatio, returning an error if they differ by more than a fraction tolerance of
+// the expected RMS.
+// The track should have a steady volume, since only its current Volume is checked.
+func VerifyVolume(t Track, sampleRate int, tolerance float64) error {
+	metered, ok := t.(MeteredTrack)
+	if !ok {
+		return errors.New("cannot verify the volume of this track")
+	}
+	expected := metered.Volume() * metered.RMSRatio(sampleRate)
+	actual := RMS(t, sampleRate)
+	if math.Abs(actual-expected) > tolerance*expected {
+		return errors.New("RMS " + strconv.FormatFloat(actual, 'g', 6, 64) +
+			" does not match expected RMS " + strconv.FormatFloat(expected, 'g', 6, 64))
+	}
+	return nil
+}
+
+// meterStream streams a track in chunks, so that long tracks can be metered without holding
+// their entire signal in memory.
+func meterStream(t Track, sampleRate int, f func(chunk []wav.Sample)) {
+	stream := Stream(t, sampleRate)
+	buf := make([]wav.Sample, streamChunkSize)
+	for {
+		n := stream.Read(buf)
+		if n > 0 {
+			f(buf[:n])
+		}
+		if n < len(buf) {
+			return
+		}
+	}
+}
+
+func squareSum(samples []wav.Sample) float64 {
+	var sum float64
+	for _, sample := range samples {
+		sum += float64(sample) * float64(sample)
+	}
+	return sum
+}
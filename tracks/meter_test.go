@@ -0,0 +1,102 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVerifyVolume(t *testing.T) {
+	const sampleRate = 44100
+	for _, waveform := range []Waveform{SineWave, SawtoothWave, PulseWave, GlottalWave} {
+		tone := NewToneTrack(441, 0.4, 0)
+		tone.SetWaveform(waveform)
+		if waveform == PulseWave {
+			tone.SetOpenQuotient(0.5)
+		}
+		tone.Continue(time.Second)
+		if err := VerifyVolume(tone, sampleRate, 0.01); err != nil {
+			t.Errorf("waveform %d: %s", waveform, err)
+		}
+	}
+
+	for _, freq := range []float64{220.5, 4410} {
+		harmonic := NewHarmonicToneTrack(freq, 0.5, HarmonicRolloff(-6, 20))
+		harmonic.Continue(time.Second)
+		if err := VerifyVolume(harmonic, sampleRate, 0.01); err != nil {
+			t.Errorf("harmonic tone at %f Hz: %s", freq, err)
+		}
+	}
+
+	for _, strength := range []float64{0, 0.01, 0.1} {
+		sawtooth := NewSawtoothTrack(441, 3)
+		params := sawtooth.Parameters()
+		params.Volume = 0.5
+		params.Formants = []float64{700, 1220, 2600}
+		params.Strength = strength
+		sawtooth.AdjustParameters(params, 0)
+		sawtooth.Continue(time.Second)
+		if err := VerifyVolume(sawtooth, sampleRate, 0.01); err != nil {
+			t.Errorf("sawtooth with strength %f: %s", strength, err)
+		}
+	}
+}
+
+func TestVerifyVolumeMismatch(t *testing.T) {
+	tone := NewToneTrack(441, 0.5, 0)
+	tone.Continue(time.Second)
+	tone.AdjustVolume(0.1, time.Millisecond*10)
+	if err := VerifyVolume(tone, 44100, 0.01); err == nil {
+		t.Error("expected a tone which mostly exceeds its current volume to fail")
+	}
+	if err := VerifyVolume(NewSilenceTrack(time.Second), 44100, 0.01); err == nil {
+		t.Error("expected an unsupported track to fail")
+	}
+}
+
+func TestPeakRMSSine(t *testing.T) {
+	const sampleRate = 44100
+	tone := NewToneTrackPhase(441, 1, 0, 0.25)
+	tone.Continue(time.Second)
+	if peak := Peak(tone, sampleRate); math.Abs(peak-1) > 1e-4 {
+		t.Errorf("expected a full-scale sine to peak at 1 but got %f", peak)
+	}
+	if rms := RMS(tone, sampleRate); math.Abs(rms-1/math.Sqrt2) > 1e-3 {
+		t.Errorf("expected a full-scale sine to have an RMS of %f but got %f", 1/math.Sqrt2, rms)
+	}
+
+	quiet := NewToneTrack(441, 0.5, 0)
+	quiet.Continue(time.Millisecond * 100)
+	quiet.AdjustVolume(0.1, time.Millisecond)
+	quiet.Continue(time.Millisecond * 100)
+	series := RMSSeries(quiet, sampleRate, DefaultMeterWindow)
+	if len(series) != 21 {
+		t.Fatalf("expected 21 windows but got %d", len(series))
+	}
+	for i, expected := range map[int]float64{0: 0.5, 8: 0.5, 12: 0.1, 19: 0.1} {
+		expected /= math.Sqrt2
+		if math.Abs(series[i]-expected) > 0.005 {
+			t.Errorf("window %d: expected an RMS of %f but got %f", i, expected, series[i])
+		}
+	}
+}
+
+func TestMeterTrackSetMix(t *testing.T) {
+	const sampleRate = 44100
+	tone := NewToneTrackPhase(441, 0.5, 0, 0)
+	tone.Continue(time.Second)
+	opposite := NewToneTrackPhase(441, 0.5, 0, 0.5)
+	opposite.Continue(time.Second)
+	set := TrackSet{"tone": tone, "opposite": opposite}
+	if rms := set.RMS(sampleRate); rms > 1e-4 {
+		t.Errorf("expected opposite phases to cancel, but got an RMS of %f", rms)
+	}
+	if peak := set.Peak(sampleRate); peak > 1e-4 {
+		t.Errorf("expected opposite phases to cancel, but got a peak of %f", peak)
+	}
+
+	same := TrackSet{"a": tone, "b": tone.Clone()}
+	if peak := same.Peak(sampleRate); math.Abs(peak-1) > 1e-3 {
+		t.Errorf("expected equal phases to add up to a peak of 1, but got %f", peak)
+	}
+}
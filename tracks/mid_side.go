@@ -0,0 +1,142 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// MidSide converts left and right channels into mid and side channels, where the mid channel is
+// the average of the two and the side channel is half their difference.
+// If one channel is shorter than the other, it is padded with silence.
+func MidSide(left, right []wav.Sample) (mid, side []wav.Sample) {
+	count := len(left)
+	if len(right) > count {
+		count = len(right)
+	}
+	mid = make([]wav.Sample, count)
+	side = make([]wav.Sample, count)
+	for i := range mid {
+		var l, r wav.Sample
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		mid[i] = (l + r) / 2
+		side[i] = (l - r) / 2
+	}
+	return
+}
+
+// LeftRight is the inverse of MidSide.
+// If one channel is shorter than the other, it is padded with silence.
+func LeftRight(mid, side []wav.Sample) (left, right []wav.Sample) {
+	count := len(mid)
+	if len(side) > count {
+		count = len(side)
+	}
+	left = make([]wav.Sample, count)
+	right = make([]wav.Sample, count)
+	for i := range left {
+		var m, s wav.Sample
+		if i < len(mid) {
+			m = mid[i]
+		}
+		if i < len(side) {
+			s = side[i]
+		}
+		left[i] = m + s
+		right[i] = m - s
+	}
+	return
+}
+
+// A MidSideTrack wraps a StereoTrack and adjusts its stereo width when it is encoded in stereo.
+// The inner track's stereo encoding is split into mid and side channels with MidSide, each
+// channel is scaled by its own gain, and the channels are recombined with LeftRight.
+// Raising the side gain widens the stereo image, and a side gain of zero collapses it to mono.
+//
+// When a MidSideTrack is encoded in mono, the side gain has no effect, and the inner track's mono
+// encoding is scaled by the mid gain.
+type MidSideTrack struct {
+	inner    StereoTrack
+	midGain  float64
+	sideGain float64
+}
+
+// NewMidSideTrack creates a MidSideTrack with unity gains, which leaves the inner track
+// unchanged.
+func NewMidSideTrack(inner StereoTrack) *MidSideTrack {
+	return &MidSideTrack{inner: inner, midGain: 1, sideGain: 1}
+}
+
+// Inner returns the track whose width is adjusted.
+func (m *MidSideTrack) Inner() Track {
+	return m.inner
+}
+
+// MidGain returns the gain applied to the mid channel when encoding.
+func (m *MidSideTrack) MidGain() float64 {
+	return m.midGain
+}
+
+// SetMidGain sets the gain applied to the mid channel when encoding.
+func (m *MidSideTrack) SetMidGain(gain float64) {
+	m.midGain = gain
+}
+
+// SideGain returns the gain applied to the side channel when encoding.
+func (m *MidSideTrack) SideGain() float64 {
+	return m.sideGain
+}
+
+// SetSideGain sets the gain applied to the side channel when encoding.
+func (m *MidSideTrack) SetSideGain(gain float64) {
+	m.sideGain = gain
+}
+
+func (m *MidSideTrack) Duration() time.Duration {
+	return m.inner.Duration()
+}
+
+// Encode generates the inner track's mono encoding, scaled by the mid gain.
+func (m *MidSideTrack) Encode(sampleRate int) []wav.Sample {
+	return scaledSamples(m.inner.Encode(sampleRate), m.midGain)
+}
+
+// EncodeStereo encodes the inner track in stereo and rescales its mid and side channels.
+func (m *MidSideTrack) EncodeStereo(sampleRate int) []wav.Sample {
+	mid, side := MidSide(deinterleave(m.inner.EncodeStereo(sampleRate)))
+	return interleave(LeftRight(scaledSamples(mid, m.midGain), scaledSamples(side, m.sideGain)))
+}
+
+func (m *MidSideTrack) Continue(d time.Duration) {
+	m.inner.Continue(d)
+}
+
+// Volume returns the volume of the inner track, scaled by the mid gain.
+func (m *MidSideTrack) Volume() float64 {
+	return m.inner.Volume() * m.midGain
+}
+
+// AdjustVolume elongates the track while adjusting the inner track's volume so that Volume
+// reaches newVolume.
+// If the mid gain is zero, the track is simply elongated.
+func (m *MidSideTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	if m.midGain == 0 {
+		m.inner.Continue(d)
+	} else {
+		m.inner.AdjustVolume(newVolume/m.midGain, d)
+	}
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (m *MidSideTrack) Clone() Track {
+	inner, ok := cloneTrack(m.inner).(StereoTrack)
+	if !ok {
+		return nil
+	}
+	return &MidSideTrack{inner: inner, midGain: m.midGain, sideGain: m.sideGain}
+}
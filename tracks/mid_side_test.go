@@ -0,0 +1,69 @@
+package tracks
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestMidSideRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	left := make([]wav.Sample, 1000)
+	right := make([]wav.Sample, 1000)
+	for i := range left {
+		left[i] = wav.Sample(random.Float64()*2 - 1)
+		right[i] = wav.Sample(random.Float64()*2 - 1)
+	}
+	newLeft, newRight := LeftRight(MidSide(left, right))
+	assertSamplesClose(t, left, newLeft, 1e-12)
+	assertSamplesClose(t, right, newRight, 1e-12)
+
+	// The shorter channel is padded with silence.
+	newLeft, newRight = LeftRight(MidSide(left[:10], right[:4]))
+	assertSamplesClose(t, left[:10], newLeft, 1e-12)
+	assertSamplesClose(t, append(append([]wav.Sample{}, right[:4]...), make([]wav.Sample, 6)...),
+		newRight, 1e-12)
+}
+
+func TestMidSideTrackWidth(t *testing.T) {
+	const sampleRate = 8000
+	low := NewToneTrack(300, 0.4, 0)
+	low.Continue(time.Millisecond * 200)
+	high := NewToneTrack(500, 0.2, 0)
+	high.Continue(time.Millisecond * 200)
+	source := TrackSet{"low": NewPannedTrack(low, -0.6), "high": NewPannedTrack(high, 0.8)}
+	sourceMid, sourceSide := MidSide(deinterleave(source.EncodeStereo(sampleRate)))
+
+	track := NewMidSideTrack(source)
+	assertSamplesClose(t, source.EncodeStereo(sampleRate), track.EncodeStereo(sampleRate), 1e-12)
+
+	// The side channel scales with its gain, while the mono content stays put.
+	for _, sideGain := range []float64{0, 0.5, 2} {
+		track.SetSideGain(sideGain)
+		left, right := deinterleave(track.EncodeStereo(sampleRate))
+		mid, side := MidSide(left, right)
+		assertSamplesClose(t, sourceMid, mid, 1e-12)
+		assertSamplesClose(t, scaledSamples(sourceSide, sideGain), side, 1e-12)
+		if sideGain == 0 {
+			assertSamplesClose(t, left, right, 0)
+		}
+		assertSamplesClose(t, source.Encode(sampleRate), track.Encode(sampleRate), 0)
+	}
+
+	track.SetMidGain(0.5)
+	mid, _ := MidSide(deinterleave(track.EncodeStereo(sampleRate)))
+	assertSamplesClose(t, scaledSamples(sourceMid, 0.5), mid, 1e-12)
+	assertSamplesClose(t, scaledSamples(source.Encode(sampleRate), 0.5), track.Encode(sampleRate),
+		1e-12)
+	if v := track.Volume(); math.Abs(v-source.Volume()/2) > 1e-12 {
+		t.Errorf("expected the mid gain to halve the volume to %f, but got %f", source.Volume()/2,
+			v)
+	}
+	track.AdjustVolume(0.3, time.Millisecond*50)
+	if v := track.Volume(); math.Abs(v-0.3) > 1e-12 {
+		t.Errorf("expected a volume of 0.3 but got %f", v)
+	}
+}
@@ -0,0 +1,163 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A ModulationShape is the shape of one period of a ModulatedTrack's modulator.
+type ModulationShape int
+
+const (
+	// SineModulation swells and fades smoothly.
+	SineModulation ModulationShape = iota
+
+	// TriangleModulation swells and fades linearly.
+	TriangleModulation
+)
+
+// A ModulatedTrack applies a tremolo to an inner track by periodically modulating the amplitude
+// of its encoded output.
+//
+// The modulator swings the gain between 1 and 1-depth, starting at 1.
+// Its rate and depth are automated in the same way as a Track's volume, and the modulator's
+// phase follows the rate smoothly as it changes, so the modulation never jumps.
+// A depth of 0 leaves the inner track's output unchanged.
+type ModulatedTrack struct {
+	inner Track
+	shape ModulationShape
+	rate  *envelope
+	depth *envelope
+}
+
+// NewModulatedTrack creates a ModulatedTrack which modulates an inner track at the given rate,
+// in Hz, and depth, between 0 and 1.
+func NewModulatedTrack(inner Track, rate, depth float64,
+	shape ModulationShape) *ModulatedTrack {
+	return &ModulatedTrack{
+		inner: inner,
+		shape: shape,
+		rate:  newEnvelope(clampModulationRate(rate)),
+		depth: newEnvelope(clampModulationDepth(depth)),
+	}
+}
+
+// Inner returns the track being modulated.
+func (m *ModulatedTrack) Inner() Track {
+	return m.inner
+}
+
+// Shape returns the shape of the modulator.
+func (m *ModulatedTrack) Shape() ModulationShape {
+	return m.shape
+}
+
+// Rate returns the rate of the modulation at the end of the track, in Hz.
+func (m *ModulatedTrack) Rate() float64 {
+	return m.rate.Value()
+}
+
+// Depth returns the depth of the modulation at the end of the track.
+func (m *ModulatedTrack) Depth() float64 {
+	return m.depth.Value()
+}
+
+func (m *ModulatedTrack) Duration() time.Duration {
+	return m.inner.Duration()
+}
+
+func (m *ModulatedTrack) Encode(sampleRate int) []wav.Sample {
+	res := m.inner.Encode(sampleRate)
+	rates := m.rate.Values(sampleRate, len(res))
+	depths := m.depth.Values(sampleRate, len(res))
+	var phase float64
+	for i, depth := range depths {
+		if depth != 0 {
+			res[i] *= wav.Sample(1 - depth*(1-m.modulator(phase))/2)
+		}
+		phase += rates[i] / float64(sampleRate)
+		phase -= math.Floor(phase)
+	}
+	return res
+}
+
+// modulator evaluates the modulator's shape, which ranges from -1 to 1, at a phase in turns.
+func (m *ModulatedTrack) modulator(phase float64) float64 {
+	if m.shape == TriangleModulation {
+		return 4*math.Abs(phase-0.5) - 1
+	}
+	phase += 0.25
+	return sineTurns(phase - math.Floor(phase))
+}
+
+// Continue elongates the inner track, keeping the rate and depth of the modulation.
+func (m *ModulatedTrack) Continue(d time.Duration) {
+	m.sync()
+	m.inner.Continue(d)
+	m.rate.Continue(d)
+	m.depth.Continue(d)
+}
+
+// Volume returns the inner track's volume scaled by the average gain of the modulation, so that
+// a deep tremolo counts as a quieter sound.
+func (m *ModulatedTrack) Volume() float64 {
+	return m.inner.Volume() * m.averageGain()
+}
+
+// AdjustVolume elongates the inner track while adjusting its volume so that Volume reaches
+// newVolume at the current depth.
+func (m *ModulatedTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	m.sync()
+	m.inner.AdjustVolume(newVolume/m.averageGain(), d)
+	m.rate.Continue(d)
+	m.depth.Continue(d)
+}
+
+// AdjustModulation elongates the track while moving the modulation to a new rate and depth, so
+// that a tremolo can be ramped in and out.
+// The inner track is continued with its current sound.
+func (m *ModulatedTrack) AdjustModulation(rate, depth float64, transition time.Duration) {
+	m.sync()
+	m.inner.Continue(transition)
+	m.rate.Adjust(clampModulationRate(rate), transition)
+	m.depth.Adjust(clampModulationDepth(depth), transition)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (m *ModulatedTrack) Clone() Track {
+	inner := cloneTrack(m.inner)
+	if inner == nil {
+		return nil
+	}
+	return &ModulatedTrack{
+		inner: inner,
+		shape: m.shape,
+		rate:  m.rate.clone(),
+		depth: m.depth.clone(),
+	}
+}
+
+// averageGain returns the average gain of the modulation at the current depth.
+// Both shapes average to 0, so the gain averages to the middle of its swing.
+func (m *ModulatedTrack) averageGain() float64 {
+	return 1 - m.depth.Value()/2
+}
+
+// sync continues the rate and depth to the end of the inner track, which may have been
+// elongated directly.
+func (m *ModulatedTrack) sync() {
+	if gap := m.inner.Duration() - m.rate.Duration(); gap > 0 {
+		m.rate.Continue(gap)
+		m.depth.Continue(gap)
+	}
+}
+
+func clampModulationRate(rate float64) float64 {
+	return math.Max(rate, 0)
+}
+
+func clampModulationDepth(depth float64) float64 {
+	return math.Max(0, math.Min(depth, 1))
+}
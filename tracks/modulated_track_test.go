@@ -0,0 +1,103 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestModulatedTrackShapes(t *testing.T) {
+	const sampleRate = 8000
+	ones := make([]wav.Sample, sampleRate)
+	for i := range ones {
+		ones[i] = 1
+	}
+	for _, test := range []struct {
+		shape     ModulationShape
+		modulator func(phase float64) float64
+	}{
+		{SineModulation, func(phase float64) float64 {
+			return math.Cos(2 * math.Pi * phase)
+		}},
+		{TriangleModulation, func(phase float64) float64 {
+			return 4*math.Abs(phase-0.5) - 1
+		}},
+	} {
+		modulated := NewModulatedTrack(NewSampleTrack(ones, sampleRate), 5, 0.6, test.shape)
+		samples := modulated.Encode(sampleRate)
+		if len(samples) != len(ones) {
+			t.Fatalf("shape %d: expected %d samples but got %d", test.shape, len(ones),
+				len(samples))
+		}
+
+		// The gain swings between 1 and 0.4, five times a second.
+		for i, sample := range samples {
+			phase := float64(i) * 5 / sampleRate
+			phase -= math.Floor(phase)
+			expected := 1 - 0.6*(1-test.modulator(phase))/2
+			if math.Abs(float64(sample)-expected) > 1e-6 {
+				t.Fatalf("shape %d: sample %d: expected %f but got %f", test.shape, i, expected,
+					sample)
+			}
+		}
+	}
+
+	// Without depth, the inner track is unchanged.
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.Continue(time.Millisecond * 100)
+	flat := NewModulatedTrack(tone, 5, 0, SineModulation)
+	assertSamplesClose(t, tone.Encode(sampleRate), flat.Encode(sampleRate), 0)
+}
+
+func TestModulatedTrackAutomation(t *testing.T) {
+	const sampleRate = 8000
+	tone := NewToneTrack(300, 0.5, 0)
+	modulated := NewModulatedTrack(tone, -3, 1.5, SineModulation)
+	if modulated.Rate() != 0 || modulated.Depth() != 1 {
+		t.Errorf("expected a clamped rate of 0 and depth of 1, but got %f and %f",
+			modulated.Rate(), modulated.Depth())
+	}
+	if v := modulated.Volume(); math.Abs(v-0.25) > 1e-9 {
+		t.Errorf("expected a full tremolo to halve the volume to 0.25, but got %f", v)
+	}
+
+	modulated = NewModulatedTrack(tone, 4, 0, TriangleModulation)
+	modulated.Continue(time.Millisecond * 100)
+	modulated.AdjustModulation(9, 0.8, time.Millisecond*300)
+	// The inner track may be elongated directly, and the modulation holds over that time.
+	tone.Continue(time.Millisecond * 100)
+	modulated.AdjustVolume(0.3, time.Millisecond*100)
+	if d := modulated.Duration(); d != time.Millisecond*600 {
+		t.Fatalf("expected a duration of 600ms but got %s", d)
+	}
+	if modulated.Rate() != 9 || modulated.Depth() != 0.8 {
+		t.Errorf("expected a rate of 9 and depth of 0.8, but got %f and %f", modulated.Rate(),
+			modulated.Depth())
+	}
+	if v := modulated.Volume(); math.Abs(v-0.3) > 1e-9 {
+		t.Errorf("expected a volume of 0.3 but got %f", v)
+	}
+	clone := modulated.Clone().(*ModulatedTrack)
+	clone.Continue(time.Millisecond * 100)
+	if d := modulated.Duration(); d != time.Millisecond*600 {
+		t.Errorf("expected the clone to continue independently, but the original lasts %s", d)
+	}
+
+	// The tremolo fades in and speeds up without jumps, so the tone changes no faster than an
+	// unmodulated tone at full volume would.
+	samples := modulated.Encode(sampleRate)
+	maxStep := 0.5 * 2 * math.Pi * 300 / sampleRate
+	for i := 1; i < len(samples); i++ {
+		if step := math.Abs(float64(samples[i] - samples[i-1])); step > maxStep*1.1 {
+			t.Fatalf("sample %d: the tone jumped by %f", i, step)
+		}
+	}
+	plain := tone.Encode(sampleRate)
+	assertSamplesClose(t, plain[:800], samples[:800], 0)
+	if level := RMSLevel(samples[3200:4000]); level > RMSLevel(plain[3200:4000])*0.8 {
+		t.Errorf("expected the tremolo to lower the level below %f, but got %f",
+			RMSLevel(plain[3200:4000])*0.8, level)
+	}
+}
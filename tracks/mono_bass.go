@@ -0,0 +1,133 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// MonoBass collapses the frequencies of a stereo signal below a cutoff to mono, leaving higher
+// frequencies untouched.
+//
+// This works by removing the low frequencies from the side channel with a zero-phase high-pass
+// filter, whose magnitude response is that of a fourth-order Linkwitz-Riley filter, so that the
+// left and right channels match below the cutoff.
+// Since the filter does not shift the phase of the side channel, the highs keep their alignment
+// with the mid channel, and they come through unchanged.
+func MonoBass(left, right []wav.Sample, sampleRate int, cutoff float64) (newLeft,
+	newRight []wav.Sample) {
+	mid, side := MidSide(left, right)
+	return LeftRight(mid, zeroPhaseFilter(side, HighPassFilter, cutoff, sampleRate))
+}
+
+// LowBandCorrelation measures how close the frequencies of a stereo signal below a cutoff are
+// to mono.
+//
+// Both channels are low-passed with the same zero-phase filter as MonoBass, and the result is
+// the correlation of the filtered channels, which is 1 for identical bass, 0 for unrelated bass,
+// and -1 for bass of opposite polarity.
+// It is 0 if either channel has no bass at all.
+func LowBandCorrelation(left, right []wav.Sample, sampleRate int, cutoff float64) float64 {
+	lowLeft := zeroPhaseFilter(left, LowPassFilter, cutoff, sampleRate)
+	lowRight := zeroPhaseFilter(right, LowPassFilter, cutoff, sampleRate)
+	var lr, ll, rr float64
+	for i := 0; i < len(lowLeft) && i < len(lowRight); i++ {
+		l, r := float64(lowLeft[i]), float64(lowRight[i])
+		lr += l * r
+		ll += l * l
+		rr += r * r
+	}
+	if ll == 0 || rr == 0 {
+		return 0
+	}
+	return lr / math.Sqrt(ll*rr)
+}
+
+// zeroPhaseFilter filters a signal forwards and then backwards with a second-order Butterworth
+// filter.
+// The backward pass cancels the phase shift of the forward pass and squares its magnitude
+// response, so the low-pass and high-pass responses add up to exactly 1 at every frequency.
+func zeroPhaseFilter(samples []wav.Sample, kind FilterType, cutoff float64,
+	sampleRate int) []wav.Sample {
+	res := make([]wav.Sample, len(samples))
+	forward := &biquad{}
+	forward.configure(kind, cutoff, butterworthQ, sampleRate)
+	for i, sample := range samples {
+		res[i] = wav.Sample(forward.process(float64(sample)))
+	}
+	backward := &biquad{}
+	backward.configure(kind, cutoff, butterworthQ, sampleRate)
+	for i := len(res) - 1; i >= 0; i-- {
+		res[i] = wav.Sample(backward.process(float64(res[i])))
+	}
+	return res
+}
+
+// A MonoBassTrack wraps a StereoTrack and collapses its low frequencies to mono when it is
+// encoded in stereo.
+// Since collapsing the bass only affects the difference between the channels, the mono encoding
+// of a MonoBassTrack matches that of its inner track.
+type MonoBassTrack struct {
+	inner  StereoTrack
+	cutoff float64
+}
+
+// NewMonoBassTrack creates a MonoBassTrack with the given cutoff frequency.
+func NewMonoBassTrack(inner StereoTrack, cutoff float64) *MonoBassTrack {
+	return &MonoBassTrack{inner: inner, cutoff: cutoff}
+}
+
+// Cutoff returns the frequency below which the track is mono.
+func (m *MonoBassTrack) Cutoff() float64 {
+	return m.cutoff
+}
+
+// SetCutoff sets the frequency below which the track is mono.
+func (m *MonoBassTrack) SetCutoff(cutoff float64) {
+	m.cutoff = cutoff
+}
+
+// Inner returns the track whose bass is folded to mono.
+func (m *MonoBassTrack) Inner() Track {
+	return m.inner
+}
+
+func (m *MonoBassTrack) Duration() time.Duration {
+	return m.inner.Duration()
+}
+
+func (m *MonoBassTrack) Encode(sampleRate int) []wav.Sample {
+	return m.inner.Encode(sampleRate)
+}
+
+// EncodeStereo encodes the inner track in stereo and collapses its low frequencies.
+func (m *MonoBassTrack) EncodeStereo(sampleRate int) []wav.Sample {
+	left, right := deinterleave(m.inner.EncodeStereo(sampleRate))
+	return interleave(MonoBass(left, right, sampleRate, m.cutoff))
+}
+
+func (m *MonoBassTrack) Continue(d time.Duration) {
+	m.inner.Continue(d)
+}
+
+func (m *MonoBassTrack) Volume() float64 {
+	return m.inner.Volume()
+}
+
+func (m *MonoBassTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	m.inner.AdjustVolume(newVolume, d)
+}
+
+func (m *MonoBassTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(m.inner, newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (m *MonoBassTrack) Clone() Track {
+	inner, ok := cloneTrack(m.inner).(StereoTrack)
+	if !ok {
+		return nil
+	}
+	return &MonoBassTrack{inner: inner, cutoff: m.cutoff}
+}
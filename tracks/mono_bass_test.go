@@ -0,0 +1,60 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestMonoBass(t *testing.T) {
+	const sampleRate = 16000
+	stereo := func(leftFreq, rightFreq float64) (left, right []wav.Sample) {
+		leftTone := NewToneTrack(leftFreq, 0.5, 0)
+		leftTone.Continue(time.Second)
+		rightTone := NewToneTrack(rightFreq, 0.5, 0)
+		rightTone.Continue(time.Second)
+		set := TrackSet{"left": NewPannedTrack(leftTone, -1), "right": NewPannedTrack(rightTone, 1)}
+		return deinterleave(set.EncodeStereo(sampleRate))
+	}
+
+	// Unrelated bass in the two channels becomes the same bass.
+	left, right := stereo(45, 60)
+	if c := LowBandCorrelation(left, right, sampleRate, 150); math.Abs(c) > 0.05 {
+		t.Errorf("expected unrelated bass to be uncorrelated, but got %f", c)
+	}
+	newLeft, newRight := MonoBass(left, right, sampleRate, 150)
+	if c := LowBandCorrelation(newLeft, newRight, sampleRate, 150); c < 0.99 {
+		t.Errorf("expected the bass to be mono, but its correlation is %f", c)
+	}
+
+	// Highs come through unchanged, without a phase shift between the channels.
+	left, right = stereo(3000, 4500)
+	newLeft, newRight = MonoBass(left, right, sampleRate, 150)
+	skip := sampleRate / 10
+	assertSamplesClose(t, left[skip:len(left)-skip], newLeft[skip:len(left)-skip], 1e-3)
+	assertSamplesClose(t, right[skip:len(right)-skip], newRight[skip:len(right)-skip], 1e-3)
+
+	if c := LowBandCorrelation(left, make([]wav.Sample, len(right)), sampleRate, 150); c != 0 {
+		t.Errorf("expected a silent channel to have a correlation of 0, but got %f", c)
+	}
+	if c := LowBandCorrelation(left, scaledSamples(left, -0.5), sampleRate, 150); c > -0.999 {
+		t.Errorf("expected bass of opposite polarity to have a correlation of -1, but got %f", c)
+	}
+}
+
+func TestMonoBassTrack(t *testing.T) {
+	tone := NewToneTrack(50, 0.5, 0)
+	tone.Continue(time.Millisecond * 500)
+	panned := NewPannedTrack(tone, 1)
+	track := NewMonoBassTrack(panned, 150)
+	assertSamplesClose(t, panned.Encode(8000), track.Encode(8000), 0)
+	left, right := deinterleave(track.EncodeStereo(8000))
+	if PeakLevel(left[2000:]) < 0.1 {
+		t.Error("expected the bass to reach the left channel")
+	}
+	if c := LowBandCorrelation(left, right, 8000, 150); c < 0.99 {
+		t.Errorf("expected the bass to be mono, but its correlation is %f", c)
+	}
+}
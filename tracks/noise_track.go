@@ -0,0 +1,224 @@
+package tracks
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+const (
+	// octaveDecibels is the drop per octave of a spectrum whose power falls as 1/f.
+	octaveDecibels = 3.0102999566398120
+
+	// tiltBlockSize is the number of samples between updates of a gliding tilt filter.
+	tiltBlockSize = 64
+
+	// tiltLowestPole is the frequency below which a tilted spectrum levels off.
+	tiltLowestPole = 10.0
+
+	// tiltGainPoints is the number of frequencies at which a tilt filter's power gain is
+	// measured when it is normalized.
+	tiltGainPoints = 512
+)
+
+// A NoiseColor is a preset spectral tilt for a NoiseTrack.
+type NoiseColor int
+
+const (
+	// WhiteNoise has the same power at every frequency.
+	WhiteNoise NoiseColor = iota
+
+	// PinkNoise loses 3dB per octave, giving every octave the same power.
+	PinkNoise
+
+	// BrownNoise loses 6dB per octave, like integrated white noise.
+	BrownNoise
+)
+
+// Tilt returns the spectral tilt of the color, in dB per octave.
+func (n NoiseColor) Tilt() float64 {
+	switch n {
+	case PinkNoise:
+		return -octaveDecibels
+	case BrownNoise:
+		return -2 * octaveDecibels
+	default:
+		return 0
+	}
+}
+
+// A NoiseTrack generates Gaussian noise with an adjustable spectral tilt.
+//
+// The tilt is the slope of the spectrum in dB per octave, between -6 (brown) and +6 (violet).
+// Regardless of the tilt, the noise is normalized so that its RMS level matches that of a
+// ToneTrack with the same volume.
+//
+// The noise is generated from a seed, so every Encode of a NoiseTrack produces the same samples.
+type NoiseTrack struct {
+	seed   int64
+	volume *envelope
+	tilt   *envelope
+}
+
+// NewNoiseTrack creates a zero-length NoiseTrack with the given color.
+func NewNoiseTrack(color NoiseColor, volume float64, seed int64) *NoiseTrack {
+	return &NoiseTrack{
+		seed:   seed,
+		volume: newEnvelope(volume),
+		tilt:   newEnvelope(color.Tilt()),
+	}
+}
+
+func (n *NoiseTrack) Duration() time.Duration {
+	return n.volume.Duration()
+}
+
+func (n *NoiseTrack) Encode(sampleRate int) []wav.Sample {
+	res := make([]wav.Sample, sampleCount(n.Duration(), sampleRate))
+	volumes := n.volume.Values(sampleRate, len(res))
+	tilts := n.tilt.Values(sampleRate, len(res))
+
+	random := rand.New(rand.NewSource(n.seed))
+	filter := newTiltFilter(sampleRate)
+	for i := range res {
+		if i%tiltBlockSize == 0 {
+			filter.SetTilt(tilts[i])
+		}
+		// A sine with amplitude v has an RMS level of v/sqrt(2).
+		x := filter.Process(random.NormFloat64())
+		res[i] = wav.Sample(x * volumes[i] / math.Sqrt2)
+	}
+	return res
+}
+
+func (n *NoiseTrack) Continue(d time.Duration) {
+	n.volume.Continue(d)
+	n.tilt.Continue(d)
+}
+
+// Volume returns the current volume, which is the amplitude of a sine with the same RMS level.
+func (n *NoiseTrack) Volume() float64 {
+	return n.volume.Value()
+}
+
+func (n *NoiseTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	n.volume.Adjust(newVolume, d)
+	n.tilt.Continue(d)
+}
+
+func (n *NoiseTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	n.volume.AdjustCurve(newVolume, d, curve)
+	n.tilt.Continue(d)
+}
+
+// Tilt returns the current spectral tilt, in dB per octave.
+func (n *NoiseTrack) Tilt() float64 {
+	return n.tilt.Value()
+}
+
+// AdjustTilt elongates the track while gliding the spectral tilt to a new value.
+func (n *NoiseTrack) AdjustTilt(dbPerOctave float64, transition time.Duration) {
+	n.tilt.Adjust(dbPerOctave, transition)
+	n.volume.Continue(transition)
+}
+
+// Seed returns the seed from which the noise is generated.
+func (n *NoiseTrack) Seed() int64 {
+	return n.seed
+}
+
+// SetSeed sets the seed from which the noise is generated.
+func (n *NoiseTrack) SetSeed(seed int64) {
+	n.seed = seed
+}
+
+func (n *NoiseTrack) Clone() Track {
+	return &NoiseTrack{seed: n.seed, volume: n.volume.clone(), tilt: n.tilt.clone()}
+}
+
+// A tiltFilter tilts the spectrum of a signal using first-order sections whose poles are spaced
+// an octave apart.
+// Each section falls by 6dB per octave from its pole to its zero, and the zeros are placed so
+// that the average slope across each octave is the desired tilt.
+type tiltFilter struct {
+	sampleRate int
+	tilt       float64
+	gain       float64
+	sections   []tiltSection
+}
+
+type tiltSection struct {
+	poleFrequency float64
+	pole, zero    float64
+	x1, y1        float64
+}
+
+func newTiltFilter(sampleRate int) *tiltFilter {
+	res := &tiltFilter{sampleRate: sampleRate, tilt: math.NaN()}
+	for freq := tiltLowestPole; freq < float64(sampleRate)/4; freq *= 2 {
+		res.sections = append(res.sections, tiltSection{
+			poleFrequency: freq,
+			pole:          res.matchedRoot(freq),
+		})
+	}
+	return res
+}
+
+// SetTilt changes the tilt, in dB per octave, without resetting the filter state.
+// The tilt is clamped to [-6, 6].
+func (t *tiltFilter) SetTilt(tilt float64) {
+	if tilt == t.tilt {
+		return
+	}
+	t.tilt = tilt
+	octaves := math.Max(-1, math.Min(1, -tilt/(2*octaveDecibels)))
+	for i := range t.sections {
+		s := &t.sections[i]
+		s.zero = t.matchedRoot(s.poleFrequency * math.Pow(2, octaves))
+	}
+	t.gain = 1 / math.Sqrt(t.powerGain())
+}
+
+// Process filters a single sample.
+func (t *tiltFilter) Process(x float64) float64 {
+	for i := range t.sections {
+		s := &t.sections[i]
+		y := x - s.zero*s.x1 + s.pole*s.y1
+		s.x1, s.y1 = x, y
+		x = y
+	}
+	return x * t.gain
+}
+
+// powerGain measures the average power gain of the sections over the spectrum, which is the
+// power of their output for white input.
+// The frequencies are spaced logarithmically, since the response changes most at the bottom.
+func (t *tiltFilter) powerGain() float64 {
+	minAngle := 2 * math.Pi / float64(t.sampleRate)
+	step := math.Pow(math.Pi/minAngle, 1/float64(tiltGainPoints))
+	integral := t.sectionsPower(minAngle) * minAngle
+	for angle := minAngle; angle < math.Pi; angle *= step {
+		mid := math.Min(angle*math.Sqrt(step), math.Pi)
+		width := math.Min(angle*step, math.Pi) - angle
+		integral += t.sectionsPower(mid) * width
+	}
+	return integral / math.Pi
+}
+
+func (t *tiltFilter) sectionsPower(angle float64) float64 {
+	cos := math.Cos(angle)
+	res := 1.0
+	for _, s := range t.sections {
+		numerator := 1 - 2*s.zero*cos + s.zero*s.zero
+		denominator := 1 - 2*s.pole*cos + s.pole*s.pole
+		res *= numerator / denominator
+	}
+	return res
+}
+
+// matchedRoot maps a frequency on the negative real axis of the s-plane to the z-plane.
+func (t *tiltFilter) matchedRoot(freq float64) float64 {
+	return math.Exp(-2 * math.Pi * freq / float64(t.sampleRate))
+}
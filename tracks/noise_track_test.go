@@ -0,0 +1,76 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNoiseTrackColors(t *testing.T) {
+	const sampleRate = 16000
+	for _, color := range []NoiseColor{WhiteNoise, PinkNoise, BrownNoise} {
+		noise := NewNoiseTrack(color, 0.4, 1)
+		noise.Continue(time.Second * 4)
+		samples := noise.Encode(sampleRate)
+		if rms := RMSLevel(samples); math.Abs(rms/(0.4/math.Sqrt2)-1) > 0.1 {
+			t.Errorf("color %d: expected RMS %f but got %f", color, 0.4/math.Sqrt2, rms)
+		}
+
+		// The tilt levels off toward the Nyquist frequency, so the top octave is left out.
+		spectrum := averageSpectrum(samples, sampleRate)
+		for _, freq := range []float64{125, 250, 500, 1000} {
+			low := bandPower(spectrum, sampleRate, freq, freq*2)
+			high := bandPower(spectrum, sampleRate, freq*2, freq*4)
+			slope := 10 * math.Log10(high/low)
+			if math.Abs(slope-color.Tilt()) > 1 {
+				t.Errorf("color %d: expected a slope of %f dB per octave above %f Hz but got %f",
+					color, color.Tilt(), freq, slope)
+			}
+		}
+	}
+}
+
+func TestNoiseTrackLongContinue(t *testing.T) {
+	const sampleRate = 8000
+	noise := NewNoiseTrack(BrownNoise, 0.4, 2)
+	noise.Continue(time.Second * 60)
+	samples := noise.Encode(sampleRate)
+	expected := 0.4 / math.Sqrt2
+	for _, start := range []int{0, len(samples) / 2, len(samples) - sampleRate*5} {
+		if rms := RMSLevel(samples[start : start+sampleRate*5]); math.Abs(rms/expected-1) > 0.2 {
+			t.Errorf("sample %d: expected RMS %f but got %f", start, expected, rms)
+		}
+	}
+}
+
+func TestNoiseTrackTilt(t *testing.T) {
+	const sampleRate = 16000
+	noise := NewNoiseTrack(WhiteNoise, 0.3, 3)
+	noise.Continue(time.Second * 2)
+	noise.AdjustTilt(-6, time.Second)
+	noise.Continue(time.Second * 2)
+	if tilt := noise.Tilt(); tilt != -6 {
+		t.Errorf("expected tilt -6 but got %f", tilt)
+	}
+	if d := noise.Duration(); d != time.Second*5 {
+		t.Errorf("expected duration 5s but got %v", d)
+	}
+
+	samples := noise.Encode(sampleRate)
+	assertSamplesClose(t, samples, noise.Clone().Encode(sampleRate), 0)
+	for _, test := range []struct {
+		start int
+		tilt  float64
+	}{
+		{0, 0},
+		{sampleRate * 3, -6},
+	} {
+		spectrum := averageSpectrum(samples[test.start:test.start+sampleRate*2], sampleRate)
+		low := bandPower(spectrum, sampleRate, 500, 1000)
+		high := bandPower(spectrum, sampleRate, 1000, 2000)
+		if slope := 10 * math.Log10(high/low); math.Abs(slope-test.tilt) > 1 {
+			t.Errorf("sample %d: expected a slope of %f dB per octave but got %f", test.start,
+				test.tilt, slope)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// minNotchBandwidth keeps the bandwidth of a NotchTrack's anti-resonance above zero.
+const minNotchBandwidth = 1
+
+// A NotchTrack cuts an anti-resonance, or spectral zero, into an inner track.
+//
+// The notch subtracts a band-pass copy of the inner track from itself, so its depth can fade
+// between no effect and a complete cut.
+// The notch is a separate filter stage, so it stays stable wherever its center lies, even across
+// the resonances of the inner track.
+type NotchTrack struct {
+	inner      Track
+	centers    *envelope
+	bandwidths *envelope
+	depths     *envelope
+}
+
+// NewNotchTrack creates a NotchTrack which filters inner.
+// The depth ranges from 0, which leaves the inner track unchanged, to 1.
+func NewNotchTrack(inner Track, center, bandwidth, depth float64) *NotchTrack {
+	res := &NotchTrack{
+		inner:      inner,
+		centers:    newEnvelope(center),
+		bandwidths: newEnvelope(bandwidth),
+		depths:     newEnvelope(depth),
+	}
+	res.sync()
+	return res
+}
+
+// Inner returns the track being filtered.
+func (n *NotchTrack) Inner() Track {
+	return n.inner
+}
+
+// Center returns the center frequency of the notch at the end of the track.
+func (n *NotchTrack) Center() float64 {
+	return n.centers.Value()
+}
+
+// Bandwidth returns the bandwidth of the notch, in Hz, at the end of the track.
+func (n *NotchTrack) Bandwidth() float64 {
+	return n.bandwidths.Value()
+}
+
+// Depth returns the depth of the notch at the end of the track.
+func (n *NotchTrack) Depth() float64 {
+	return n.depths.Value()
+}
+
+func (n *NotchTrack) Duration() time.Duration {
+	return n.inner.Duration()
+}
+
+func (n *NotchTrack) Encode(sampleRate int) []wav.Sample {
+	res := n.inner.Encode(sampleRate)
+	centers := n.centers.Values(sampleRate, len(res))
+	bandwidths := n.bandwidths.Values(sampleRate, len(res))
+	depths := n.depths.Values(sampleRate, len(res))
+
+	filter := &biquad{}
+	var lastCenter, lastBandwidth float64
+	for i, sample := range res {
+		center, bandwidth := centers[i], bandwidths[i]
+		if i == 0 || center != lastCenter || bandwidth != lastBandwidth {
+			if bandwidth < minNotchBandwidth {
+				bandwidth = minNotchBandwidth
+			}
+			filter.configure(BandPassFilter, center, center/bandwidth, sampleRate)
+			lastCenter, lastBandwidth = centers[i], bandwidths[i]
+		}
+		// The band-pass runs even while the notch is off, so it has settled when the notch
+		// fades in.
+		band := filter.process(float64(sample))
+		if depths[i] != 0 {
+			res[i] = wav.Sample(float64(sample) - depths[i]*band)
+		}
+	}
+	return res
+}
+
+func (n *NotchTrack) Continue(d time.Duration) {
+	n.sync()
+	n.inner.Continue(d)
+	n.continueEnvelopes(d)
+}
+
+func (n *NotchTrack) Volume() float64 {
+	return n.inner.Volume()
+}
+
+func (n *NotchTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	n.sync()
+	n.inner.AdjustVolume(newVolume, d)
+	n.continueEnvelopes(d)
+}
+
+func (n *NotchTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	n.sync()
+	AdjustVolumeCurve(n.inner, newVolume, d, curve)
+	n.continueEnvelopes(d)
+}
+
+// AdjustNotch elongates the track while gliding the center, bandwidth, and depth of the notch.
+func (n *NotchTrack) AdjustNotch(center, bandwidth, depth float64, transition time.Duration) {
+	n.sync()
+	n.inner.Continue(transition)
+	n.centers.Adjust(center, transition)
+	n.bandwidths.Adjust(bandwidth, transition)
+	n.depths.Adjust(depth, transition)
+}
+
+// GlideNotch glides the center, bandwidth, and depth of the notch over the last part of the
+// track, without elongating it.
+//
+// This lays the notch over time by which the inner track was elongated directly.
+// If the notch has already been adjusted over some of that time, the glide only covers the
+// rest, and a zero duration sets the notch immediately.
+func (n *NotchTrack) GlideNotch(center, bandwidth, depth float64, d time.Duration) {
+	start := n.inner.Duration() - d
+	if gap := start - n.centers.Duration(); gap > 0 {
+		n.continueEnvelopes(gap)
+	}
+	remaining := n.inner.Duration() - n.centers.Duration()
+	if remaining < 0 {
+		remaining = 0
+	}
+	n.centers.Adjust(center, remaining)
+	n.bandwidths.Adjust(bandwidth, remaining)
+	n.depths.Adjust(depth, remaining)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (n *NotchTrack) Clone() Track {
+	inner := cloneTrack(n.inner)
+	if inner == nil {
+		return nil
+	}
+	return &NotchTrack{
+		inner:      inner,
+		centers:    n.centers.clone(),
+		bandwidths: n.bandwidths.clone(),
+		depths:     n.depths.clone(),
+	}
+}
+
+func (n *NotchTrack) continueEnvelopes(d time.Duration) {
+	n.centers.Continue(d)
+	n.bandwidths.Continue(d)
+	n.depths.Continue(d)
+}
+
+// sync holds the notch over any time the inner track was elongated by without going through the
+// NotchTrack.
+func (n *NotchTrack) sync() {
+	if d := n.inner.Duration() - n.centers.Duration(); d > 0 {
+		n.continueEnvelopes(d)
+	}
+}
@@ -0,0 +1,97 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNotchTrackDip(t *testing.T) {
+	const sampleRate = 16000
+	for _, center := range []float64{1000, 1700, 3000} {
+		noise := NewNoiseTrack(WhiteNoise, 0.3, 1)
+		noise.Continue(time.Second * 2)
+		plain := averageSpectrum(noise.Encode(sampleRate), sampleRate)
+		notched := NewNotchTrack(noise, center, center/4, 1)
+		spectrum := averageSpectrum(notched.Encode(sampleRate), sampleRate)
+
+		dip := bandPower(spectrum, sampleRate, center*0.97, center*1.03) /
+			bandPower(plain, sampleRate, center*0.97, center*1.03)
+		if dip > 0.05 {
+			t.Errorf("%f Hz: expected a deep dip at the center but kept %f of the power", center,
+				dip)
+		}
+		for _, far := range []float64{center / 3, math.Min(center*2.5, 7000)} {
+			kept := bandPower(spectrum, sampleRate, far*0.95, far*1.05) /
+				bandPower(plain, sampleRate, far*0.95, far*1.05)
+			if kept < 0.7 {
+				t.Errorf("%f Hz: expected %f Hz to pass but kept %f of the power", center, far,
+					kept)
+			}
+		}
+	}
+
+	// A notch with no depth leaves the inner track untouched.
+	noise := NewNoiseTrack(WhiteNoise, 0.3, 1)
+	noise.Continue(time.Millisecond * 300)
+	assertSamplesClose(t, noise.Encode(sampleRate),
+		NewNotchTrack(noise, 1000, 250, 0).Encode(sampleRate), 0)
+}
+
+func TestNotchTrackAdjust(t *testing.T) {
+	const sampleRate = 16000
+	noise := NewNoiseTrack(WhiteNoise, 0.3, 1)
+	noise.Continue(time.Second)
+	notched := NewNotchTrack(noise, 1000, 250, 1)
+	notched.AdjustNotch(3000, 750, 1, time.Millisecond*500)
+	notched.Continue(time.Second)
+	if d := notched.Duration(); d != time.Millisecond*2500 {
+		t.Fatalf("expected duration 2.5s but got %v", d)
+	}
+	if c, b := notched.Center(), notched.Bandwidth(); c != 3000 || b != 750 {
+		t.Errorf("expected the notch to end at 3000 Hz wide 750 Hz, but got %f and %f", c, b)
+	}
+	samples := notched.Encode(sampleRate)
+	head := averageSpectrum(samples[:sampleRate], sampleRate)
+	tail := averageSpectrum(samples[len(samples)-sampleRate:], sampleRate)
+	if bandPower(head, sampleRate, 950, 1050)*10 > bandPower(head, sampleRate, 2900, 3100) {
+		t.Error("expected the start of the track to be cut at 1000 Hz")
+	}
+	if bandPower(tail, sampleRate, 2900, 3100)*10 > bandPower(tail, sampleRate, 950, 1050) {
+		t.Error("expected the end of the track to be cut at 3000 Hz")
+	}
+
+	// A glide lays the notch over time that was already added, without elongating the track.
+	notched.GlideNotch(1500, 400, 0.5, time.Millisecond*200)
+	if d := notched.Duration(); d != time.Millisecond*2500 {
+		t.Errorf("expected the glide to keep the duration 2.5s but got %v", d)
+	}
+	if c, b, depth := notched.Center(), notched.Bandwidth(), notched.Depth(); c != 1500 ||
+		b != 400 || depth != 0.5 {
+		t.Errorf("unexpected notch after the glide: %f, %f, %f", c, b, depth)
+	}
+}
+
+func TestNotchTrackCrossesTone(t *testing.T) {
+	const sampleRate = 16000
+	tones := TrackSet{
+		"low":  NewToneTrack(1000, 0.4, 0),
+		"high": NewToneTrack(2000, 0.4, 0),
+	}
+	tones.Continue(time.Millisecond * 100)
+	notched := NewNotchTrack(tones, 300, 75, 1)
+	notched.AdjustNotch(3500, 100, 1, time.Second)
+	notched.AdjustNotch(300, 75, 1, time.Second)
+	samples := notched.Encode(sampleRate)
+	for i, sample := range samples {
+		if math.IsNaN(float64(sample)) || math.Abs(float64(sample)) > 1.6 {
+			t.Fatalf("sample %d: the notch became unstable with %f", i, sample)
+		}
+	}
+
+	// Sweeping across a tone briefly cuts it, and then the tone recovers.
+	levels := RMSSeries(notched, sampleRate, time.Millisecond*20)
+	if level := levels[len(levels)-3]; math.Abs(level-0.4)/0.4 > 0.1 {
+		t.Errorf("expected the tones to recover to an RMS of 0.4 but got %f", level)
+	}
+}
@@ -0,0 +1,36 @@
+package tracks
+
+import (
+	"errors"
+	"math"
+	"strconv"
+)
+
+var noteSemitones = map[byte]int{'C': 0, 'D': 2, 'E': 4, 'F': 5, 'G': 7, 'A': 9, 'B': 11}
+
+// NoteFrequency returns the equal-tempered frequency of a note name in scientific pitch
+// notation, such as "A4" (440 Hz), "C#3", or "Bb2".
+func NoteFrequency(name string) (float64, error) {
+	if len(name) < 2 {
+		return 0, errors.New("invalid note name: " + name)
+	}
+	semitone, ok := noteSemitones[name[0]]
+	if !ok {
+		return 0, errors.New("invalid note letter: " + name)
+	}
+	rest := name[1:]
+	switch rest[0] {
+	case '#':
+		semitone++
+		rest = rest[1:]
+	case 'b':
+		semitone--
+		rest = rest[1:]
+	}
+	octave, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, errors.New("invalid note octave: " + name)
+	}
+	midiNumber := 12*(octave+1) + semitone
+	return 440 * math.Pow(2, float64(midiNumber-69)/12), nil
+}
@@ -0,0 +1,46 @@
+package tracks
+
+import "math"
+
+// sineTableSize is the number of points at which sineTable samples one period of a sine.
+// With linear interpolation between them, the error of sineTurns stays below 3e-7.
+const sineTableSize = 4096
+
+// sineTable holds one period of a sine, plus the first point of the next period so that
+// interpolation never has to wrap around.
+var sineTable = newSineTable()
+
+func newSineTable() []float64 {
+	res := make([]float64, sineTableSize+1)
+	for i := range res {
+		res[i] = math.Sin(2 * math.Pi * float64(i) / sineTableSize)
+	}
+	return res
+}
+
+// sineTurns evaluates a sine at a phase in the range [0, 1), measured in turns, by linearly
+// interpolating sineTable.
+// It is much cheaper than math.Sin, which dominates the cost of encoding oscillators otherwise.
+func sineTurns(phase float64) float64 {
+	index := phase * sineTableSize
+	i := int(index)
+	if i < 0 || i >= sineTableSize {
+		return math.Sin(2 * math.Pi * phase)
+	}
+	frac := index - float64(i)
+	return sineTable[i] + (sineTable[i+1]-sineTable[i])*frac
+}
+
+// sineSeries sums the series amplitudes[k]*sin((k+1)*x) over the first count terms, given the
+// sine and cosine of x.
+// The harmonics are stepped through with the angle-addition formulas rather than evaluated one at
+// a time.
+func sineSeries(amplitudes []float64, count int, sin1, cos1 float64) float64 {
+	sinK, cosK := sin1, cos1
+	var res float64
+	for _, amp := range amplitudes[:count] {
+		res += amp * sinK
+		sinK, cosK = sinK*cos1+cosK*sin1, cosK*cos1-sinK*sin1
+	}
+	return res
+}
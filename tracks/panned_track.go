@@ -0,0 +1,123 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// EncodeStereo generates interleaved left and right samples by encoding
+// every track in the set in stereo and summing up the signals.
+//
+// Tracks which are not StereoTracks are placed in both channels at their
+// full level, so a set without any panning sounds the same as its mono
+// encoding.
+func (t TrackSet) EncodeStereo(sampleRate int) []wav.Sample {
+	ids := t.sortedIDs()
+	encodedTracks := make([][]wav.Sample, len(ids))
+	for i, id := range ids {
+		encodedTracks[i] = encodeStereo(t[id], sampleRate)
+	}
+	return mixSamples(encodedTracks)
+}
+
+// encodeStereo encodes any track as interleaved stereo samples.
+func encodeStereo(t Track, sampleRate int) []wav.Sample {
+	if st, ok := t.(StereoTrack); ok {
+		return st.EncodeStereo(sampleRate)
+	}
+	mono := t.Encode(sampleRate)
+	return interleave(mono, mono)
+}
+
+// A PannedTrack positions an inner track between the left and right channels.
+//
+// Mono inner tracks are panned with a constant-power law, so a centered PannedTrack is 3 dB
+// quieter in each channel than the unpanned track.
+// Inner tracks which are StereoTracks, such as TrackSets containing PannedTracks, keep their own
+// stereo image; the pan acts as a balance control which attenuates the opposite channel.
+//
+// When a PannedTrack is encoded in mono, the pan has no effect.
+type PannedTrack struct {
+	inner Track
+	pan   float64
+}
+
+// NewPannedTrack creates a PannedTrack.
+// The pan ranges from -1 (left) to 1 (right), and is clamped to that range.
+func NewPannedTrack(inner Track, pan float64) *PannedTrack {
+	res := &PannedTrack{inner: inner}
+	res.SetPan(pan)
+	return res
+}
+
+// Pan returns the track's position, from -1 (left) to 1 (right).
+func (p *PannedTrack) Pan() float64 {
+	return p.pan
+}
+
+// SetPan sets the track's position, from -1 (left) to 1 (right).
+func (p *PannedTrack) SetPan(pan float64) {
+	p.pan = math.Max(-1, math.Min(1, pan))
+}
+
+// Inner returns the track being panned.
+func (p *PannedTrack) Inner() Track {
+	return p.inner
+}
+
+func (p *PannedTrack) Duration() time.Duration {
+	return p.inner.Duration()
+}
+
+// Encode generates the mono encoding of the inner track.
+func (p *PannedTrack) Encode(sampleRate int) []wav.Sample {
+	return p.inner.Encode(sampleRate)
+}
+
+// EncodeStereo generates interleaved left and right samples.
+func (p *PannedTrack) EncodeStereo(sampleRate int) []wav.Sample {
+	angle := (p.pan + 1) * math.Pi / 4
+	leftGain, rightGain := math.Cos(angle), math.Sin(angle)
+
+	var res []wav.Sample
+	if st, ok := p.inner.(StereoTrack); ok {
+		res = st.EncodeStereo(sampleRate)
+		leftGain = math.Min(1, leftGain*math.Sqrt2)
+		rightGain = math.Min(1, rightGain*math.Sqrt2)
+	} else {
+		mono := p.inner.Encode(sampleRate)
+		res = interleave(mono, mono)
+	}
+	for i := 0; i+1 < len(res); i += 2 {
+		res[i] *= wav.Sample(leftGain)
+		res[i+1] *= wav.Sample(rightGain)
+	}
+	return res
+}
+
+func (p *PannedTrack) Continue(d time.Duration) {
+	p.inner.Continue(d)
+}
+
+func (p *PannedTrack) Volume() float64 {
+	return p.inner.Volume()
+}
+
+func (p *PannedTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	p.inner.AdjustVolume(newVolume, d)
+}
+
+func (p *PannedTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(p.inner, newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (p *PannedTrack) Clone() Track {
+	inner := cloneTrack(p.inner)
+	if inner == nil {
+		return nil
+	}
+	return &PannedTrack{inner: inner, pan: p.pan}
+}
@@ -0,0 +1,54 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPannedTrackConstantPower(t *testing.T) {
+	tone := NewToneTrack(440, 0.5, 0)
+	tone.Continue(time.Millisecond * 100)
+	mono := tone.Encode(8000)
+	for _, pan := range []float64{-1, -0.5, 0, 0.3, 1} {
+		panned := NewPannedTrack(tone, pan)
+		assertSamplesClose(t, mono, panned.Encode(8000), 0)
+		left, right := deinterleave(panned.EncodeStereo(8000))
+		for i, sample := range mono {
+			power := float64(left[i]*left[i] + right[i]*right[i])
+			if math.Abs(power-float64(sample*sample)) > 1e-12 {
+				t.Fatalf("pan %f: sample %d: expected power %f but got %f", pan, i,
+					sample*sample, power)
+			}
+		}
+		if pan == -1 && PeakLevel(right) > 1e-12 {
+			t.Error("expected a hard left pan to silence the right channel")
+		}
+	}
+	if pan := NewPannedTrack(tone, 3).Pan(); pan != 1 {
+		t.Errorf("expected the pan to be clamped to 1 but got %f", pan)
+	}
+}
+
+func TestTrackSetEncodeStereo(t *testing.T) {
+	a := NewToneTrack(300, 0.3, 0)
+	a.Continue(time.Millisecond * 100)
+	b := NewToneTrack(500, 0.2, 0)
+	b.Continue(time.Millisecond * 150)
+
+	// Without panning, both channels carry the mono mix.
+	plain := TrackSet{"a": a, "b": b}
+	left, right := deinterleave(plain.EncodeStereo(8000))
+	assertSamplesClose(t, plain.Encode(8000), left, 0)
+	assertSamplesClose(t, plain.Encode(8000), right, 0)
+
+	// A panned set balances the mix of its children rather than panning each of them again.
+	inner := TrackSet{"a": NewPannedTrack(a, 0), "b": NewPannedTrack(b, -1)}
+	innerLeft, innerRight := deinterleave(inner.EncodeStereo(8000))
+	outer := TrackSet{"inner": NewPannedTrack(inner, 1)}
+	left, right = deinterleave(outer.EncodeStereo(8000))
+	assertSamplesClose(t, innerRight, right, 1e-12)
+	if PeakLevel(left) > 1e-12 || PeakLevel(innerLeft) == 0 {
+		t.Error("expected a hard right balance to silence the left channel")
+	}
+}
@@ -0,0 +1,222 @@
+package tracks
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/unixpickle/wav"
+)
+
+// minParallelMixLength is the shortest mix which is worth splitting across goroutines.
+const minParallelMixLength = 1 << 16
+
+// encodeSlots holds a token for every goroutine which may help with encoding at once, across
+// every encode in the process.
+// Along with the goroutine which starts an encode, the helpers fill GOMAXPROCS.
+//
+// A set nested in a wrapper is encoded by a worker of the outer set, which cannot tell the inner
+// set about its pool, so pools take their helpers from these shared slots instead.
+// Once the slots run out, the nested set is encoded serially by the worker itself rather than
+// multiplying the goroutines with every level of nesting.
+var encodeSlots = make(chan struct{}, runtime.GOMAXPROCS(0)-1)
+
+// acquireEncodeSlots takes up to n slots without waiting, returning the number taken.
+func acquireEncodeSlots(n int) int {
+	for i := 0; i < n; i++ {
+		select {
+		case encodeSlots <- struct{}{}:
+		default:
+			return i
+		}
+	}
+	return n
+}
+
+func releaseEncodeSlot() {
+	<-encodeSlots
+}
+
+// encodeMembers encodes every track in the set, ordered by ID so that mixing the results is
+// deterministic.
+//
+// Every track in the tree of nested TrackSets is encoded by a single pool of workers, and the
+// nested sets are then mixed bottom-up in the same order a serial encode would use.
+// A track which appears in the tree more than once is only encoded once.
+func (t TrackSet) encodeMembers(sampleRate, workers int) ([]TrackID, [][]wav.Sample) {
+	jobs := &encodeJobs{byTrack: map[Track]*encodeJob{}}
+	ids := t.sortedIDs()
+	nodes := make([]*mixNode, len(ids))
+	for i, id := range ids {
+		nodes[i] = newMixNode(t[id], jobs)
+	}
+	runEncodeJobs(jobs.list, sampleRate, workers)
+
+	encodedTracks := make([][]wav.Sample, len(nodes))
+	for i, node := range nodes {
+		encodedTracks[i] = node.mix(workers)
+	}
+	return ids, encodedTracks
+}
+
+type encodeJob struct {
+	track  Track
+	result []wav.Sample
+}
+
+// encodeJobs collects the jobs for a tree of TrackSets.
+type encodeJobs struct {
+	list []*encodeJob
+
+	// byTrack finds the job of a track which is a pointer, so that a track which appears in
+	// several places is not encoded by several workers at once.
+	byTrack map[Track]*encodeJob
+}
+
+// job returns the job which encodes a track, adding one if needed.
+func (e *encodeJobs) job(t Track) *encodeJob {
+	shared := reflect.ValueOf(t).Kind() == reflect.Ptr
+	if shared {
+		if job, ok := e.byTrack[t]; ok {
+			return job
+		}
+	}
+	job := &encodeJob{track: t}
+	e.list = append(e.list, job)
+	if shared {
+		e.byTrack[t] = job
+	}
+	return job
+}
+
+// runEncodeJobs encodes the jobs on the calling goroutine, helped by up to workers-1 goroutines
+// which are available in encodeSlots.
+func runEncodeJobs(jobs []*encodeJob, sampleRate, workers int) {
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+	helpers := 0
+	if workers > 1 {
+		helpers = acquireEncodeSlots(workers - 1)
+	}
+	if helpers == 0 {
+		for _, job := range jobs {
+			job.result = job.track.Encode(sampleRate)
+		}
+		return
+	}
+
+	jobChan := make(chan *encodeJob, len(jobs))
+	for _, job := range jobs {
+		jobChan <- job
+	}
+	close(jobChan)
+
+	work := func() {
+		for job := range jobChan {
+			job.result = job.track.Encode(sampleRate)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < helpers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer releaseEncodeSlot()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+}
+
+// A mixNode mirrors the structure of a tree of TrackSets.
+// Leaf nodes wait on an encodeJob, which several leaves may share, and the other nodes mix their
+// children.
+type mixNode struct {
+	job      *encodeJob
+	children []*mixNode
+}
+
+func newMixNode(t Track, jobs *encodeJobs) *mixNode {
+	set, ok := t.(TrackSet)
+	if !ok {
+		return &mixNode{job: jobs.job(t)}
+	}
+	res := &mixNode{}
+	for _, id := range set.sortedIDs() {
+		res.children = append(res.children, newMixNode(set[id], jobs))
+	}
+	return res
+}
+
+func (m *mixNode) mix(workers int) []wav.Sample {
+	if m.job != nil {
+		return m.job.result
+	}
+	encodedTracks := make([][]wav.Sample, len(m.children))
+	for i, child := range m.children {
+		encodedTracks[i] = child.mix(workers)
+	}
+	return mixSamplesWorkers(encodedTracks, workers)
+}
+
+// mixSamples sums up signals of possibly different lengths.
+// The result is as long as the longest signal.
+func mixSamples(encodedTracks [][]wav.Sample) []wav.Sample {
+	return mixSamplesWorkers(encodedTracks, 1)
+}
+
+// mixSamplesWorkers is like mixSamples, but it splits long mixes into sample ranges which are
+// summed concurrently, by the calling goroutine and helpers from encodeSlots.
+func mixSamplesWorkers(encodedTracks [][]wav.Sample, workers int) []wav.Sample {
+	sampleCount := 0
+	for _, enc := range encodedTracks {
+		if len(enc) > sampleCount {
+			sampleCount = len(enc)
+		}
+	}
+	res := make([]wav.Sample, sampleCount)
+
+	chunks := 1
+	if workers > 1 && sampleCount >= minParallelMixLength {
+		chunks = 1 + acquireEncodeSlots(workers-1)
+	}
+	if chunks == 1 {
+		mixRange(res, encodedTracks, 0, sampleCount)
+		return res
+	}
+
+	var wg sync.WaitGroup
+	chunkSize := (sampleCount + chunks - 1) / chunks
+	for i := 1; i < chunks; i++ {
+		start, end := i*chunkSize, (i+1)*chunkSize
+		if start >= sampleCount {
+			releaseEncodeSlot()
+			continue
+		}
+		if end > sampleCount {
+			end = sampleCount
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			defer releaseEncodeSlot()
+			mixRange(res, encodedTracks, start, end)
+		}(start, end)
+	}
+	mixRange(res, encodedTracks, 0, chunkSize)
+	wg.Wait()
+	return res
+}
+
+func mixRange(res []wav.Sample, encodedTracks [][]wav.Sample, start, end int) {
+	for i := start; i < end; i++ {
+		for _, enc := range encodedTracks {
+			if i >= len(enc) {
+				continue
+			}
+			res[i] += enc[i]
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package tracks
+
+import (
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func testEncodeSet() TrackSet {
+	shared := NewToneTrack(500, 0.1, 20)
+	shared.AdjustFrequency(250, time.Millisecond*400)
+	set := TrackSet{"shared": shared}
+	for i := 0; i < 12; i++ {
+		tone := NewToneTrack(100*float64(i+1), 0.05, float64(i))
+		tone.SetSeed(int64(i))
+		tone.AdjustVolume(0.01, time.Millisecond*time.Duration(100+30*i))
+		set[TrackID(rune('a'+i))] = tone
+	}
+	noise := NewNoiseTrack(PinkNoise, 0.1, 4)
+	noise.Continue(time.Millisecond * 300)
+	set["nested"] = TrackSet{
+		"noise":  noise,
+		"shared": shared,
+		"deeper": TrackSet{"shared": shared, "tone": NewToneTrack(700, 0.1, 0)},
+	}
+	set["weighted"] = NewWeightedTrackSet(TrackSet{"shared": shared})
+	return set
+}
+
+func TestEncodeWorkersMatchesSerial(t *testing.T) {
+	serial := testEncodeSet().EncodeWorkers(44100, 1)
+	for _, workers := range []int{2, 3, 8, 32} {
+		parallel := testEncodeSet().EncodeWorkers(44100, workers)
+		assertSamplesClose(t, serial, parallel, 0)
+	}
+
+	// Encoding the same set again, in parallel, reuses the tones' cursors.
+	set := testEncodeSet()
+	set.EncodeWorkers(44100, 8)
+	assertSamplesClose(t, serial, set.EncodeWorkers(44100, 8), 0)
+}
+
+func TestEncodeMembersSharesJobs(t *testing.T) {
+	set := testEncodeSet()
+	jobs := &encodeJobs{byTrack: map[Track]*encodeJob{}}
+	for _, id := range set.sortedIDs() {
+		newMixNode(set[id], jobs)
+	}
+	// The shared tone appears four times but is encoded once, while the weighted set is a job
+	// of its own.
+	if expected := 12 + 1 + 2 + 1; len(jobs.list) != expected {
+		t.Errorf("expected %d jobs but got %d", expected, len(jobs.list))
+	}
+}
+
+func TestEncodeWithStemsSharedTrack(t *testing.T) {
+	tone := NewToneTrack(440, 0.2, 0)
+	tone.Continue(time.Millisecond * 100)
+	mix, stems := TrackSet{"a": tone, "b": tone}.EncodeWithStems(8000)
+	if &stems["a"][0] == &stems["b"][0] {
+		t.Fatal("stems of a shared track should not alias")
+	}
+	for i, sample := range mix {
+		if sample != stems["a"][i]+stems["b"][i] {
+			t.Fatalf("sample %d: mix %v is not the sum of the stems", i, sample)
+		}
+	}
+}
+
+func TestEncodeNestedSharesSlots(t *testing.T) {
+	oldSlots := encodeSlots
+	encodeSlots = make(chan struct{}, 3)
+	defer func() {
+		encodeSlots = oldSlots
+	}()
+
+	// Every inner set is encoded by a worker of the outer set, since it is wrapped.
+	var active, maxActive int32
+	set := TrackSet{}
+	for i := 0; i < 6; i++ {
+		inner := TrackSet{}
+		for j := 0; j < 6; j++ {
+			inner[TrackID(strconv.Itoa(j))] = &concurrencyTrack{
+				SilenceTrack: NewSilenceTrack(time.Millisecond),
+				active:       &active,
+				maxActive:    &maxActive,
+			}
+		}
+		set[TrackID(strconv.Itoa(i))] = NewWeightedTrackSet(inner)
+	}
+	set.EncodeWorkers(8000, 8)
+	if maxActive > 4 {
+		t.Errorf("expected at most 4 concurrent encodes, but got %d", maxActive)
+	}
+	if n := len(encodeSlots); n != 0 {
+		t.Errorf("expected every slot to be released, but %d are taken", n)
+	}
+}
+
+// A concurrencyTrack records the largest number of its kind being encoded at once.
+type concurrencyTrack struct {
+	*SilenceTrack
+	active    *int32
+	maxActive *int32
+}
+
+func (c *concurrencyTrack) Encode(sampleRate int) []wav.Sample {
+	n := atomic.AddInt32(c.active, 1)
+	defer atomic.AddInt32(c.active, -1)
+	for {
+		max := atomic.LoadInt32(c.maxActive)
+		if n <= max || atomic.CompareAndSwapInt32(c.maxActive, max, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	return c.SilenceTrack.Encode(sampleRate)
+}
+
+func BenchmarkTrackSet(b *testing.B) {
+	set := TrackSet{}
+	for i := 0; i < 8; i++ {
+		tone := NewToneTrack(200*float64(i+1), 0.1, 10)
+		tone.AdjustFrequency(150*float64(i+1), time.Second)
+		set[TrackID(rune('a'+i))] = tone
+	}
+	for _, bench := range []struct {
+		name    string
+		workers int
+	}{
+		{"workers=1", 1},
+		{"workers=GOMAXPROCS", runtime.GOMAXPROCS(0)},
+	} {
+		workers := bench.workers
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				clone, err := set.Clone()
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.StartTimer()
+				clone.EncodeWorkers(44100, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package tracks
+
+import (
+	"errors"
+	"strings"
+)
+
+// PathSeparator separates the TrackIDs in a path through nested TrackSets.
+const PathSeparator = "/"
+
+// Lookup finds a track in nested TrackSets by a path of TrackIDs, such as "voice1/formants/f2".
+//
+// Since TrackIDs may contain the separator themselves, each set first tries to match the whole
+// remaining path as a TrackID, and then shorter and shorter prefixes ending before a separator.
+// The first prefix which names a track wins, so "a/b" is found as a literal ID before it is
+// found as track "b" inside set "a".
+//
+// It returns false if no track matches or if the path passes through a track which is not a
+// TrackSet.
+func (t TrackSet) Lookup(path string) (Track, bool) {
+	id, rest, ok := t.matchPath(path)
+	if !ok {
+		return nil, false
+	}
+	if rest == "" {
+		return t[id], true
+	}
+	child, ok := t[id].(TrackSet)
+	if !ok {
+		return nil, false
+	}
+	return child.Lookup(rest)
+}
+
+// SetPath stores a track in nested TrackSets by a path of TrackIDs.
+//
+// The path is resolved like in Lookup.
+// If no prefix of the path names an existing track, the first segment becomes a new TrackSet,
+// so missing intermediate sets are created.
+// It fails if the path contains an empty segment or passes through a track which is not a
+// TrackSet.
+func (t TrackSet) SetPath(path string, track Track) error {
+	for _, segment := range strings.Split(path, PathSeparator) {
+		if segment == "" {
+			return errors.New("invalid track path: " + path)
+		}
+	}
+	return t.setPath(path, path, track)
+}
+
+func (t TrackSet) setPath(fullPath, path string, track Track) error {
+	id, rest, ok := t.matchPath(path)
+	if !ok {
+		parts := strings.SplitN(path, PathSeparator, 2)
+		if len(parts) == 1 {
+			t[TrackID(path)] = track
+			return nil
+		}
+		id, rest = TrackID(parts[0]), parts[1]
+		t[id] = TrackSet{}
+	}
+	if rest == "" {
+		t[id] = track
+		return nil
+	}
+	child, ok := t[id].(TrackSet)
+	if !ok {
+		parent := fullPath[:len(fullPath)-len(rest)-len(PathSeparator)]
+		return errors.New("not a TrackSet: " + parent)
+	}
+	return child.setPath(fullPath, rest, track)
+}
+
+// matchPath finds the longest prefix of a path, ending at a separator or at the end of the path,
+// which names a track in the set.
+// It returns the matched ID and the rest of the path after the separator.
+func (t TrackSet) matchPath(path string) (id TrackID, rest string, ok bool) {
+	end := len(path)
+	for {
+		if _, ok := t[TrackID(path[:end])]; ok {
+			if end == len(path) {
+				return TrackID(path), "", true
+			}
+			return TrackID(path[:end]), path[end+len(PathSeparator):], true
+		}
+		end = strings.LastIndex(path[:end], PathSeparator)
+		if end < 0 {
+			return "", "", false
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLookupNested(t *testing.T) {
+	f2 := NewToneTrack(1500, 0.2, 0)
+	literal := NewToneTrack(2500, 0.2, 0)
+	inner := NewToneTrack(800, 0.2, 0)
+	breath := NewNoiseTrack(WhiteNoise, 0.1, 1)
+	set := TrackSet{
+		"voice1": TrackSet{
+			"formants": TrackSet{
+				"f2":   f2,
+				"f2/x": literal,
+			},
+			"formants/f2": inner,
+			"breath":      breath,
+		},
+	}
+
+	for _, test := range []struct {
+		path     string
+		expected Track
+	}{
+		{"voice1/breath", breath},
+		{"voice1/formants/f2", inner},
+	} {
+		actual, ok := set.Lookup(test.path)
+		if !ok || actual != test.expected {
+			t.Errorf("%q: expected %v but got %v (%v)", test.path, test.expected, actual, ok)
+		}
+	}
+	formants, ok := set.Lookup("voice1/formants")
+	if !ok || formants.(TrackSet)["f2"] != f2 {
+		t.Fatalf("expected to find the formants set, but got %v (%v)", formants, ok)
+	}
+	if actual, ok := formants.(TrackSet).Lookup("f2/x"); !ok || actual != literal {
+		t.Errorf("expected to find the literal ID f2/x, but got %v (%v)", actual, ok)
+	}
+
+	// The literal ID "formants/f2" in voice1 wins over the path through its formants set, so
+	// the tracks of that set which start with "f2" are shadowed.
+	if actual, ok := set.Lookup("voice1/formants/f2/x"); ok {
+		t.Errorf("expected f2/x to be shadowed but got %v", actual)
+	}
+	for _, path := range []string{"", "voice2", "voice1/formants/f3", "voice1/breath/x",
+		"voice1/formants/f2/x/y", "voice1//breath"} {
+		if track, ok := set.Lookup(path); ok {
+			t.Errorf("%q: expected no track but got %v", path, track)
+		}
+	}
+}
+
+func TestSetPath(t *testing.T) {
+	set := TrackSet{"voice1": TrackSet{"breath": NewNoiseTrack(WhiteNoise, 0.1, 1)}}
+	tone := NewToneTrack(440, 0.3, 0)
+	tone.Continue(time.Millisecond * 10)
+	if err := set.SetPath("voice1/formants/f2", tone); err != nil {
+		t.Fatal(err)
+	}
+	formants, ok := set["voice1"].(TrackSet)["formants"].(TrackSet)
+	if !ok || formants["f2"] != tone {
+		t.Fatalf("expected the missing formants set to be created, but got %v", set)
+	}
+	if actual, ok := set.Lookup("voice1/formants/f2"); !ok || actual != tone {
+		t.Errorf("expected to look up the track which was set, but got %v", actual)
+	}
+
+	// Replacing a track through a path keeps its siblings.
+	other := NewToneTrack(880, 0.3, 0)
+	if err := set.SetPath("voice1/formants/f2", other); err != nil {
+		t.Fatal(err)
+	}
+	if formants["f2"] != other || len(set["voice1"].(TrackSet)) != 2 {
+		t.Errorf("expected f2 to be replaced in place, but got %v", set)
+	}
+
+	// A literal ID is replaced rather than shadowed by a new path.
+	set["voice2/f1"] = tone
+	if err := set.SetPath("voice2/f1", other); err != nil {
+		t.Fatal(err)
+	}
+	if set["voice2/f1"] != other {
+		t.Errorf("expected the literal ID to be replaced, but got %v", set)
+	}
+	if _, ok := set["voice2"]; ok {
+		t.Errorf("expected no set to be created for a literal ID")
+	}
+
+	for _, test := range []struct {
+		path string
+		err  string
+	}{
+		{"voice1/breath/x", "not a TrackSet: voice1/breath"},
+		{"voice1/formants/f2/x", "not a TrackSet: voice1/formants/f2"},
+		{"voice1//x", "invalid track path: voice1//x"},
+		{"", "invalid track path: "},
+		{"voice1/", "invalid track path: voice1/"},
+	} {
+		if err := set.SetPath(test.path, tone); err == nil || err.Error() != test.err {
+			t.Errorf("%q: expected error %q but got %v", test.path, test.err, err)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package tracks
+
+import (
+	"math"
+
+	"github.com/unixpickle/wav"
+)
+
+// EncodePCM16 encodes a track as signed 16-bit PCM.
+//
+// A sample of 1 becomes math.MaxInt16 and a sample of -1 becomes -math.MaxInt16, so the scale is
+// symmetric.
+// Samples beyond that range are clamped rather than wrapped around.
+func EncodePCM16(t Track, sampleRate int) []int16 {
+	return samplesToPCM16(t.Encode(sampleRate))
+}
+
+// EncodePCM16Stereo is like EncodePCM16, but produces interleaved stereo samples.
+// Tracks which are not StereoTracks are duplicated into both channels.
+func EncodePCM16Stereo(t Track, sampleRate int) []int16 {
+	return samplesToPCM16(encodeStereo(t, sampleRate))
+}
+
+// EncodeFloat64 encodes a track as float64 samples.
+// The samples are not clamped, so they may exceed [-1, 1] if the track is too loud.
+func EncodeFloat64(t Track, sampleRate int) []float64 {
+	return samplesToFloat64(t.Encode(sampleRate))
+}
+
+// EncodeFloat64Stereo is like EncodeFloat64, but produces interleaved stereo samples.
+// Tracks which are not StereoTracks are duplicated into both channels.
+func EncodeFloat64Stereo(t Track, sampleRate int) []float64 {
+	return samplesToFloat64(encodeStereo(t, sampleRate))
+}
+
+// PCM16ToSamples converts signed 16-bit PCM back into samples.
+// It inverts EncodePCM16, so -math.MaxInt16 becomes -1, and math.MinInt16 is clamped to -1.
+func PCM16ToSamples(pcm []int16) []wav.Sample {
+	res := make([]wav.Sample, len(pcm))
+	for i, x := range pcm {
+		res[i] = wav.Sample(math.Max(-1, float64(x)/math.MaxInt16))
+	}
+	return res
+}
+
+func samplesToPCM16(samples []wav.Sample) []int16 {
+	res := make([]int16, len(samples))
+	for i, sample := range samples {
+		res[i] = pcm16Value(sample)
+	}
+	return res
+}
+
+func samplesToFloat64(samples []wav.Sample) []float64 {
+	res := make([]float64, len(samples))
+	for i, sample := range samples {
+		res[i] = float64(sample)
+	}
+	return res
+}
+
+// pcm16Value converts a sample to signed 16-bit PCM.
+func pcm16Value(sample wav.Sample) int16 {
+	return int16(math.Round(clampSample(sample) * math.MaxInt16))
+}
+
+// clampSample clamps a sample to the range [-1, 1].
+// NaN samples, which a broken track might produce, become silence.
+func clampSample(sample wav.Sample) float64 {
+	value := float64(sample)
+	if math.IsNaN(value) {
+		return 0
+	}
+	return math.Max(-1, math.Min(1, value))
+}
@@ -0,0 +1,102 @@
+package tracks
+
+import (
+	"io"
+
+	"github.com/unixpickle/wav"
+)
+
+// A pcmReader renders a track into little-endian PCM as it is read.
+type pcmReader struct {
+	track Track
+	opts  EncodeOptions
+	err   error
+
+	stream SampleStream
+
+	// copies is the number of output samples made from each sample of the stream, which is 2
+	// when a mono stream is duplicated into stereo.
+	copies int
+
+	// remaining is the number of output samples left to produce.
+	remaining int
+	samples   []wav.Sample
+	pending   []byte
+}
+
+// NewPCMReader creates an io.Reader which produces a track as little-endian PCM, like the data of
+// a WAV file written by WriteWAV.
+//
+// The track is rendered in chunks as the reader is read, so the track should not be modified
+// until the reader reaches io.EOF.
+// Streaming tracks keep only a chunk in memory at a time, but StereoTracks are encoded in full at
+// the first read in stereo, since stereo encoding is not streamed.
+// Unsupported formats produce an error on the first read.
+func NewPCMReader(t Track, sampleRate, bitDepth, channels int) io.Reader {
+	opts := EncodeOptions{SampleRate: sampleRate, BitDepth: bitDepth, Channels: channels}
+	return &pcmReader{track: t, opts: opts, err: opts.validate()}
+}
+
+func (p *pcmReader) Read(buf []byte) (int, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	if p.stream == nil {
+		p.start()
+	}
+	var n int
+	for n < len(buf) {
+		if len(p.pending) == 0 && !p.render() {
+			break
+		}
+		copied := copy(buf[n:], p.pending)
+		p.pending = p.pending[copied:]
+		n += copied
+	}
+	if n == 0 && len(buf) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (p *pcmReader) start() {
+	rate := p.opts.SampleRate
+	p.remaining = sampleCount(p.track.Duration(), rate) * p.opts.Channels
+	if _, ok := p.track.(StereoTrack); ok && p.opts.Channels == 2 {
+		p.stream = &sliceStream{samples: encodeStereo(p.track, rate)}
+		p.copies = 1
+	} else {
+		p.stream = Stream(p.track, rate)
+		p.copies = p.opts.Channels
+	}
+	p.samples = make([]wav.Sample, streamChunkSize)
+}
+
+// render converts the next chunk of the stream into pending bytes, returning false once the
+// track has been exhausted.
+func (p *pcmReader) render() bool {
+	if p.remaining == 0 {
+		return false
+	}
+	chunk := p.samples
+	if len(chunk) > p.remaining/p.copies {
+		chunk = chunk[:p.remaining/p.copies]
+	}
+
+	// Like WriteWAV, the stream is padded so that the output matches the track's duration.
+	n := p.stream.Read(chunk)
+	for i := n; i < len(chunk); i++ {
+		chunk[i] = 0
+	}
+
+	bytesPerSample := p.opts.BitDepth / 8
+	data := make([]byte, len(chunk)*p.copies*bytesPerSample)
+	for i, sample := range chunk {
+		for j := 0; j < p.copies; j++ {
+			putPCMSample(data[(i*p.copies+j)*bytesPerSample:], sample, p.opts.BitDepth)
+		}
+	}
+	p.pending = data
+	p.remaining -= len(chunk) * p.copies
+	return true
+}
@@ -0,0 +1,113 @@
+package tracks
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestPCMReaderMatchesWAV(t *testing.T) {
+	tone := NewToneTrack(220, 0.4, 0)
+	tone.AdjustFrequency(440, time.Millisecond*700)
+	noise := NewNoiseTrack(WhiteNoise, 0.1, 3)
+	noise.Continue(time.Millisecond * 500)
+	set := TrackSet{"tone": tone, "noise": noise}
+	panned := NewPannedTrack(tone, 0.5)
+
+	for _, test := range []struct {
+		track Track
+		opts  EncodeOptions
+	}{
+		{set, EncodeOptions{SampleRate: 16000, BitDepth: 16, Channels: 1}},
+		{set, EncodeOptions{SampleRate: 8000, BitDepth: 8, Channels: 2}},
+		{set, EncodeOptions{SampleRate: 11025, BitDepth: 24, Channels: 1}},
+		{panned, EncodeOptions{SampleRate: 8000, BitDepth: 16, Channels: 2}},
+	} {
+		var file bytes.Buffer
+		if err := WriteWAV(test.track, &file, test.opts); err != nil {
+			t.Fatal(err)
+		}
+		size := SampleCount(test.track.Duration(), test.opts.SampleRate) * test.opts.Channels *
+			test.opts.BitDepth / 8
+		expected := file.Bytes()[44 : 44+size]
+
+		for _, bufSize := range []int{1, 3, 7, 1001, 1 << 20} {
+			reader := NewPCMReader(test.track, test.opts.SampleRate, test.opts.BitDepth,
+				test.opts.Channels)
+			var actual []byte
+			buf := make([]byte, bufSize)
+			for {
+				n, err := reader.Read(buf)
+				actual = append(actual, buf[:n]...)
+				if err == io.EOF {
+					break
+				} else if err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(actual, expected) {
+				t.Errorf("%v, buffer %d: expected the %d bytes of WriteWAV, but got %d other bytes",
+					test.opts, bufSize, len(expected), len(actual))
+			}
+			if n, err := reader.Read(buf); n != 0 || err != io.EOF {
+				t.Errorf("%v: expected io.EOF after the end but got %d, %v", test.opts, n, err)
+			}
+		}
+	}
+}
+
+func TestPCMReaderLazy(t *testing.T) {
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.Continue(time.Second * 10)
+	counted := &countingTrack{ToneTrack: tone}
+	reader := NewPCMReader(counted, 16000, 16, 1)
+	if _, err := reader.Read(make([]byte, 3)); err != nil {
+		t.Fatal(err)
+	}
+	if counted.read > streamChunkSize {
+		t.Errorf("expected at most a chunk to be rendered, but %d samples were", counted.read)
+	}
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		t.Fatal(err)
+	}
+	if counted.read != 160000 {
+		t.Errorf("expected the whole track to be rendered in the end, but got %d samples",
+			counted.read)
+	}
+}
+
+func TestPCMReaderInvalidFormat(t *testing.T) {
+	for _, reader := range []io.Reader{
+		NewPCMReader(NewSilenceTrack(time.Second), 0, 16, 1),
+		NewPCMReader(NewSilenceTrack(time.Second), 8000, 12, 1),
+		NewPCMReader(NewSilenceTrack(time.Second), 8000, 16, 3),
+	} {
+		if n, err := reader.Read(make([]byte, 16)); n != 0 || err == nil || err == io.EOF {
+			t.Errorf("expected a format error but got %d, %v", n, err)
+		}
+	}
+}
+
+// A countingTrack counts the samples read from its streams.
+type countingTrack struct {
+	*ToneTrack
+	read int
+}
+
+func (c *countingTrack) Stream(sampleRate int) SampleStream {
+	return &countingStream{inner: c.ToneTrack.Stream(sampleRate), track: c}
+}
+
+type countingStream struct {
+	inner SampleStream
+	track *countingTrack
+}
+
+func (c *countingStream) Read(buf []wav.Sample) int {
+	n := c.inner.Read(buf)
+	c.track.read += n
+	return n
+}
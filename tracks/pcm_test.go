@@ -0,0 +1,88 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestEncodePCM16Clamp(t *testing.T) {
+	samples := []wav.Sample{
+		1, -1, 0, 0.5, -0.5, 1.00001, -1.00001, 2, -3,
+		wav.Sample(math.NaN()), wav.Sample(math.Inf(1)), wav.Sample(math.Inf(-1)),
+	}
+	expected := []int16{
+		math.MaxInt16, -math.MaxInt16, 0, 16384, -16384, math.MaxInt16, -math.MaxInt16,
+		math.MaxInt16, -math.MaxInt16, 0, math.MaxInt16, -math.MaxInt16,
+	}
+	actual := EncodePCM16(NewSampleTrack(samples, 8000), 8000)
+	if len(actual) != len(expected) {
+		t.Fatalf("expected %d samples but got %d", len(expected), len(actual))
+	}
+	for i, x := range expected {
+		if actual[i] != x {
+			t.Errorf("sample %d (%f): expected %d but got %d", i, samples[i], x, actual[i])
+		}
+	}
+
+	// The float encoding is not clamped.
+	floats := EncodeFloat64(NewSampleTrack(samples[:9], 8000), 8000)
+	for i, x := range floats {
+		if x != float64(samples[i]) {
+			t.Errorf("sample %d: expected %f but got %f", i, samples[i], x)
+		}
+	}
+
+	back := PCM16ToSamples([]int16{math.MinInt16, -math.MaxInt16, math.MaxInt16})
+	if back[0] != -1 || back[1] != -1 || back[2] != 1 {
+		t.Errorf("expected full scale PCM to become -1, -1 and 1, but got %v", back)
+	}
+}
+
+func TestEncodePCM16RoundTrip(t *testing.T) {
+	tone := NewToneTrack(441, 0.9, 0)
+	tone.Continue(time.Millisecond * 200)
+	expected := tone.Encode(16000)
+	pcm := EncodePCM16(tone, 16000)
+	if len(pcm) != len(expected) {
+		t.Fatalf("expected %d samples but got %d", len(expected), len(pcm))
+	}
+	assertSamplesClose(t, expected, PCM16ToSamples(pcm), 1.0/math.MaxInt16)
+
+	// Every value survives a round trip through samples exactly.
+	all := make([]int16, 0, 1<<16)
+	for x := -math.MaxInt16; x <= math.MaxInt16; x++ {
+		all = append(all, int16(x))
+	}
+	again := EncodePCM16(NewSampleTrack(PCM16ToSamples(all), 8000), 8000)
+	for i, x := range all {
+		if again[i] != x {
+			t.Fatalf("expected %d to survive a round trip, but got %d", x, again[i])
+		}
+	}
+}
+
+func TestEncodePCM16Stereo(t *testing.T) {
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.Continue(time.Millisecond * 50)
+	mono := EncodePCM16(tone, 8000)
+	stereo := EncodePCM16Stereo(tone, 8000)
+	if len(stereo) != len(mono)*2 {
+		t.Fatalf("expected %d samples but got %d", len(mono)*2, len(stereo))
+	}
+	for i, x := range mono {
+		if stereo[2*i] != x || stereo[2*i+1] != x {
+			t.Fatalf("sample %d: expected %d in both channels but got %d and %d", i, x,
+				stereo[2*i], stereo[2*i+1])
+		}
+	}
+
+	left := EncodeFloat64Stereo(NewPannedTrack(tone, -1), 8000)
+	for i := 1; i < len(left); i += 2 {
+		if math.Abs(left[i]) > 1e-6 {
+			t.Fatalf("sample %d: expected silence on the right but got %f", i, left[i])
+		}
+	}
+}
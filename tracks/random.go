@@ -0,0 +1,121 @@
+package tracks
+
+import (
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"strconv"
+)
+
+// perturbationCutoff is the cutoff frequency, in Hz, of the noise which drives jitter and
+// shimmer.
+const perturbationCutoff = 40.0
+
+// A seededSource is a rand.Source which counts the values it generates, so that it can be
+// cloned by replaying its seed.
+type seededSource struct {
+	seed   int64
+	calls  int
+	source rand.Source
+}
+
+func newSeededSource(seed int64) *seededSource {
+	return &seededSource{seed: seed, source: rand.NewSource(seed)}
+}
+
+func (s *seededSource) Int63() int64 {
+	s.calls++
+	return s.source.Int63()
+}
+
+func (s *seededSource) Seed(seed int64) {
+	s.seed = seed
+	s.calls = 0
+	s.source.Seed(seed)
+}
+
+// clone creates a source in the same state as s.
+func (s *seededSource) clone() *seededSource {
+	res := newSeededSource(s.seed)
+	for res.calls < s.calls {
+		res.Int63()
+	}
+	return res
+}
+
+// A lowPassNoise generates Gaussian noise through a one-pole low-pass filter.
+// The noise is scaled to a standard deviation of 1, and it starts in its steady state.
+type lowPassNoise struct {
+	random *rand.Rand
+	coeff  float64
+	scale  float64
+	value  float64
+}
+
+func newLowPassNoise(cutoff float64, sampleRate int, random *rand.Rand) *lowPassNoise {
+	coeff := 1 - math.Exp(-2*math.Pi*cutoff/float64(sampleRate))
+	return &lowPassNoise{
+		random: random,
+		coeff:  coeff,
+		scale:  math.Sqrt((2 - coeff) / coeff),
+		value:  random.NormFloat64(),
+	}
+}
+
+func (l *lowPassNoise) next() float64 {
+	l.value += l.coeff * (l.random.NormFloat64()*l.scale - l.value)
+	return l.value
+}
+
+// A SeededTrack is a Track whose randomness is generated from a seed, so that it encodes to the
+// same samples every time.
+type SeededTrack interface {
+	Track
+
+	Seed() int64
+	SetSeed(seed int64)
+}
+
+// SetSeed seeds every SeededTrack in the set, recursing into nested TrackSets and the inner
+// tracks of wrappers.
+//
+// Each track gets its own seed, derived from seed and the track's path in the set, so tracks do
+// not repeat each other's noise, and renders with the same seed are identical.
+func (t TrackSet) SetSeed(seed int64) {
+	for id, track := range t {
+		seedTrack(track, seed, string(id))
+	}
+}
+
+func seedTrack(t Track, seed int64, path string) {
+	switch t := t.(type) {
+	case TrackSet:
+		for id, track := range t {
+			seedTrack(track, seed, path+PathSeparator+string(id))
+		}
+	case *SyncTrackSet:
+		t.lock.Lock()
+		defer t.lock.Unlock()
+		seedTrack(t.set, seed, path)
+	case *WeightedTrackSet:
+		seedTrack(t.Tracks(), seed, path)
+	case *ConcatTrack:
+		for i, piece := range t.pieces {
+			seedTrack(piece, seed, path+PathSeparator+strconv.Itoa(i))
+		}
+	case *CrossfadeTrack:
+		seedTrack(t.first, seed, path+PathSeparator+"first")
+		seedTrack(t.second, seed, path+PathSeparator+"second")
+	case SeededTrack:
+		hash := fnv.New64a()
+		hash.Write([]byte(path))
+		t.SetSeed(seed ^ int64(hash.Sum64()))
+
+		// A seeded wrapper, such as a FreezeTrack, may wrap randomness of its own.
+		if wrapper, ok := t.(interface{ Inner() Track }); ok {
+			seedTrack(wrapper.Inner(), seed, path+PathSeparator+"inner")
+		}
+	case interface{ Inner() Track }:
+		seedTrack(t.Inner(), seed, path)
+	}
+}
@@ -0,0 +1,97 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestSetSeedWrappers(t *testing.T) {
+	wrappers := map[string]func(inner Track) Track{
+		"conditioned": func(inner Track) Track {
+			return NewConditionedTrack(inner, DefaultDCCutoff)
+		},
+		"crossfade": func(inner Track) Track {
+			return Crossfade(NewSilenceTrack(time.Millisecond*10), inner, time.Millisecond*5)
+		},
+		"concat": func(inner Track) Track {
+			return Concat(NewSilenceTrack(time.Millisecond*10), inner)
+		},
+		"fade":   func(inner Track) Track { return FadeIn(inner, time.Millisecond*10) },
+		"filter": func(inner Track) Track { return NewFilterTrack(inner, LowPassFilter, 1000, 1) },
+		"freeze": func(inner Track) Track {
+			return NewFreezeTrack(inner, time.Millisecond*10, time.Millisecond*20)
+		},
+		"loop": func(inner Track) Track {
+			loop, err := NewLoopTrack(inner, 0, time.Millisecond*10, time.Millisecond*20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return loop
+		},
+		"midside": func(inner Track) Track {
+			return NewMidSideTrack(NewPannedTrack(inner, 0.5))
+		},
+		"modulated": func(inner Track) Track {
+			return NewModulatedTrack(inner, 5, 0.5, SineModulation)
+		},
+		"monobass": func(inner Track) Track {
+			return NewMonoBassTrack(NewPannedTrack(inner, 0.5), 120)
+		},
+		"notch":     func(inner Track) Track { return NewNotchTrack(inner, 1000, 100, 0.5) },
+		"panned":    func(inner Track) Track { return NewPannedTrack(inner, -0.5) },
+		"slice":     func(inner Track) Track { return Slice(inner, 0, time.Millisecond*10) },
+		"sync":      func(inner Track) Track { return NewSyncTrackSet(TrackSet{"noise": inner}) },
+		"transient": func(inner Track) Track { return NewTransientShaperTrack(inner, 1, 1) },
+		"varispeed": func(inner Track) Track { return NewVarispeedTrack(inner, 1.5) },
+		"weighted": func(inner Track) Track {
+			return NewWeightedTrackSet(TrackSet{"noise": inner})
+		},
+		"nested set": func(inner Track) Track { return TrackSet{"noise": inner} },
+	}
+	for name, wrap := range wrappers {
+		first := NewNoiseTrack(WhiteNoise, 0.1, 0)
+		second := NewNoiseTrack(WhiteNoise, 0.1, 0)
+		first.Continue(time.Millisecond * 30)
+		second.Continue(time.Millisecond * 30)
+		set := TrackSet{"a": wrap(first), "b": wrap(second)}
+		set.SetSeed(7)
+		if first.Seed() == 0 || second.Seed() == 0 {
+			t.Errorf("%s: noise was not seeded", name)
+		} else if first.Seed() == second.Seed() {
+			t.Errorf("%s: wrapped noise tracks got the same seed", name)
+		}
+	}
+}
+
+func TestSetSeedDeterministic(t *testing.T) {
+	makeSet := func() TrackSet {
+		noise := NewNoiseTrack(PinkNoise, 0.1, 0)
+		noise.Continue(time.Millisecond * 50)
+		tone := NewToneTrack(440, 0.1, 50)
+		tone.Continue(time.Millisecond * 50)
+		return TrackSet{"noise": FadeIn(noise, time.Millisecond*10), "tone": tone}
+	}
+	first, second := makeSet(), makeSet()
+	first.SetSeed(3)
+	second.SetSeed(3)
+	assertSamplesClose(t, first.Encode(8000), second.Encode(8000), 0)
+
+	second.SetSeed(4)
+	if samplesEqual(first.Encode(8000), second.Encode(8000)) {
+		t.Error("different seeds should produce different noise")
+	}
+}
+
+func samplesEqual(a, b []wav.Sample) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package tracks
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// sampleTrackTail is the length of the end of a recording which SampleTrack.Volume measures.
+const sampleTrackTail = time.Millisecond * 50
+
+// A SampleTrack plays recorded audio, such as a music bed or a recorded interjection, so that it
+// can be mixed with synthesized tracks.
+//
+// The recording is resampled when it is encoded at a rate other than its own.
+// Continuing a SampleTrack extends it with silence, or with repetitions of the end of the
+// recording if a loop is set, and AdjustVolume ramps the gain of that continued material.
+type SampleTrack struct {
+	samples    []wav.Sample
+	sampleRate int
+	loop       time.Duration
+	gain       *envelope
+}
+
+// NewSampleTrack creates a SampleTrack which plays mono samples recorded at the given sample
+// rate, which must be positive.
+// The samples should not be modified after this.
+func NewSampleTrack(samples []wav.Sample, sampleRate int) *SampleTrack {
+	return &SampleTrack{samples: samples, sampleRate: sampleRate, gain: newEnvelope(1)}
+}
+
+// NewSampleTrackSound creates a SampleTrack from a sound, mixing its channels down to mono.
+func NewSampleTrackSound(s wav.Sound) (*SampleTrack, error) {
+	if s.SampleRate() <= 0 {
+		return nil, errors.New("invalid sample rate: " + strconv.Itoa(s.SampleRate()))
+	}
+	channels := s.Channels()
+	if channels <= 0 {
+		return nil, errors.New("invalid channel count: " + strconv.Itoa(channels))
+	}
+	interleaved := s.Samples()
+	mono := make([]wav.Sample, len(interleaved)/channels)
+	for i := range mono {
+		var sum wav.Sample
+		for _, sample := range interleaved[i*channels : (i+1)*channels] {
+			sum += sample
+		}
+		mono[i] = sum / wav.Sample(channels)
+	}
+	return NewSampleTrack(mono, s.SampleRate()), nil
+}
+
+// ReadSampleTrack reads a WAV file into a SampleTrack, as in NewSampleTrackSound.
+func ReadSampleTrack(r io.Reader) (*SampleTrack, error) {
+	sound, err := wav.ReadSound(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewSampleTrackSound(sound)
+}
+
+// LoadSampleTrack reads a WAV file from a path into a SampleTrack, as in NewSampleTrackSound.
+func LoadSampleTrack(path string) (*SampleTrack, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSampleTrack(f)
+}
+
+// SampleRate returns the sample rate of the recording.
+func (s *SampleTrack) SampleRate() int {
+	return s.sampleRate
+}
+
+// Loop returns the length of the end of the recording which is repeated while the track is
+// continued, or 0 if it is continued with silence.
+func (s *SampleTrack) Loop() time.Duration {
+	return s.loop
+}
+
+// SetLoop sets the length of the end of the recording which is repeated while the track is
+// continued, including any material it has been continued with already.
+// A loop of 0 continues the track with silence, and a loop longer than the recording repeats
+// all of it.
+//
+// The end of the loop is crossfaded into the samples just before it, as in LoopTrack, so the
+// end of the recording itself changes slightly once the track has been continued.
+func (s *SampleTrack) SetLoop(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	s.loop = d
+}
+
+// Duration returns the length of the recording plus the material it was continued with.
+func (s *SampleTrack) Duration() time.Duration {
+	return s.recordingDuration() + s.gain.Duration()
+}
+
+func (s *SampleTrack) recordingDuration() time.Duration {
+	return sampleTime(len(s.samples), s.sampleRate)
+}
+
+// Encode renders the recording and its continuation, resampled to the given rate.
+// Nothing is rendered if either sample rate is not positive.
+func (s *SampleTrack) Encode(sampleRate int) []wav.Sample {
+	native := s.encodeNative()
+	if sampleRate != s.sampleRate {
+		var err error
+		native, err = Resample(native, s.sampleRate, sampleRate)
+		if err != nil {
+			return nil
+		}
+	}
+	res := make([]wav.Sample, sampleCount(s.Duration(), sampleRate))
+	copy(res, native)
+	return res
+}
+
+// encodeNative renders the recording and its continuation at the recording's sample rate.
+func (s *SampleTrack) encodeNative() []wav.Sample {
+	res := make([]wav.Sample, sampleCount(s.Duration(), s.sampleRate))
+	copy(res, s.samples)
+	extension := len(res) - len(s.samples)
+	loopStart := s.loopStart()
+	if extension <= 0 || loopStart == len(s.samples) {
+		return res
+	}
+
+	loop := crossfadedLoop(s.samples, loopStart, len(s.samples), s.sampleRate)
+	copy(res[loopStart:], loop)
+	gains := s.gain.Values(s.sampleRate, extension)
+	for i, gain := range gains {
+		res[len(s.samples)+i] = loop[(len(s.samples)-loopStart+i)%len(loop)] * wav.Sample(gain)
+	}
+	return res
+}
+
+// loopStart returns the index of the first sample of the loop, which is the length of the
+// recording if there is no loop.
+func (s *SampleTrack) loopStart() int {
+	if s.loop == 0 {
+		return len(s.samples)
+	}
+	start := len(s.samples) - sampleCount(s.loop, s.sampleRate)
+	if start < 0 {
+		start = 0
+	}
+	return start
+}
+
+// Continue elongates the track with silence or repetitions of its loop.
+func (s *SampleTrack) Continue(d time.Duration) {
+	s.gain.Continue(d)
+}
+
+// Volume returns the volume at the end of the track, as the amplitude of a sine with the same
+// RMS level.
+//
+// Before the track is continued, this measures the end of the recording.
+// Afterwards, it measures the loop, scaled by the current gain, and it is 0 for a track which
+// is continued with silence.
+func (s *SampleTrack) Volume() float64 {
+	if s.gain.Duration() == 0 {
+		start := len(s.samples) - sampleCount(sampleTrackTail, s.sampleRate)
+		if start < 0 {
+			start = 0
+		}
+		return RMSLevel(s.samples[start:]) * math.Sqrt2
+	}
+	return s.loopVolume() * s.gain.Value()
+}
+
+// loopVolume returns the volume of the continued material at a gain of 1.
+func (s *SampleTrack) loopVolume() float64 {
+	return RMSLevel(s.samples[s.loopStart():]) * math.Sqrt2
+}
+
+// AdjustVolume elongates the track while ramping the gain of the continued material so that
+// its Volume reaches newVolume.
+// A track which continues with silence is simply elongated.
+func (s *SampleTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	s.gain.Adjust(s.targetGain(newVolume), d)
+}
+
+// AdjustVolumeCurve is like AdjustVolume, but the gain follows the given curve.
+func (s *SampleTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	s.gain.AdjustCurve(s.targetGain(newVolume), d, curve)
+}
+
+func (s *SampleTrack) targetGain(newVolume float64) float64 {
+	if volume := s.loopVolume(); volume > 0 {
+		return newVolume / volume
+	}
+	return s.gain.Value()
+}
+
+// Clone creates a copy of the track which can be continued independently.
+// The copy shares the recording, which is never modified.
+func (s *SampleTrack) Clone() Track {
+	return &SampleTrack{
+		samples:    s.samples,
+		sampleRate: s.sampleRate,
+		loop:       s.loop,
+		gain:       s.gain.clone(),
+	}
+}
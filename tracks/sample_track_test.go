@@ -0,0 +1,133 @@
+package tracks
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestSampleTrackRead(t *testing.T) {
+	const sampleRate = 8000
+	left := NewToneTrack(300, 0.4, 0)
+	left.Continue(time.Millisecond * 50)
+	right := NewToneTrack(500, 0.2, 0)
+	right.Continue(time.Millisecond * 50)
+	sound := wav.NewPCM16Sound(2, sampleRate)
+	leftSamples, rightSamples := left.Encode(sampleRate), right.Encode(sampleRate)
+	sound.SetSamples(interleave(leftSamples, rightSamples))
+
+	// Both channels are mixed down to mono.
+	expected := make([]wav.Sample, len(leftSamples))
+	for i := range expected {
+		expected[i] = (leftSamples[i] + rightSamples[i]) / 2
+	}
+	var buf bytes.Buffer
+	if err := sound.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSampleTrack(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "recording.wav")
+	if err := wav.WriteFile(sound, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSampleTrack(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, track := range []*SampleTrack{read, loaded} {
+		if track.SampleRate() != sampleRate || track.Duration() != time.Millisecond*50 {
+			t.Errorf("expected 50ms at %d Hz but got %s at %d Hz", sampleRate, track.Duration(),
+				track.SampleRate())
+		}
+		assertSamplesClose(t, expected, track.Encode(sampleRate), 1.0/32767)
+	}
+
+	if _, err := NewSampleTrackSound(wav.NewPCM16Sound(1, 0)); err == nil ||
+		err.Error() != "invalid sample rate: 0" {
+		t.Errorf("expected an invalid sample rate error but got %v", err)
+	}
+	if _, err := LoadSampleTrack(filepath.Join(t.TempDir(), "missing.wav")); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestSampleTrackMix(t *testing.T) {
+	const nativeRate = 8000
+	recorded := NewToneTrack(200, 0.3, 0)
+	recorded.Continue(time.Millisecond * 100)
+	recording := NewSampleTrack(recorded.Encode(nativeRate), nativeRate)
+	tone := NewToneTrack(700, 0.2, 0)
+	tone.Continue(time.Millisecond * 100)
+	set := TrackSet{"recording": recording, "tone": tone}
+	set.Continue(time.Millisecond * 50)
+	if d := recording.Duration(); d != time.Millisecond*150 {
+		t.Fatalf("expected the recording to be continued to 150ms, but got %s", d)
+	}
+	if v := recording.Volume(); v != 0 {
+		t.Errorf("expected silence after the recording, but got a volume of %f", v)
+	}
+
+	for _, sampleRate := range []int{nativeRate, 16000, 22050} {
+		mix := set.Encode(sampleRate)
+		if n := SampleCount(time.Millisecond*150, sampleRate); len(mix) != n {
+			t.Fatalf("%d Hz: expected %d samples but got %d", sampleRate, n, len(mix))
+		}
+		// The recording is resampled along with its silent continuation.
+		silence := make([]wav.Sample, SampleCount(time.Millisecond*50, nativeRate))
+		expected := append(recorded.Encode(nativeRate), silence...)
+		if sampleRate != nativeRate {
+			var err error
+			expected, err = Resample(expected, nativeRate, sampleRate)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		for i, sample := range tone.Encode(sampleRate) {
+			expected[i] += sample
+		}
+		assertSamplesClose(t, expected, mix, 1e-6)
+	}
+}
+
+func TestSampleTrackLoop(t *testing.T) {
+	const sampleRate = 8000
+	tone := NewToneTrack(250, 0.5, 0)
+	tone.Continue(time.Millisecond * 100)
+	recording := NewSampleTrack(tone.Encode(sampleRate), sampleRate)
+	recording.SetLoop(time.Millisecond * 40)
+	if v := recording.Volume(); math.Abs(v-0.5) > 0.01 {
+		t.Errorf("expected the recording to end at a volume of 0.5 but got %f", v)
+	}
+	recording.Continue(time.Millisecond * 150)
+	clone := recording.Clone().(*SampleTrack)
+
+	// The continuation repeats the last 40ms over and over.
+	samples := recording.Encode(sampleRate)
+	period := SampleCount(time.Millisecond*40, sampleRate)
+	for i := SampleCount(time.Millisecond*100, sampleRate); i < len(samples); i++ {
+		if samples[i] != samples[i-period] {
+			t.Fatalf("sample %d: expected %f but got %f", i, samples[i-period], samples[i])
+		}
+	}
+
+	recording.AdjustVolume(0.1, time.Millisecond*50)
+	recording.Continue(time.Millisecond * 40)
+	if v := recording.Volume(); math.Abs(v-0.1) > 0.01 {
+		t.Errorf("expected a volume of 0.1 but got %f", v)
+	}
+	tail := recording.Encode(sampleRate)[len(samples):]
+	if level := RMSLevel(tail[len(tail)-period:]) * math.Sqrt2; math.Abs(level-0.1) > 0.01 {
+		t.Errorf("expected the end of the fade to measure 0.1 but got %f", level)
+	}
+	if clone.Duration() != time.Millisecond*250 {
+		t.Errorf("expected the clone to keep its duration of 250ms, but got %s", clone.Duration())
+	}
+	assertSamplesClose(t, samples, clone.Encode(sampleRate), 0)
+}
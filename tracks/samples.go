@@ -0,0 +1,70 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// sampleTime returns the timestamp of the sample at the given index, computed the same way the
+// concrete track types compute it while encoding.
+func sampleTime(index, sampleRate int) time.Duration {
+	secondsElapsed := float64(index) / float64(sampleRate)
+	return time.Duration(float64(time.Second) * secondsElapsed)
+}
+
+// sampleCount returns the number of samples a track of the given duration encodes to.
+//
+// This is the number of sample timestamps before d.
+// Tracks must derive their lengths from their total durations with this function, rather than
+// rounding each part of a track separately, so that parallel tracks stay sample-aligned no
+// matter how many times they are continued.
+func sampleCount(d time.Duration, sampleRate int) int {
+	n := int(d.Seconds() * float64(sampleRate))
+	for n > 0 && sampleTime(n-1, sampleRate) >= d {
+		n--
+	}
+	for sampleTime(n, sampleRate) < d {
+		n++
+	}
+	return n
+}
+
+// SampleCount returns the number of samples a track of the given duration encodes to at a
+// sample rate.
+// It is also the index of the sample at which something starting at d is first heard.
+func SampleCount(d time.Duration, sampleRate int) int {
+	return sampleCount(d, sampleRate)
+}
+
+// wrapPhase wraps a phase, measured in turns, into the range [0, 1).
+func wrapPhase(phase float64) float64 {
+	phase -= math.Floor(phase)
+	if phase >= 1 {
+		phase = 0
+	}
+	return phase
+}
+
+// interleave combines a left and right channel into interleaved stereo samples.
+// The channels must have the same length.
+func interleave(left, right []wav.Sample) []wav.Sample {
+	res := make([]wav.Sample, len(left)*2)
+	for i := range left {
+		res[i*2] = left[i]
+		res[i*2+1] = right[i]
+	}
+	return res
+}
+
+// deinterleave splits interleaved stereo samples into left and right channels.
+func deinterleave(samples []wav.Sample) (left, right []wav.Sample) {
+	left = make([]wav.Sample, len(samples)/2)
+	right = make([]wav.Sample, len(samples)/2)
+	for i := range left {
+		left[i] = samples[i*2]
+		right[i] = samples[i*2+1]
+	}
+	return
+}
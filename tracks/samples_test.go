@@ -0,0 +1,63 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContinueSampleAligned(t *testing.T) {
+	const step = time.Microsecond * 3333
+	tone := NewToneTrack(440, 0.3, 0)
+	noise := NewNoiseTrack(WhiteNoise, 0.1, 1)
+	saw := NewSawtoothTrack(110, 2)
+	silence := NewSilenceTrack(0)
+	set := TrackSet{"tone": tone, "noise": noise}
+	for i := 0; i < 1000; i++ {
+		set.Continue(step)
+		saw.Continue(step)
+		silence.Continue(step)
+	}
+	total := step * 1000
+	for _, sampleRate := range []int{8000, 22050, 44100, 48000} {
+		expected := SampleCount(total, sampleRate)
+		for name, track := range map[string]Track{
+			"tone":    tone,
+			"noise":   noise,
+			"saw":     saw,
+			"silence": silence,
+			"set":     set,
+		} {
+			if track.Duration() != total {
+				t.Errorf("%s: expected a duration of %s but got %s", name, total,
+					track.Duration())
+			}
+			if n := len(track.Encode(sampleRate)); n != expected {
+				t.Errorf("%s at %d Hz: expected %d samples but got %d", name, sampleRate,
+					expected, n)
+			}
+		}
+	}
+}
+
+func TestSliceSampleAligned(t *testing.T) {
+	const step = time.Microsecond * 3333
+	tone := NewToneTrack(440, 0.3, 0)
+	tone.Continue(step * 100)
+	for _, sampleRate := range []int{8000, 22050, 44100} {
+		// Slices encode to the length of their duration wherever they start in the source.
+		for i := 0; i < 100; i++ {
+			slice := Slice(tone, step*time.Duration(i), step*time.Duration(i+1))
+			n := len(slice.Encode(sampleRate))
+			if n != SampleCount(slice.Duration(), sampleRate) {
+				t.Fatalf("slice %d at %d Hz: expected %d samples but got %d", i, sampleRate,
+					SampleCount(slice.Duration(), sampleRate), n)
+			}
+		}
+
+		fade := Crossfade(Slice(tone, 0, step*60), Slice(tone, step*40, step*100), step*20)
+		if n := len(fade.Encode(sampleRate)); n != SampleCount(fade.Duration(), sampleRate) {
+			t.Errorf("%d Hz: expected the crossfade to cover %d samples but got %d", sampleRate,
+				SampleCount(fade.Duration(), sampleRate), n)
+		}
+	}
+}
@@ -57,6 +57,7 @@ func (s *SawtoothParameters) powerForFrequency(freq float64) float64 {
 type SawtoothTrack struct {
 	fundamentalFrequency float64
 	amplitudeScale       float64
+	initialPhase         float64
 	parts                []*sawtoothTrackPart
 }
 
@@ -81,6 +82,14 @@ func NewSawtoothTrack(fundFreq float64, formantCount int) *SawtoothTrack {
 	}
 }
 
+// NewSawtoothTrackPhase is like NewSawtoothTrack, but the fundamental starts at the given
+// phase, in turns.
+func NewSawtoothTrackPhase(fundFreq float64, formantCount int, phase float64) *SawtoothTrack {
+	res := NewSawtoothTrack(fundFreq, formantCount)
+	res.SetInitialPhase(phase)
+	return res
+}
+
 func (s *SawtoothTrack) Duration() (duration time.Duration) {
 	for _, part := range s.parts {
 		duration += part.duration
@@ -89,38 +98,75 @@ func (s *SawtoothTrack) Duration() (duration time.Duration) {
 }
 
 func (s *SawtoothTrack) Encode(sampleRate int) []wav.Sample {
-	duration := s.Duration()
-	var partStartTime time.Duration
-	var partIndex int
-
-	res := []wav.Sample{}
-	tempParameters := NewSawtoothParameters(len(s.lastPart().end.Formants))
-	for {
-		secondsElapsed := float64(len(res)) / float64(sampleRate)
-		currentTime := time.Duration(float64(time.Second) * secondsElapsed)
-		if currentTime >= duration {
-			break
-		}
-
-		for currentTime >= partStartTime+s.parts[partIndex].duration {
-			partStartTime += s.parts[partIndex].duration
-			partIndex++
-		}
+	return readStream(s.Stream(sampleRate), sampleCount(s.Duration(), sampleRate))
+}
 
-		part := s.parts[partIndex]
-		part.parametersAtTime(tempParameters, currentTime-partStartTime)
-		sample := s.sample(tempParameters, secondsElapsed)
-		res = append(res, wav.Sample(sample))
+// Stream returns a SampleStream which generates the wave incrementally.
+func (s *SawtoothTrack) Stream(sampleRate int) SampleStream {
+	return &sawtoothStream{
+		track:          s,
+		sampleRate:     sampleRate,
+		duration:       s.Duration(),
+		tempParameters: NewSawtoothParameters(len(s.lastPart().end.Formants)),
 	}
+}
 
+// InitialPhase returns the phase of the fundamental at the start of the track, in turns.
+func (s *SawtoothTrack) InitialPhase() float64 {
+	return s.initialPhase
+}
+
+// SetInitialPhase sets the phase of the fundamental at the start of the track, in turns.
+// The phase is wrapped into the range [0, 1).
+// Each harmonic is offset by the same amount of time as the fundamental, so the shape of the
+// wave is preserved.
+func (s *SawtoothTrack) SetInitialPhase(phase float64) {
+	s.initialPhase = wrapPhase(phase)
+}
+
+// Clone creates a copy of the wave which can be adjusted independently.
+func (s *SawtoothTrack) Clone() Track {
+	res := &SawtoothTrack{
+		fundamentalFrequency: s.fundamentalFrequency,
+		amplitudeScale:       s.amplitudeScale,
+		initialPhase:         s.initialPhase,
+		parts:                make([]*sawtoothTrackPart, len(s.parts)),
+	}
+	for i, part := range s.parts {
+		res.parts[i] = &sawtoothTrackPart{
+			duration:    part.duration,
+			start:       part.start.Copy(),
+			end:         part.end.Copy(),
+			volumeCurve: part.volumeCurve,
+		}
+	}
 	return res
 }
 
+// Continue elongates the wave with its current parameters.
+func (s *SawtoothTrack) Continue(d time.Duration) {
+	s.AdjustParameters(s.lastPart().end, d)
+}
+
 // Volume returns the volume of the current parameters.
 func (s *SawtoothTrack) Volume() float64 {
 	return s.lastPart().end.Volume
 }
 
+// RMSRatio returns the RMS level of the current parameters at a volume of 1.
+// Harmonics above the Nyquist frequency fold back into the spectrum without losing power, so
+// they are counted too.
+func (s *SawtoothTrack) RMSRatio(sampleRate int) float64 {
+	params := s.lastPart().end
+	var power float64
+	for i := 1; i <= sawtoothHarmonicCount; i++ {
+		freq := float64(i) * s.fundamentalFrequency
+		amp := params.powerForFrequency(freq) / freq
+		power += amp * amp
+	}
+	return s.amplitudeScale * math.Sqrt(power/2)
+}
+
 // AdjustVolume elongates the track while adjusting its volume parameter.
 func (s *SawtoothTrack) AdjustVolume(volume float64, d time.Duration) {
 	newParams := s.Parameters()
@@ -128,6 +174,12 @@ func (s *SawtoothTrack) AdjustVolume(volume float64, d time.Duration) {
 	s.AdjustParameters(newParams, d)
 }
 
+// AdjustVolumeCurve is like AdjustVolume, but the volume follows the given curve.
+func (s *SawtoothTrack) AdjustVolumeCurve(volume float64, d time.Duration, curve Curve) {
+	s.AdjustVolume(volume, d)
+	s.lastPart().volumeCurve = curve
+}
+
 // Parameters returns a copy of the current parameters.
 func (s *SawtoothTrack) Parameters() *SawtoothParameters {
 	return s.lastPart().end.Copy()
@@ -148,12 +200,17 @@ func (s *SawtoothTrack) lastPart() *sawtoothTrackPart {
 }
 
 func (s *SawtoothTrack) sample(params *SawtoothParameters, time float64) float64 {
+	// The harmonics are stepped through with the angle-addition formulas, starting from the
+	// phase of the fundamental.
+	sin1, cos1 := math.Sincos(math.Pi * 2 * wrapPhase(s.fundamentalFrequency*time+s.initialPhase))
+	sinK, cosK := sin1, cos1
 	var res float64
 	for i := 1; i <= sawtoothHarmonicCount; i++ {
 		freq := float64(i) * s.fundamentalFrequency
-		sinValue := (1 / freq) * math.Sin(math.Pi*2*freq*time)
+		sinValue := (1 / freq) * sinK
 		power := params.Volume * params.powerForFrequency(freq)
 		res += power * sinValue
+		sinK, cosK = sinK*cos1+cosK*sin1, cosK*cos1-sinK*sin1
 	}
 	return res * s.amplitudeScale
 }
@@ -162,13 +219,49 @@ type sawtoothTrackPart struct {
 	duration time.Duration
 	start    *SawtoothParameters
 	end      *SawtoothParameters
+
+	// volumeCurve shapes the volume transition, which is linear if this is nil.
+	volumeCurve Curve
 }
 
 func (s *sawtoothTrackPart) parametersAtTime(out *SawtoothParameters, t time.Duration) {
 	fracDone := float64(t) / float64(s.duration)
-	out.Volume = fracDone*s.end.Volume + (1-fracDone)*s.start.Volume
+	out.Volume = evaluateCurve(s.volumeCurve, s.start.Volume, s.end.Volume, fracDone)
 	out.Strength = fracDone*s.end.Strength + (1-fracDone)*s.start.Strength
 	for i := range out.Formants {
 		out.Formants[i] = fracDone*s.end.Formants[i] + (1-fracDone)*s.start.Formants[i]
 	}
 }
+
+type sawtoothStream struct {
+	track          *SawtoothTrack
+	sampleRate     int
+	duration       time.Duration
+	tempParameters *SawtoothParameters
+
+	partStartTime time.Duration
+	partIndex     int
+	sampleIndex   int
+}
+
+func (s *sawtoothStream) Read(buf []wav.Sample) int {
+	parts := s.track.parts
+	for i := range buf {
+		secondsElapsed := float64(s.sampleIndex) / float64(s.sampleRate)
+		currentTime := time.Duration(float64(time.Second) * secondsElapsed)
+		if currentTime >= s.duration {
+			return i
+		}
+
+		for currentTime >= s.partStartTime+parts[s.partIndex].duration {
+			s.partStartTime += parts[s.partIndex].duration
+			s.partIndex++
+		}
+
+		part := parts[s.partIndex]
+		part.parametersAtTime(s.tempParameters, currentTime-s.partStartTime)
+		buf[i] = wav.Sample(s.track.sample(s.tempParameters, secondsElapsed))
+		s.sampleIndex++
+	}
+	return len(buf)
+}
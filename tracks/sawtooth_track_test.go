@@ -0,0 +1,34 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestSawtoothTrackPhaseOffset(t *testing.T) {
+	const sampleRate = 8000
+	newWave := func(phase float64) *SawtoothTrack {
+		res := NewSawtoothTrackPhase(100, 1, phase)
+		res.AdjustVolume(0.5, 0)
+		res.Continue(time.Millisecond * 500)
+		return res
+	}
+	wave := newWave(0)
+	samples := wave.Encode(sampleRate)
+
+	mixed := TrackSet{"first": wave, "second": newWave(0)}.Encode(sampleRate)
+	doubled := make([]wav.Sample, len(samples))
+	for i, sample := range samples {
+		doubled[i] = sample * 2
+	}
+	assertSamplesClose(t, doubled, mixed, 1e-9)
+
+	// Half a period of the 100 Hz fundamental is 40 samples, and every harmonic shifts with it.
+	shifted := newWave(0.5).Encode(sampleRate)
+	assertSamplesClose(t, samples[40:], shifted[:len(shifted)-40], 1e-9)
+	if clone := newWave(0.5).Clone().(*SawtoothTrack); clone.InitialPhase() != 0.5 {
+		t.Errorf("expected the clone to keep phase 0.5 but got %f", clone.InitialPhase())
+	}
+}
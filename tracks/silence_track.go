@@ -0,0 +1,65 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A SilenceTrack is a track which produces nothing but silence, such as a pause between words.
+type SilenceTrack struct {
+	duration time.Duration
+}
+
+// NewSilenceTrack creates a SilenceTrack with the given duration.
+func NewSilenceTrack(d time.Duration) *SilenceTrack {
+	return &SilenceTrack{duration: d}
+}
+
+func (s *SilenceTrack) Duration() time.Duration {
+	return s.duration
+}
+
+// Encode generates as many zero samples as any other track of the same duration would.
+func (s *SilenceTrack) Encode(sampleRate int) []wav.Sample {
+	return make([]wav.Sample, sampleCount(s.duration, sampleRate))
+}
+
+// Stream returns a SampleStream which generates the silence incrementally.
+func (s *SilenceTrack) Stream(sampleRate int) SampleStream {
+	return &silenceStream{remaining: sampleCount(s.duration, sampleRate)}
+}
+
+func (s *SilenceTrack) Continue(d time.Duration) {
+	s.duration += d
+}
+
+// Volume always returns 0.
+func (s *SilenceTrack) Volume() float64 {
+	return 0
+}
+
+// AdjustVolume elongates the silence, ignoring the new volume.
+func (s *SilenceTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	s.duration += d
+}
+
+func (s *SilenceTrack) Clone() Track {
+	return &SilenceTrack{duration: s.duration}
+}
+
+type silenceStream struct {
+	remaining int
+}
+
+func (s *silenceStream) Read(buf []wav.Sample) int {
+	n := len(buf)
+	if n > s.remaining {
+		n = s.remaining
+	}
+	for i := range buf[:n] {
+		buf[i] = 0
+	}
+	s.remaining -= n
+	return n
+}
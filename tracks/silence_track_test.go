@@ -0,0 +1,54 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSilenceTrack(t *testing.T) {
+	silence := NewSilenceTrack(time.Millisecond * 10)
+	silence.Continue(time.Millisecond * 5)
+	silence.AdjustVolume(0.7, time.Millisecond*5)
+	if d := silence.Duration(); d != time.Millisecond*20 {
+		t.Errorf("expected duration 20ms but got %v", d)
+	}
+	if v := silence.Volume(); v != 0 {
+		t.Errorf("expected volume 0 but got %f", v)
+	}
+	for _, sampleRate := range []int{8000, 22050, 44100} {
+		samples := silence.Encode(sampleRate)
+		if len(samples) != sampleCount(silence.Duration(), sampleRate) {
+			t.Errorf("expected %d samples but got %d", sampleCount(silence.Duration(), sampleRate),
+				len(samples))
+		}
+		for i, sample := range samples {
+			if sample != 0 {
+				t.Fatalf("sample %d: expected silence but got %f", i, sample)
+			}
+		}
+		assertSamplesClose(t, samples, readStream(silence.Stream(sampleRate), 0), 0)
+	}
+}
+
+func TestSilenceTrackAlignment(t *testing.T) {
+	// A pause of a fractional number of samples between two phones produces the same number of
+	// samples as a tone of the same length.
+	pause := time.Microsecond * 3333
+	tone := NewToneTrack(440, 0.5, 0)
+	tone.Continue(time.Millisecond*100 + pause)
+	sequence := Concat(NewSilenceTrack(time.Millisecond*100), NewSilenceTrack(pause))
+	for _, sampleRate := range []int{8000, 22050, 44100} {
+		if a, b := len(tone.Encode(sampleRate)), len(sequence.Encode(sampleRate)); a != b {
+			t.Errorf("%d Hz: tone has %d samples but the silences have %d", sampleRate, a, b)
+		}
+	}
+
+	set := TrackSet{"tone": tone, "pause": NewSilenceTrack(pause)}
+	set.EvenOut()
+	if d := set["pause"].Duration(); d != tone.Duration() {
+		t.Errorf("expected EvenOut to extend the pause to %v but got %v", tone.Duration(), d)
+	}
+	if v := set.Volume(); v != 0.5 {
+		t.Errorf("expected the pause not to add to the volume, but the set has volume %f", v)
+	}
+}
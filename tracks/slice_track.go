@@ -0,0 +1,88 @@
+package tracks
+
+import (
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A SliceTrack plays the portion of a source track within a half-open time range.
+//
+// The range is measured on the source's timeline, so the boundaries fall on the same samples
+// at which a track of duration from or to would end.
+// The slice itself still encodes to as many samples as any other track of its duration.
+// Continuing a SliceTrack extends it with silence.
+type SliceTrack struct {
+	source    Track
+	from      time.Duration
+	to        time.Duration
+	extension time.Duration
+}
+
+// Slice creates a SliceTrack for the range [from, to) of a source track.
+// The range is clamped to the source's duration, and an empty range yields an empty track.
+func Slice(t Track, from, to time.Duration) *SliceTrack {
+	if from < 0 {
+		from = 0
+	}
+	if d := t.Duration(); to > d {
+		to = d
+	}
+	if to < from {
+		to = from
+	}
+	return &SliceTrack{source: t, from: from, to: to}
+}
+
+// Inner returns the track being sliced.
+func (s *SliceTrack) Inner() Track {
+	return s.source
+}
+
+// Duration returns the length of the range plus any silence the track was continued with.
+func (s *SliceTrack) Duration() time.Duration {
+	return s.to - s.from + s.extension
+}
+
+func (s *SliceTrack) Encode(sampleRate int) []wav.Sample {
+	start := sampleCount(s.from, sampleRate)
+	end := sampleCount(s.to, sampleRate)
+	res := make([]wav.Sample, sampleCount(s.Duration(), sampleRate))
+	if end > start+len(res) {
+		end = start + len(res)
+	}
+	if end > start {
+		source := s.source.Encode(sampleRate)
+		if end > len(source) {
+			end = len(source)
+		}
+		if start < end {
+			copy(res, source[start:end])
+		}
+	}
+	return res
+}
+
+// Continue elongates the track with silence.
+func (s *SliceTrack) Continue(d time.Duration) {
+	s.extension += d
+}
+
+// Volume always returns 0, since the track continues with silence.
+func (s *SliceTrack) Volume() float64 {
+	return 0
+}
+
+// AdjustVolume elongates the track with silence, ignoring the new volume.
+func (s *SliceTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	s.extension += d
+}
+
+// Clone creates a copy of the track, or returns nil if the source cannot be cloned.
+func (s *SliceTrack) Clone() Track {
+	source := cloneTrack(s.source)
+	if source == nil {
+		return nil
+	}
+	return &SliceTrack{source: source, from: s.from, to: s.to, extension: s.extension}
+}
@@ -0,0 +1,59 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestSliceBoundaries(t *testing.T) {
+	tone := NewToneTrack(300, 0.5, 10)
+	tone.AdjustFrequency(500, time.Millisecond*250)
+	cuts := []time.Duration{0, time.Microsecond * 33333, time.Microsecond * 100001,
+		time.Microsecond * 187654, tone.Duration()}
+	for _, sampleRate := range []int{8000, 22050, 44100} {
+		full := tone.Encode(sampleRate)
+		for i := 1; i < len(cuts); i++ {
+			slice := Slice(tone, cuts[i-1], cuts[i])
+			if d := slice.Duration(); d != cuts[i]-cuts[i-1] {
+				t.Errorf("expected duration %v but got %v", cuts[i]-cuts[i-1], d)
+			}
+			// The slice holds exactly the samples whose timestamps fall in the range, padded or
+			// truncated to as many samples as any other track of its duration.
+			start, end := sampleCount(cuts[i-1], sampleRate), sampleCount(cuts[i], sampleRate)
+			expected := make([]wav.Sample, sampleCount(slice.Duration(), sampleRate))
+			copy(expected, full[start:end])
+			assertSamplesClose(t, expected, slice.Encode(sampleRate), 0)
+		}
+	}
+}
+
+func TestSliceClampingAndContinue(t *testing.T) {
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.Continue(time.Millisecond * 100)
+	full := tone.Encode(8000)
+
+	clamped := Slice(tone, time.Millisecond*50, time.Second)
+	if d := clamped.Duration(); d != time.Millisecond*50 {
+		t.Errorf("expected the slice to be clamped to 50ms but got %v", d)
+	}
+	assertSamplesClose(t, full[400:], clamped.Encode(8000), 0)
+
+	for _, empty := range []*SliceTrack{
+		Slice(tone, time.Millisecond*60, time.Millisecond*20),
+		Slice(tone, time.Millisecond*60, time.Millisecond*60),
+		Slice(tone, time.Second, time.Second*2),
+	} {
+		if d := empty.Duration(); d != 0 || len(empty.Encode(8000)) != 0 {
+			t.Errorf("expected an empty slice but got duration %v", d)
+		}
+	}
+
+	clamped.Continue(time.Millisecond * 25)
+	expected := append(append([]wav.Sample{}, full[400:]...), make([]wav.Sample, 200)...)
+	assertSamplesClose(t, expected, clamped.Encode(8000), 0)
+	if v := clamped.Volume(); v != 0 {
+		t.Errorf("expected the silent extension to have volume 0 but got %f", v)
+	}
+}
@@ -0,0 +1,144 @@
+package tracks
+
+import (
+	"math"
+	"math/cmplx"
+
+	"github.com/unixpickle/wav"
+)
+
+const (
+	// formantSmoothing is the approximate width, in Hz, of the triangular kernel which
+	// EstimateFormants smooths a spectrum with, so that a formant spread over several bins
+	// yields a single peak.
+	formantSmoothing = 150.0
+
+	// formantFloor is the level, relative to the strongest peak, below which EstimateFormants
+	// ignores peaks.
+	formantFloor = 0.01
+)
+
+// Spectrogram computes the magnitude spectra of Hann-windowed frames of a signal.
+//
+// Frames are windowSize samples long and start every hop samples, and the final frame is
+// zero-padded if it runs past the end of the signal.
+// Each frame is zero-padded to a power of two before its transform, and it holds the magnitudes
+// of the bins from 0 Hz up to the Nyquist frequency, so bin k is at the frequency
+// SpectrogramBinFrequency(k, len(frame), sampleRate).
+// The magnitudes are scaled so that a sine centered on a bin has a magnitude equal to its
+// amplitude.
+//
+// It returns nil if windowSize or hop is not positive, or if the signal is empty.
+func Spectrogram(samples []wav.Sample, sampleRate, windowSize, hop int) [][]float64 {
+	if windowSize <= 0 || hop <= 0 || len(samples) == 0 {
+		return nil
+	}
+	window := make([]float64, windowSize)
+	var windowSum float64
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(windowSize))
+		windowSum += window[i]
+	}
+	scale := 2 / windowSum
+
+	frameCount := 1
+	if len(samples) > windowSize {
+		frameCount += (len(samples) - windowSize + hop - 1) / hop
+	}
+	res := make([][]float64, frameCount)
+	buffer := make([]complex128, nextPowerOfTwo(windowSize))
+	for i := range res {
+		start := i * hop
+		for j := range buffer {
+			buffer[j] = 0
+			if j < windowSize && start+j < len(samples) {
+				buffer[j] = complex(float64(samples[start+j])*window[j], 0)
+			}
+		}
+		fft(buffer)
+		frame := make([]float64, len(buffer)/2+1)
+		for k := range frame {
+			frame[k] = cmplx.Abs(buffer[k]) * scale
+		}
+		res[i] = frame
+	}
+	return res
+}
+
+// TrackSpectrogram is like Spectrogram, but it analyzes the encoding of a track.
+func TrackSpectrogram(t Track, sampleRate, windowSize, hop int) [][]float64 {
+	return Spectrogram(t.Encode(sampleRate), sampleRate, windowSize, hop)
+}
+
+// SpectrogramBinFrequency returns the frequency, in Hz, of bin k of a Spectrogram frame with
+// frameSize bins.
+func SpectrogramBinFrequency(k, frameSize, sampleRate int) float64 {
+	if frameSize < 2 {
+		return 0
+	}
+	return float64(k) * float64(sampleRate) / float64(2*(frameSize-1))
+}
+
+// EstimateFormants estimates the formant frequencies, in Hz, of a frame from Spectrogram by
+// picking the peaks of its smoothed magnitudes.
+//
+// The frequencies are in ascending order, and peaks far below the strongest one are ignored.
+// Peaks are located between bins by fitting a parabola, so estimates can be finer than the
+// spacing of the bins.
+func EstimateFormants(frame []float64, sampleRate int) []float64 {
+	if len(frame) < 3 {
+		return nil
+	}
+	binWidth := SpectrogramBinFrequency(1, len(frame), sampleRate)
+	radius := int(formantSmoothing / binWidth / 4)
+	smoothed := movingAverage(movingAverage(frame, radius), radius)
+
+	var peak float64
+	for _, x := range smoothed[1:] {
+		peak = math.Max(peak, x)
+	}
+	if peak == 0 {
+		return nil
+	}
+
+	var res []float64
+	for k := 1; k < len(smoothed)-1; k++ {
+		left, center, right := smoothed[k-1], smoothed[k], smoothed[k+1]
+		if center <= left || center < right || center < peak*formantFloor {
+			continue
+		}
+		var offset float64
+		if curvature := left - 2*center + right; curvature < 0 {
+			offset = 0.5 * (left - right) / curvature
+		}
+		res = append(res, (float64(k)+offset)*binWidth)
+	}
+	return res
+}
+
+// movingAverage averages each value with the radius values on either side of it, shrinking the
+// average at the ends of the slice.
+func movingAverage(values []float64, radius int) []float64 {
+	if radius <= 0 {
+		return append([]float64{}, values...)
+	}
+	res := make([]float64, len(values))
+	var sum float64
+	var count int
+	for i := 0; i < radius && i < len(values); i++ {
+		sum += values[i]
+		count++
+	}
+	for i := range values {
+		if j := i + radius; j < len(values) {
+			sum += values[j]
+			count++
+		}
+		if j := i - radius - 1; j >= 0 {
+			sum -= values[j]
+			count--
+		}
+		res[i] = sum / float64(count)
+	}
+	return res
+}
@@ -0,0 +1,125 @@
+package tracks
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestSpectrogramFrames(t *testing.T) {
+	samples := make([]wav.Sample, 1000)
+	for i := range samples {
+		samples[i] = wav.Sample(math.Sin(float64(i) * 0.3))
+	}
+	for _, test := range []struct {
+		length     int
+		windowSize int
+		hop        int
+		frames     int
+		bins       int
+	}{
+		{1000, 256, 100, 9, 129},
+		{1000, 300, 100, 8, 257},
+		{1000, 1000, 1, 1, 513},
+		{100, 256, 100, 1, 129},
+		{0, 256, 100, 0, 0},
+		{1000, 0, 100, 0, 0},
+		{1000, 256, 0, 0, 0},
+	} {
+		spectrogram := Spectrogram(samples[:test.length], 8000, test.windowSize, test.hop)
+		if len(spectrogram) != test.frames {
+			t.Errorf("%v: expected %d frames but got %d", test, test.frames, len(spectrogram))
+			continue
+		}
+		for _, frame := range spectrogram {
+			if len(frame) != test.bins {
+				t.Errorf("%v: expected %d bins but got %d", test, test.bins, len(frame))
+				break
+			}
+		}
+	}
+
+	// The final frame runs past the end of the signal, so it matches the frame of a signal
+	// padded with silence.
+	padded := append(append([]wav.Sample{}, samples...), make([]wav.Sample, 56)...)
+	frames := Spectrogram(samples, 8000, 256, 100)
+	paddedFrames := Spectrogram(padded, 8000, 256, 100)
+	if len(paddedFrames) != len(frames) {
+		t.Fatalf("expected %d frames for the padded signal but got %d", len(frames),
+			len(paddedFrames))
+	}
+	for i, frame := range frames {
+		for k, m := range frame {
+			if m != paddedFrames[i][k] {
+				t.Fatalf("frame %d, bin %d: expected %f but got %f", i, k, paddedFrames[i][k], m)
+			}
+		}
+	}
+}
+
+func TestSpectrogramMagnitude(t *testing.T) {
+	const sampleRate = 8000
+	tone := NewToneTrack(1000, 0.5, 0)
+	tone.Continue(sampleTime(2048, sampleRate))
+	for i, frame := range TrackSpectrogram(tone, sampleRate, 512, 256) {
+		var best int
+		for k, m := range frame {
+			if m > frame[best] {
+				best = k
+			}
+		}
+		if f := SpectrogramBinFrequency(best, len(frame), sampleRate); f != 1000 {
+			t.Errorf("frame %d: expected a peak at 1000 Hz but got %f Hz", i, f)
+		}
+		if math.Abs(frame[best]-0.5) > 0.005 {
+			t.Errorf("frame %d: expected a magnitude of 0.5 but got %f", i, frame[best])
+		}
+	}
+
+	for _, test := range []struct {
+		k         int
+		frameSize int
+		expected  float64
+	}{
+		{0, 257, 0},
+		{256, 257, sampleRate / 2},
+		{64, 257, 1000},
+		{0, 1, 0},
+	} {
+		if f := SpectrogramBinFrequency(test.k, test.frameSize, sampleRate); f != test.expected {
+			t.Errorf("bin %d of %d: expected %f Hz but got %f Hz", test.k, test.frameSize,
+				test.expected, f)
+		}
+	}
+}
+
+func TestEstimateFormants(t *testing.T) {
+	const sampleRate = 16000
+	formants := []float64{710, 1234, 2650}
+	set := TrackSet{}
+	for i, f := range formants {
+		tone := NewToneTrack(f, 0.5/float64(i+1), 0)
+		tone.Continue(sampleTime(1024, sampleRate))
+		set[TrackID("F"+strconv.Itoa(i+1))] = tone
+	}
+	frame := TrackSpectrogram(set, sampleRate, 1024, 1024)[0]
+	estimates := EstimateFormants(frame, sampleRate)
+	if len(estimates) != len(formants) {
+		t.Fatalf("expected %d formants but got %v", len(formants), estimates)
+	}
+
+	// Peaks are interpolated, so they are located more finely than the 15.6 Hz bins.
+	for i, f := range formants {
+		if math.Abs(estimates[i]-f) > 5 {
+			t.Errorf("F%d: expected %f Hz but got %f Hz", i+1, f, estimates[i])
+		}
+	}
+
+	for _, frame := range [][]float64{nil, {1, 2}, make([]float64, 513)} {
+		if estimates := EstimateFormants(frame, sampleRate); estimates != nil {
+			t.Errorf("expected no formants in %d bins but got %v", len(frame), estimates)
+		}
+	}
+}
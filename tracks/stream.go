@@ -0,0 +1,102 @@
+package tracks
+
+import "github.com/unixpickle/wav"
+
+// streamChunkSize is the number of samples read at a time when a stream is drained.
+const streamChunkSize = 4096
+
+// A SampleStream generates the samples of a track incrementally.
+type SampleStream interface {
+	// Read fills buf with the next samples of the track and returns the number of samples
+	// written.
+	// The result is less than len(buf) only once the track has been exhausted.
+	Read(buf []wav.Sample) int
+}
+
+// A StreamingTrack is a Track which can be encoded incrementally rather than all at once.
+type StreamingTrack interface {
+	Track
+
+	// Stream returns a SampleStream which generates the same samples as Encode.
+	// The track should not be modified while the stream is in use.
+	Stream(sampleRate int) SampleStream
+}
+
+// Stream returns a SampleStream for a track.
+// Tracks which do not implement StreamingTrack are encoded in full when the stream is created.
+func Stream(t Track, sampleRate int) SampleStream {
+	if st, ok := t.(StreamingTrack); ok {
+		return st.Stream(sampleRate)
+	}
+	return &sliceStream{samples: t.Encode(sampleRate)}
+}
+
+// Stream returns a SampleStream which sums up the streams of every track in the set.
+// Only one chunk of each member track is held in memory at a time.
+func (t TrackSet) Stream(sampleRate int) SampleStream {
+	ids := t.sortedIDs()
+	res := &setStream{streams: make([]SampleStream, len(ids))}
+	for i, id := range ids {
+		res.streams[i] = Stream(t[id], sampleRate)
+	}
+	return res
+}
+
+// readStream drains a stream into a slice.
+// The slice starts out with room for size samples, which should be the length of the track,
+// so that it only has to grow if the stream runs longer.
+func readStream(s SampleStream, size int) []wav.Sample {
+	res := make([]wav.Sample, size)
+	if n := s.Read(res); n < size {
+		return res[:n]
+	}
+	for {
+		start := len(res)
+		res = append(res, make([]wav.Sample, streamChunkSize)...)
+		n := s.Read(res[start:])
+		if n < streamChunkSize {
+			return res[:start+n]
+		}
+	}
+}
+
+type sliceStream struct {
+	samples []wav.Sample
+}
+
+func (s *sliceStream) Read(buf []wav.Sample) int {
+	n := copy(buf, s.samples)
+	s.samples = s.samples[n:]
+	return n
+}
+
+type setStream struct {
+	streams []SampleStream
+	scratch []wav.Sample
+}
+
+func (s *setStream) Read(buf []wav.Sample) int {
+	if len(s.scratch) < len(buf) {
+		s.scratch = make([]wav.Sample, len(buf))
+	}
+	for i := range buf {
+		buf[i] = 0
+	}
+
+	var res int
+	remaining := s.streams[:0]
+	for _, stream := range s.streams {
+		n := stream.Read(s.scratch[:len(buf)])
+		for i, sample := range s.scratch[:n] {
+			buf[i] += sample
+		}
+		if n > res {
+			res = n
+		}
+		if n == len(buf) {
+			remaining = append(remaining, stream)
+		}
+	}
+	s.streams = remaining
+	return res
+}
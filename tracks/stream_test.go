@@ -0,0 +1,47 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestStreamMatchesEncode(t *testing.T) {
+	tone := NewToneTrack(220, 0.3, 15)
+	tone.AdjustFrequency(330, time.Millisecond*300)
+	saw := NewSawtoothTrack(110, 2)
+	saw.AdjustVolume(0.2, time.Millisecond*50)
+	saw.Continue(time.Millisecond * 200)
+	inner := NewToneTrack(880, 0.1, 0)
+	inner.Continue(time.Millisecond * 420)
+	noise := NewNoiseTrack(WhiteNoise, 0.05, 2)
+	noise.Continue(time.Millisecond * 100)
+	set := TrackSet{
+		"tone": tone,
+		"saw":  saw,
+		"nested": TrackSet{
+			"inner": inner,
+			"deeper": TrackSet{
+				"noise": noise,
+			},
+		},
+	}
+
+	for _, sampleRate := range []int{8000, 22050} {
+		expected := set.Encode(sampleRate)
+		for _, chunkSize := range []int{1, 7, 1000, 4096, 100000} {
+			stream := set.Stream(sampleRate)
+			var actual []wav.Sample
+			buf := make([]wav.Sample, chunkSize)
+			for {
+				n := stream.Read(buf)
+				actual = append(actual, buf[:n]...)
+				if n < chunkSize {
+					break
+				}
+			}
+			assertSamplesClose(t, expected, actual, 0)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package tracks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A SyncTrackSet is a TrackSet which is safe to use from multiple goroutines.
+//
+// The members of a SyncTrackSet are guarded by its lock, so they should only be modified through
+// the set once they have been added to it.
+// Nested SyncTrackSets have locks of their own, which are always acquired after the locks of the
+// sets containing them.
+type SyncTrackSet struct {
+	lock sync.RWMutex
+	set  TrackSet
+}
+
+// NewSyncTrackSet creates a SyncTrackSet with the members of a TrackSet.
+// The TrackSet should not be used directly after this.
+func NewSyncTrackSet(set TrackSet) *SyncTrackSet {
+	if set == nil {
+		set = TrackSet{}
+	}
+	return &SyncTrackSet{set: set}
+}
+
+// Get returns the member with the given ID, if there is one.
+func (s *SyncTrackSet) Get(id TrackID) (Track, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	track, ok := s.set[id]
+	return track, ok
+}
+
+// Set adds a member to the set, replacing any member with the same ID.
+func (s *SyncTrackSet) Set(id TrackID, track Track) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.set[id] = track
+}
+
+// Delete removes the member with the given ID, if there is one.
+func (s *SyncTrackSet) Delete(id TrackID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.set, id)
+}
+
+// ExcludeTracks returns a TrackSet of the members that do not have the given track IDs.
+// The returned set shares its members with s, but it is not guarded by the lock.
+func (s *SyncTrackSet) ExcludeTracks(ids ...TrackID) TrackSet {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.ExcludeTracks(ids...)
+}
+
+func (s *SyncTrackSet) Duration() time.Duration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.Duration()
+}
+
+// Encode is like TrackSet.Encode.
+// It takes the write lock, since encoding a member may update state that the member keeps to
+// speed up later encodings.
+func (s *SyncTrackSet) Encode(sampleRate int) []wav.Sample {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.set.Encode(sampleRate)
+}
+
+func (s *SyncTrackSet) Continue(duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.set.Continue(duration)
+}
+
+// EvenOut is like TrackSet.EvenOut.
+func (s *SyncTrackSet) EvenOut() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.set.EvenOut()
+}
+
+func (s *SyncTrackSet) Volume() float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.Volume()
+}
+
+// AdjustVolume is like TrackSet.AdjustVolume.
+func (s *SyncTrackSet) AdjustVolume(newVolume float64, duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.set.AdjustVolume(newVolume, duration)
+}
+
+// AdjustVolumeCurve is like TrackSet.AdjustVolumeCurve.
+func (s *SyncTrackSet) AdjustVolumeCurve(newVolume float64, duration time.Duration, curve Curve) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.set.AdjustVolumeCurve(newVolume, duration, curve)
+}
+
+// Clone creates a SyncTrackSet with a deep copy of the members, or returns nil if some member
+// cannot be cloned.
+func (s *SyncTrackSet) Clone() Track {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	set, err := s.set.Clone()
+	if err != nil {
+		return nil
+	}
+	return NewSyncTrackSet(set)
+}
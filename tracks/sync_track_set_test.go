@@ -0,0 +1,52 @@
+package tracks
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncTrackSetConcurrentUse(t *testing.T) {
+	inner := NewSyncTrackSet(TrackSet{"tone": NewToneTrack(330, 0.2, 5)})
+	set := NewSyncTrackSet(TrackSet{
+		"tone":  NewToneTrack(440, 0.2, 0),
+		"noise": NewNoiseTrack(WhiteNoise, 0.1, 1),
+		"inner": inner,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				switch (i + j) % 4 {
+				case 0:
+					set.Continue(time.Millisecond * 5)
+				case 1:
+					set.AdjustVolume(0.1+0.01*float64(j), time.Millisecond*5)
+				case 2:
+					set.Encode(8000)
+				default:
+					inner.Continue(time.Millisecond)
+					set.Volume()
+					set.Duration()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	set.EvenOut()
+	duration := set.Duration()
+	for _, id := range []TrackID{"tone", "noise", "inner"} {
+		track, _ := set.Get(id)
+		if track.Duration() != duration {
+			t.Errorf("track %s: expected duration %v but got %v", id, duration,
+				track.Duration())
+		}
+	}
+	if n := len(set.Encode(8000)); n != sampleCount(duration, 8000) {
+		t.Errorf("expected %d samples but got %d", sampleCount(duration, 8000), n)
+	}
+}
@@ -0,0 +1,57 @@
+package tracks
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A Tempo is a musical tempo in quarter-note beats per minute.
+type Tempo float64
+
+// NoteDuration converts a note value to a duration at the tempo.
+//
+// Note values are fractions of a whole note, such as "1/4" for a quarter note.
+// A trailing "." makes a dotted note, which is 1.5 times as long, and a trailing "t" makes a
+// triplet, which is 2/3 as long.
+// For example, "1/8." is a dotted eighth note and "1/16t" is a sixteenth-note triplet.
+func (t Tempo) NoteDuration(value string) (time.Duration, error) {
+	if t <= 0 {
+		return 0, errors.New("tempo must be positive")
+	}
+	scale := 1.0
+	fraction := value
+	if strings.HasSuffix(fraction, ".") {
+		scale = 1.5
+		fraction = fraction[:len(fraction)-1]
+	} else if strings.HasSuffix(fraction, "t") {
+		scale = 2.0 / 3.0
+		fraction = fraction[:len(fraction)-1]
+	}
+
+	numerator, denominator := fraction, "1"
+	if idx := strings.Index(fraction, "/"); idx >= 0 {
+		numerator, denominator = fraction[:idx], fraction[idx+1:]
+	}
+	num, err1 := strconv.Atoi(numerator)
+	den, err2 := strconv.Atoi(denominator)
+	if err1 != nil || err2 != nil || num <= 0 || den <= 0 {
+		return 0, errors.New("invalid note value: " + value)
+	}
+
+	wholeNoteSeconds := 4 * 60 / float64(t)
+	seconds := wholeNoteSeconds * float64(num) / float64(den) * scale
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// ContinueNote elongates a track by a note value at the given tempo.
+// See Tempo.NoteDuration for the note value format.
+func ContinueNote(t Track, value string, tempo Tempo) error {
+	d, err := tempo.NoteDuration(value)
+	if err != nil {
+		return err
+	}
+	t.Continue(d)
+	return nil
+}
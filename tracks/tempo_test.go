@@ -0,0 +1,57 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNoteDuration(t *testing.T) {
+	for value, expected := range map[string]time.Duration{
+		"1/4":   time.Millisecond * 500,
+		"1/8.":  time.Millisecond * 375,
+		"1":     time.Second * 2,
+		"3/4":   time.Millisecond * 1500,
+		"1/4t":  time.Millisecond * 1000 / 3,
+		"1/16":  time.Millisecond * 125,
+		"1/2.":  time.Millisecond * 1500,
+		"2/1":   time.Second * 4,
+		"1/32t": time.Millisecond * 125 / 3,
+	} {
+		actual, err := Tempo(120).NoteDuration(value)
+		if err != nil {
+			t.Errorf("%s: %s", value, err)
+		} else if actual < expected-time.Microsecond || actual > expected+time.Microsecond {
+			t.Errorf("%s: expected %v but got %v", value, expected, actual)
+		}
+	}
+	for _, value := range []string{"", "1/", "/4", "0/4", "1/0", "-1/4", "a/b", "1/4x"} {
+		if _, err := Tempo(120).NoteDuration(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+	if _, err := Tempo(0).NoteDuration("1/4"); err == nil {
+		t.Error("expected an error for a zero tempo")
+	}
+}
+
+func TestContinueNote(t *testing.T) {
+	tone := NewToneTrack(440, 0.5, 0)
+	if err := ContinueNote(tone, "1/4", 120); err != nil {
+		t.Fatal(err)
+	}
+	if d := tone.Duration(); d != time.Millisecond*500 {
+		t.Errorf("expected a quarter note to last 500ms but got %v", d)
+	}
+	if err := ContinueNote(tone, "1/8.", 120); err != nil {
+		t.Fatal(err)
+	}
+	if d := tone.Duration(); d != time.Millisecond*875 {
+		t.Errorf("expected a dotted eighth to add 375ms but got %v", d)
+	}
+	if err := ContinueNote(tone, "bogus", 120); err == nil {
+		t.Error("expected an error for an invalid note value")
+	}
+	if d := tone.Duration(); d != time.Millisecond*875 {
+		t.Errorf("expected an invalid note value to leave the duration alone but got %v", d)
+	}
+}
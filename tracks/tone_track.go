@@ -3,16 +3,33 @@ package tracks
 import (
 	"math"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/unixpickle/wav"
 )
 
-// A ToneTrack manages a pure tone with optional
+// A ToneTrack manages a tone with optional
 // overlaid noise.
+// The tone is a pure sine unless its Waveform is set.
 type ToneTrack struct {
-	currentTime time.Duration
-	segments    []*noiseSegment
+	currentTime  time.Duration
+	initialPhase float64
+	waveform     Waveform
+	openQuotient float64
+	jitter       float64
+	shimmer      float64
+	seed         int64
+	segments     []*noiseSegment
+
+	// revision counts the changes to the track other than elongating it, which invalidate
+	// the cursor.
+	revision int
+
+	// cursorLock guards the cursor, which Encode updates, so that a tone can be encoded from
+	// several goroutines at once.
+	cursorLock sync.Mutex
+	cursor     *toneCursor
 }
 
 // NewToneTrack generates a zero-length ToneTrack which
@@ -34,6 +51,13 @@ func NewToneTrack(freq, volume, spread float64) *ToneTrack {
 	}
 }
 
+// NewToneTrackPhase is like NewToneTrack, but the tone starts at the given phase, in turns.
+func NewToneTrackPhase(freq, volume, spread, phase float64) *ToneTrack {
+	res := NewToneTrack(freq, volume, spread)
+	res.SetInitialPhase(phase)
+	return res
+}
+
 func (s *ToneTrack) Duration() (res time.Duration) {
 	for _, segment := range s.segments {
 		res += segment.duration
@@ -41,40 +65,177 @@ func (s *ToneTrack) Duration() (res time.Duration) {
 	return
 }
 
+// Encode generates the samples of the tone.
 func (s *ToneTrack) Encode(sampleRate int) []wav.Sample {
-	duration := s.Duration()
-	var segmentStartTime time.Duration
-	var segmentIndex int
-	var sampleIndex int
-
-	res := []wav.Sample{}
-	var sineArgument float64
-	for {
-		secondsElapsed := float64(sampleIndex) / float64(sampleRate)
-		currentTime := time.Duration(float64(time.Second) * secondsElapsed)
-		if currentTime >= duration {
-			break
-		}
+	return s.cursorRange(sampleRate, 0, sampleCount(s.Duration(), sampleRate))
+}
 
-		for currentTime >= segmentStartTime+s.segments[segmentIndex].duration {
-			segmentStartTime += s.segments[segmentIndex].duration
-			segmentIndex++
-		}
+// cursorRange generates the samples [start, end), clamped to the length of the track.
+//
+// The track keeps the state of the oscillator where its last encoding stopped, but none of the
+// samples, so a window which starts at or after that point, as when a long tone is rendered
+// window by window, carries on without generating the tone up to the window again.
+// Any change to the track other than elongating it makes the next encoding start over.
+func (s *ToneTrack) cursorRange(sampleRate, start, end int) []wav.Sample {
+	stream := s.takeCursor(sampleRate, start)
+	if stream == nil {
+		stream = s.newStream(sampleRate)
+	}
+	stream.duration = s.Duration()
+	res := streamRange(stream, start-stream.sampleIndex, end-stream.sampleIndex)
+	s.storeCursor(stream)
+	return res
+}
 
-		segment := s.segments[segmentIndex]
-		freq, volume, spread := segment.infoAtTime(currentTime - segmentStartTime)
-		sample := math.Sin(sineArgument) * volume
-		res = append(res, wav.Sample(sample))
+// takeCursor removes and returns the stream of the cursor if it can generate the samples from
+// start onward.
+// The cursor is removed while it generates samples, so other goroutines encoding the tone start
+// their own streams instead of waiting for it.
+func (s *ToneTrack) takeCursor(sampleRate, start int) *toneStream {
+	s.cursorLock.Lock()
+	defer s.cursorLock.Unlock()
+	if !s.cursor.valid(s, sampleRate) || s.cursor.stream.sampleIndex > start {
+		return nil
+	}
+	res := s.cursor.stream
+	s.cursor = nil
+	return res
+}
 
-		freq += rand.NormFloat64() * spread
-		sineArgument += math.Pi * 2 * freq / float64(sampleRate)
-		for sineArgument > math.Pi*2 {
-			sineArgument -= math.Pi * 2
-		}
+// storeCursor makes a stream which has generated part of the track the new cursor.
+func (s *ToneTrack) storeCursor(stream *toneStream) {
+	s.cursorLock.Lock()
+	defer s.cursorLock.Unlock()
+	s.cursor = &toneCursor{
+		revision:     s.revision,
+		stream:       stream,
+		segments:     len(s.segments),
+		lastDuration: s.lastSegment().duration,
+	}
+}
+
+// Stream returns a SampleStream which generates the tone incrementally.
+func (s *ToneTrack) Stream(sampleRate int) SampleStream {
+	return s.newStream(sampleRate)
+}
+
+func (s *ToneTrack) newStream(sampleRate int) *toneStream {
+	res := &toneStream{
+		track:      s,
+		sampleRate: sampleRate,
+		duration:   s.Duration(),
+		phase:      s.initialPhase,
+		random:     rand.New(rand.NewSource(s.seed)),
+	}
+	if s.jitter != 0 {
+		res.jitter = newLowPassNoise(perturbationCutoff, sampleRate, res.random)
+	}
+	if s.shimmer != 0 {
+		res.shimmer = newLowPassNoise(perturbationCutoff, sampleRate, res.random)
+	}
+	if s.waveform != SineWave {
+		res.harmonicsCos, res.harmonicsSin = waveformHarmonics(s.waveform, s.OpenQuotient())
+	}
+	return res
+}
+
+// InitialPhase returns the phase at which the tone starts, in turns.
+func (s *ToneTrack) InitialPhase() float64 {
+	return s.initialPhase
+}
+
+// SetInitialPhase sets the phase at which the tone starts, in turns.
+// The phase is wrapped into the range [0, 1).
+// Since the tone stays phase-continuous through every adjustment, this offset carries through
+// the entire track.
+func (s *ToneTrack) SetInitialPhase(phase float64) {
+	s.initialPhase = wrapPhase(phase)
+	s.revision++
+}
+
+// Waveform returns the shape of the tone's periods.
+func (s *ToneTrack) Waveform() Waveform {
+	return s.waveform
+}
+
+// SetWaveform sets the shape of the tone's periods for the entire track.
+// The frequency, volume, and phase of the tone behave the same for every waveform.
+func (s *ToneTrack) SetWaveform(w Waveform) {
+	s.waveform = w
+	s.revision++
+}
 
-		sampleIndex++
+// OpenQuotient returns the fraction of each period for which a PulseWave or GlottalWave is open.
+// It is DefaultOpenQuotient unless it has been set.
+func (s *ToneTrack) OpenQuotient() float64 {
+	if s.openQuotient == 0 {
+		return DefaultOpenQuotient
 	}
+	return s.openQuotient
+}
+
+// SetOpenQuotient sets the open quotient of the tone for the entire track.
+// It is clamped to the range [0.05, 0.95] when the tone is encoded.
+func (s *ToneTrack) SetOpenQuotient(q float64) {
+	s.openQuotient = q
+	s.revision++
+}
+
+// Jitter returns the standard deviation of the tone's random frequency perturbation, as a
+// percentage of its frequency.
+func (s *ToneTrack) Jitter() float64 {
+	return s.jitter
+}
+
+// SetJitter sets the jitter of the tone for the entire track.
+// The frequency wanders smoothly around its value, so a voice does not sound mechanically
+// steady.
+func (s *ToneTrack) SetJitter(percent float64) {
+	s.jitter = percent
+	s.revision++
+}
+
+// Shimmer returns the standard deviation of the tone's random amplitude perturbation, as a
+// percentage of its volume.
+func (s *ToneTrack) Shimmer() float64 {
+	return s.shimmer
+}
 
+// SetShimmer sets the shimmer of the tone for the entire track.
+func (s *ToneTrack) SetShimmer(percent float64) {
+	s.shimmer = percent
+	s.revision++
+}
+
+// Seed returns the seed from which the tone's spread, jitter, shimmer, and breathiness are
+// generated.
+func (s *ToneTrack) Seed() int64 {
+	return s.seed
+}
+
+// SetSeed sets the seed from which the tone's randomness is generated.
+// Every Encode of the tone produces the same samples.
+func (s *ToneTrack) SetSeed(seed int64) {
+	s.seed = seed
+	s.revision++
+}
+
+// Clone creates a copy of the tone which can be adjusted independently.
+func (s *ToneTrack) Clone() Track {
+	res := &ToneTrack{
+		currentTime:  s.currentTime,
+		initialPhase: s.initialPhase,
+		waveform:     s.waveform,
+		openQuotient: s.openQuotient,
+		jitter:       s.jitter,
+		shimmer:      s.shimmer,
+		seed:         s.seed,
+		segments:     make([]*noiseSegment, len(s.segments)),
+	}
+	for i, seg := range s.segments {
+		segCopy := *seg
+		res.segments[i] = &segCopy
+	}
 	return res
 }
 
@@ -85,13 +246,18 @@ func (s *ToneTrack) Continue(duration time.Duration) {
 		lastSeg.duration += duration
 	} else {
 		seg := &noiseSegment{
-			duration:       duration,
-			startSpread:    lastSeg.endSpread,
-			startFrequency: lastSeg.endFrequency,
-			startVolume:    lastSeg.endVolume,
-			endFrequency:   lastSeg.endFrequency,
-			endVolume:      lastSeg.endVolume,
-			endSpread:      lastSeg.endSpread,
+			duration:         duration,
+			startSpread:      lastSeg.endSpread,
+			startFrequency:   lastSeg.endFrequency,
+			startVolume:      lastSeg.endVolume,
+			endFrequency:     lastSeg.endFrequency,
+			endVolume:        lastSeg.endVolume,
+			endSpread:        lastSeg.endSpread,
+			vibratoRate:      lastSeg.vibratoRate,
+			startVibrato:     lastSeg.endVibrato,
+			endVibrato:       lastSeg.endVibrato,
+			startBreathiness: lastSeg.endBreathiness,
+			endBreathiness:   lastSeg.endBreathiness,
 		}
 		s.segments = append(s.segments, seg)
 	}
@@ -102,21 +268,69 @@ func (s *ToneTrack) Volume() float64 {
 	return s.lastSegment().endVolume
 }
 
-// AdjustVolume elongates the track while adjusting the tone's frequency.
+// RMSRatio returns 1/sqrt(2), since every waveform has the power of a sine of the same volume.
+func (s *ToneTrack) RMSRatio(sampleRate int) float64 {
+	return 1 / math.Sqrt2
+}
+
+// AdjustVolume elongates the track while adjusting the tone's amplitude.
 func (s *ToneTrack) AdjustVolume(newVolume float64, duration time.Duration) {
 	s.AdjustAll(s.Frequency(), newVolume, s.Spread(), duration)
 }
 
+// AdjustVolumeCurve is like AdjustVolume, but the amplitude follows the given curve.
+func (s *ToneTrack) AdjustVolumeCurve(newVolume float64, duration time.Duration, curve Curve) {
+	s.AdjustAll(s.Frequency(), newVolume, s.Spread(), duration)
+	s.lastSegment().volumeCurve = curve
+}
+
 // Frequency returns the tone's current frequency.
 func (s *ToneTrack) Frequency() float64 {
 	return s.lastSegment().endFrequency
 }
 
-// AdjustFrequency elongates the track while adjusting the tone's frequency.
+// AdjustFrequency elongates the track while gliding the tone's frequency.
+// The frequency is interpolated linearly, and the tone's phase is accumulated sample by sample,
+// so the glide has no discontinuities.
 func (s *ToneTrack) AdjustFrequency(newFrequency float64, duration time.Duration) {
 	s.AdjustAll(newFrequency, s.Volume(), s.Spread(), duration)
 }
 
+// GlideFrequency makes the frequency over the last part of the track glide linearly to a new
+// frequency, starting from the frequency at the beginning of that part.
+//
+// Unlike AdjustFrequency, this does not elongate the track, so a pitch contour can be laid over
+// a tone whose volume has already been arranged.
+// A duration longer than the track glides across the entire track, and a zero duration sets the
+// frequency immediately.
+func (s *ToneTrack) GlideFrequency(newFrequency float64, duration time.Duration) {
+	total := s.Duration()
+	if duration <= 0 {
+		s.AdjustFrequency(newFrequency, 0)
+		return
+	} else if duration > total {
+		duration = total
+	}
+	start := total - duration
+	s.splitAt(start)
+	s.revision++
+
+	startFrequency := s.segments[0].startFrequency
+	var segmentStart time.Duration
+	for _, seg := range s.segments {
+		segmentEnd := segmentStart + seg.duration
+		if segmentEnd <= start {
+			startFrequency = seg.endFrequency
+		} else {
+			startFrac := float64(segmentStart-start) / float64(duration)
+			endFrac := float64(segmentEnd-start) / float64(duration)
+			seg.startFrequency = startFrequency + (newFrequency-startFrequency)*startFrac
+			seg.endFrequency = startFrequency + (newFrequency-startFrequency)*endFrac
+		}
+		segmentStart = segmentEnd
+	}
+}
+
 // Spread returns the tone's random spread.
 func (s *ToneTrack) Spread() float64 {
 	return s.lastSegment().endSpread
@@ -127,25 +341,100 @@ func (s *ToneTrack) AdjustSpread(spread float64, duration time.Duration) {
 	s.AdjustAll(s.Frequency(), s.Volume(), spread, duration)
 }
 
+// AddSpread adds to the random spread of the tone over the entire track, turning the tone into a
+// band of noise around its frequency without changing its timing.
+func (s *ToneTrack) AddSpread(spread float64) {
+	for _, seg := range s.segments {
+		seg.startSpread += spread
+		seg.endSpread += spread
+	}
+	s.revision++
+}
+
 // AdjustAll elongates the track by while adjusting the tone's characteristics.
 func (s *ToneTrack) AdjustAll(freq, volume, spread float64, duration time.Duration) {
 	lastSeg := s.lastSegment()
 	seg := &noiseSegment{
-		duration:       duration,
-		startSpread:    lastSeg.endSpread,
-		startFrequency: lastSeg.endFrequency,
-		startVolume:    lastSeg.endVolume,
-		endFrequency:   freq,
-		endVolume:      volume,
-		endSpread:      spread,
+		duration:         duration,
+		startSpread:      lastSeg.endSpread,
+		startFrequency:   lastSeg.endFrequency,
+		startVolume:      lastSeg.endVolume,
+		endFrequency:     freq,
+		endVolume:        volume,
+		endSpread:        spread,
+		vibratoRate:      lastSeg.vibratoRate,
+		startVibrato:     lastSeg.endVibrato,
+		endVibrato:       lastSeg.endVibrato,
+		startBreathiness: lastSeg.endBreathiness,
+		endBreathiness:   lastSeg.endBreathiness,
 	}
 	s.segments = append(s.segments, seg)
 }
 
+// VibratoRate returns the current rate of the tone's vibrato, in Hz.
+func (s *ToneTrack) VibratoRate() float64 {
+	return s.lastSegment().vibratoRate
+}
+
+// VibratoDepth returns the current depth of the tone's vibrato, in cents.
+func (s *ToneTrack) VibratoDepth() float64 {
+	return s.lastSegment().endVibrato
+}
+
+// SetVibrato sets the rate and depth of the vibrato from the end of the track onward.
+//
+// The depth is the peak deviation from the tone's frequency in cents, so the vibrato rides on top
+// of any frequency glide and sounds the same at every pitch.
+// A depth of zero disables the vibrato.
+func (s *ToneTrack) SetVibrato(rateHz, depthCents float64) {
+	s.AdjustAll(s.Frequency(), s.Volume(), s.Spread(), 0)
+	seg := s.lastSegment()
+	seg.vibratoRate = rateHz
+	seg.startVibrato = depthCents
+	seg.endVibrato = depthCents
+}
+
+// AdjustVibrato elongates the track while ramping the depth of the vibrato, in cents.
+// This can be used to let the vibrato of a sustained tone bloom gradually.
+func (s *ToneTrack) AdjustVibrato(depthCents float64, duration time.Duration) {
+	s.AdjustAll(s.Frequency(), s.Volume(), s.Spread(), duration)
+	s.lastSegment().endVibrato = depthCents
+}
+
+// Breathiness returns the fraction of the tone's power which is aspiration noise.
+func (s *ToneTrack) Breathiness() float64 {
+	return s.lastSegment().endBreathiness
+}
+
+// AdjustBreathiness elongates the track while mixing the tone with aspiration noise.
+//
+// At a breathiness of 1, the tone is entirely noise, and at 0, it has none.
+// The noise is pulsed by the open phase of each period, as breath escapes through the opening
+// vocal folds, so it blends with the tone rather than hissing on top of it.
+func (s *ToneTrack) AdjustBreathiness(breathiness float64, duration time.Duration) {
+	s.AdjustAll(s.Frequency(), s.Volume(), s.Spread(), duration)
+	s.lastSegment().endBreathiness = math.Max(0, math.Min(1, breathiness))
+}
+
 func (s *ToneTrack) lastSegment() *noiseSegment {
 	return s.segments[len(s.segments)-1]
 }
 
+// splitAt splits the segment which spans the given time, if any, so that a segment boundary
+// occurs there.
+func (s *ToneTrack) splitAt(t time.Duration) {
+	var segmentStart time.Duration
+	for i, seg := range s.segments {
+		if t > segmentStart && t < segmentStart+seg.duration {
+			first, second := seg.split(t - segmentStart)
+			s.segments = append(s.segments[:i], append([]*noiseSegment{first, second},
+				s.segments[i+1:]...)...)
+			return
+		}
+		segmentStart += seg.duration
+	}
+}
+
 type noiseSegment struct {
 	duration       time.Duration
 	startSpread    float64
@@ -154,18 +443,206 @@ type noiseSegment struct {
 	endSpread      float64
 	endFrequency   float64
 	endVolume      float64
+
+	// volumeCurve shapes the volume transition, which is linear if this is nil.
+	volumeCurve Curve
+
+	vibratoRate  float64
+	startVibrato float64
+	endVibrato   float64
+
+	startBreathiness float64
+	endBreathiness   float64
 }
 
 func (s *noiseSegment) static() bool {
 	return s.startFrequency == s.endFrequency &&
 		s.startVolume == s.endVolume &&
-		s.startSpread == s.endSpread
+		s.startSpread == s.endSpread &&
+		s.startVibrato == s.endVibrato &&
+		s.startBreathiness == s.endBreathiness
 }
 
 func (s *noiseSegment) infoAtTime(t time.Duration) (freq, vol, spread float64) {
 	fracDone := float64(t) / float64(s.duration)
 	freq = fracDone*s.endFrequency + (1-fracDone)*s.startFrequency
-	vol = fracDone*s.endVolume + (1-fracDone)*s.startVolume
+	vol = evaluateCurve(s.volumeCurve, s.startVolume, s.endVolume, fracDone)
 	spread = fracDone*s.endSpread + (1-fracDone)*s.startSpread
 	return
 }
+
+// split divides the segment into two segments which meet at the given time.
+func (s *noiseSegment) split(t time.Duration) (first, second *noiseSegment) {
+	freq, vol, spread := s.infoAtTime(t)
+	vibrato := s.vibratoAtTime(t)
+	breathiness := s.breathinessAtTime(t)
+	firstCopy, secondCopy := *s, *s
+	first, second = &firstCopy, &secondCopy
+
+	first.duration = t
+	first.endFrequency, first.endVolume, first.endSpread = freq, vol, spread
+	first.endVibrato, first.endBreathiness = vibrato, breathiness
+	second.duration = s.duration - t
+	second.startFrequency, second.startVolume, second.startSpread = freq, vol, spread
+	second.startVibrato, second.startBreathiness = vibrato, breathiness
+
+	if curve := s.volumeCurve; curve != nil {
+		startVolume, endVolume := s.startVolume, s.endVolume
+		frac := float64(t) / float64(s.duration)
+		first.volumeCurve = func(_, _, t float64) float64 {
+			return evaluateCurve(curve, startVolume, endVolume, t*frac)
+		}
+		second.volumeCurve = func(_, _, t float64) float64 {
+			return evaluateCurve(curve, startVolume, endVolume, frac+t*(1-frac))
+		}
+	}
+	return
+}
+
+func (s *noiseSegment) vibratoAtTime(t time.Duration) float64 {
+	if s.startVibrato == s.endVibrato {
+		return s.endVibrato
+	}
+	fracDone := float64(t) / float64(s.duration)
+	return fracDone*s.endVibrato + (1-fracDone)*s.startVibrato
+}
+
+func (s *noiseSegment) breathinessAtTime(t time.Duration) float64 {
+	if s.startBreathiness == s.endBreathiness {
+		return s.endBreathiness
+	}
+	fracDone := float64(t) / float64(s.duration)
+	return fracDone*s.endBreathiness + (1-fracDone)*s.startBreathiness
+}
+
+type toneStream struct {
+	track      *ToneTrack
+	sampleRate int
+	duration   time.Duration
+
+	segmentStartTime time.Duration
+	segmentIndex     int
+	sampleIndex      int
+	phase            float64
+	vibratoPhase     float64
+
+	// harmonicsCos and harmonicsSin are the coefficients of the waveform, or nil for a sine.
+	harmonicsCos []float64
+	harmonicsSin []float64
+
+	random *rand.Rand
+
+	// jitter and shimmer perturb the frequency and volume, or they are nil if they are disabled.
+	jitter  *lowPassNoise
+	shimmer *lowPassNoise
+}
+
+func (t *toneStream) Read(buf []wav.Sample) int {
+	segments := t.track.segments
+	for i := range buf {
+		secondsElapsed := float64(t.sampleIndex) / float64(t.sampleRate)
+		currentTime := time.Duration(float64(time.Second) * secondsElapsed)
+		if currentTime >= t.duration {
+			return i
+		}
+
+		for currentTime >= t.segmentStartTime+segments[t.segmentIndex].duration {
+			t.segmentStartTime += segments[t.segmentIndex].duration
+			t.segmentIndex++
+		}
+
+		segment := segments[t.segmentIndex]
+		segmentTime := currentTime - t.segmentStartTime
+		freq, volume, spread := segment.infoAtTime(segmentTime)
+		value := t.waveformValue(freq)
+		if breathiness := segment.breathinessAtTime(segmentTime); breathiness != 0 {
+			value = math.Sqrt(1-breathiness)*value + math.Sqrt(breathiness)*t.aspiration()
+		}
+		if t.shimmer != nil {
+			volume *= 1 + t.track.shimmer/100*t.shimmer.next()
+		}
+		buf[i] = wav.Sample(value * volume)
+
+		if depth := segment.vibratoAtTime(segmentTime); depth != 0 {
+			cents := depth * math.Sin(2*math.Pi*t.vibratoPhase)
+			freq *= math.Pow(2, cents/1200)
+		}
+		t.vibratoPhase = wrapPhase(t.vibratoPhase + segment.vibratoRate/float64(t.sampleRate))
+
+		if t.jitter != nil {
+			freq *= 1 + t.track.jitter/100*t.jitter.next()
+		}
+		freq += t.random.NormFloat64() * spread
+		t.phase += freq / float64(t.sampleRate)
+		if t.phase < 0 || t.phase >= 1 {
+			t.phase = wrapPhase(t.phase)
+		}
+
+		t.sampleIndex++
+	}
+	return len(buf)
+}
+
+// waveformValue evaluates the waveform at the current phase, summing the harmonics which are
+// below the Nyquist frequency at the given fundamental.
+func (t *toneStream) waveformValue(freq float64) float64 {
+	if t.harmonicsSin == nil {
+		return sineTurns(t.phase)
+	}
+	count := len(t.harmonicsSin)
+	if freq > 0 {
+		if limit := int(float64(t.sampleRate) / 2 / freq); limit < count {
+			count = limit
+		}
+	}
+
+	// The harmonics are stepped through with the angle-addition formulas, which is much cheaper
+	// than evaluating each one directly.
+	sin1, cos1 := math.Sincos(2 * math.Pi * t.phase)
+	sinK, cosK := sin1, cos1
+	var res float64
+	for k := 0; k < count; k++ {
+		res += t.harmonicsCos[k]*cosK + t.harmonicsSin[k]*sinK
+		sinK, cosK = sinK*cos1+cosK*sin1, cosK*cos1-sinK*sin1
+	}
+	return res
+}
+
+// aspiration generates a sample of noise which is pulsed by the open phase of the tone's
+// periods.
+// Its power matches that of a sine with an amplitude of 1.
+func (t *toneStream) aspiration() float64 {
+	openQuotient := clampOpenQuotient(t.track.OpenQuotient())
+	if t.phase >= openQuotient {
+		return 0
+	}
+	return t.random.NormFloat64() * math.Sin(math.Pi*t.phase/openQuotient) /
+		math.Sqrt(openQuotient)
+}
+
+// A toneCursor holds the stream which generated a ToneTrack's last encoding, positioned at the
+// end of that encoding, so that later samples can be generated without starting over.
+type toneCursor struct {
+	revision int
+	stream   *toneStream
+
+	// segments is the number of segments the track had when the stream stopped, and
+	// lastDuration is the duration of the final one.
+	segments     int
+	lastDuration time.Duration
+}
+
+// valid checks if the track has only been elongated since the cursor's stream stopped.
+//
+// Elongating a track appends segments, except that Continue lengthens a static final segment,
+// which does not change the samples it has already produced.
+// Since the stream only depends on the track's segments up to its current sample, it can carry
+// on through the new material.
+func (c *toneCursor) valid(s *ToneTrack, sampleRate int) bool {
+	if c == nil || c.stream.sampleRate != sampleRate || c.revision != s.revision ||
+		len(s.segments) < c.segments {
+		return false
+	}
+	last := s.segments[c.segments-1]
+	return last.duration == c.lastDuration || last.static()
+}
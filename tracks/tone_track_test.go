@@ -0,0 +1,484 @@
+package tracks
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestToneTrackMatchesSine(t *testing.T) {
+	const sampleRate = 44100
+	tone := NewToneTrack(440, 0.5, 0)
+	tone.Continue(time.Second)
+	samples := tone.Encode(sampleRate)
+	if len(samples) != sampleRate {
+		t.Fatalf("expected %d samples but got %d", sampleRate, len(samples))
+	}
+	for i, sample := range samples {
+		expected := 0.5 * math.Sin(2*math.Pi*440*float64(i)/sampleRate)
+		if math.Abs(float64(sample)-expected) > 1e-6 {
+			t.Fatalf("sample %d: expected %f but got %f", i, expected, sample)
+		}
+	}
+}
+
+func TestToneTrackIncrementalEncode(t *testing.T) {
+	tone := NewToneTrack(300, 0.4, 20)
+	tone.SetSeed(5)
+	var encoded []wav.Sample
+	for i := 0; i < 5; i++ {
+		tone.AdjustAll(300+50*float64(i), 0.4-0.05*float64(i), 20, time.Millisecond*37)
+		tone.Continue(time.Millisecond * 21)
+		encoded = tone.Encode(22050)
+	}
+	fresh := tone.Clone().Encode(22050)
+	assertSamplesClose(t, fresh, encoded, 1e-12)
+
+	tone.SetWaveform(SawtoothWave)
+	assertSamplesClose(t, tone.Clone().Encode(22050), tone.Encode(22050), 0)
+}
+
+func TestToneTrackConcurrentEncode(t *testing.T) {
+	tone := NewToneTrack(440, 0.5, 30)
+	tone.SetSeed(2)
+	tone.AdjustFrequency(220, time.Millisecond*300)
+	expected := map[int][]wav.Sample{
+		8000:  tone.Clone().Encode(8000),
+		22050: tone.Clone().Encode(22050),
+	}
+
+	var wg sync.WaitGroup
+	results := make([][]wav.Sample, 16)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i%4 == 3 {
+				results[i] = EncodeRange(tone, 8000, 0, time.Millisecond*100)
+			} else if i%2 == 0 {
+				results[i] = tone.Encode(8000)
+			} else {
+				results[i] = tone.Encode(22050)
+			}
+		}(i)
+	}
+	wg.Wait()
+	for i, result := range results {
+		if i%4 == 3 {
+			assertSamplesClose(t, expected[8000][:800], result, 0)
+		} else if i%2 == 0 {
+			assertSamplesClose(t, expected[8000], result, 0)
+		} else {
+			assertSamplesClose(t, expected[22050], result, 0)
+		}
+	}
+}
+
+func TestToneTrackPhaseOffset(t *testing.T) {
+	for _, test := range []struct {
+		offset float64
+		scale  float64
+	}{
+		{0, 2},
+		{0.5, 0},
+	} {
+		// The glide checks that the offset persists through phase-continuous adjustments.
+		first := NewToneTrack(200, 0.4, 0)
+		second := NewToneTrackPhase(200, 0.4, 0, test.offset)
+		for _, tone := range []*ToneTrack{first, second} {
+			tone.AdjustFrequency(350, time.Millisecond*300)
+			tone.AdjustVolume(0.2, time.Millisecond*200)
+		}
+		single := first.Encode(22050)
+		mixed := TrackSet{"first": first, "second": second}.Encode(22050)
+		expected := make([]wav.Sample, len(single))
+		for i, sample := range single {
+			expected[i] = sample * wav.Sample(test.scale)
+		}
+		assertSamplesClose(t, expected, mixed, 1e-9)
+	}
+}
+
+func TestToneTrackFrequencyGlide(t *testing.T) {
+	const sampleRate = 44100
+	for _, track := range []PitchedTrack{
+		NewToneTrack(200, 0.5, 0),
+		NewHarmonicToneTrack(200, 0.5, []float64{1}),
+	} {
+		track.AdjustFrequency(600, time.Millisecond*500)
+		if f := track.Frequency(); f != 600 {
+			t.Errorf("%T: expected frequency 600 but got %f", track, f)
+		}
+		samples := track.Encode(sampleRate)
+		window := sampleRate / 50
+		if f := zeroCrossingFrequency(samples[:window], sampleRate); math.Abs(f-200) > 10 {
+			t.Errorf("%T: expected the glide to start at 200 Hz but got %f", track, f)
+		}
+		end := samples[len(samples)-window:]
+		if f := zeroCrossingFrequency(end, sampleRate); math.Abs(f-600) > 20 {
+			t.Errorf("%T: expected the glide to end at 600 Hz but got %f", track, f)
+		}
+
+		// A sine of amplitude a never changes by more than 2*pi*f*a/sampleRate per sample.
+		bound := 2 * math.Pi * 600 * 0.5 / sampleRate * 1.01
+		for i := 1; i < len(samples); i++ {
+			if diff := math.Abs(float64(samples[i] - samples[i-1])); diff > bound {
+				t.Fatalf("%T: sample %d: jump of %f exceeds %f", track, i, diff, bound)
+			}
+		}
+	}
+}
+
+func TestGlideAll(t *testing.T) {
+	const sampleRate = 44100
+	newTone := func() *ToneTrack {
+		tone := NewToneTrack(200, 0.5, 0)
+		tone.Continue(time.Millisecond * 500)
+		return tone
+	}
+	tones := []*ToneTrack{newTone(), newTone(), newTone(), newTone()}
+	harmonic := NewHarmonicToneTrack(200, 0.5, []float64{1})
+	harmonic.AdjustVolume(0.5, time.Millisecond*500)
+	set := TrackSet{
+		"tone":     tones[0],
+		"nested":   TrackSet{"tone": tones[1], "harmonic": harmonic},
+		"sync":     NewSyncTrackSet(TrackSet{"tone": tones[2]}),
+		"weighted": NewWeightedTrackSet(TrackSet{"tone": tones[3]}),
+	}
+	GlideAll(set, 400, time.Millisecond*300)
+
+	window := sampleRate / 50
+	for i, tone := range tones {
+		if d := tone.Duration(); d != time.Millisecond*500 {
+			t.Errorf("tone %d: expected the glide to keep a duration of 500ms, but got %s", i, d)
+		}
+		if f := tone.Frequency(); f != 400 {
+			t.Errorf("tone %d: expected frequency 400 but got %f", i, f)
+		}
+		samples := tone.Encode(sampleRate)
+		before := samples[SampleCount(time.Millisecond*150, sampleRate):][:window]
+		if f := zeroCrossingFrequency(before, sampleRate); math.Abs(f-200) > 10 {
+			t.Errorf("tone %d: expected 200 Hz before the glide but got %f Hz", i, f)
+		}
+		end := samples[len(samples)-window:]
+		if f := zeroCrossingFrequency(end, sampleRate); math.Abs(f-400) > 15 {
+			t.Errorf("tone %d: expected the glide to end at 400 Hz but got %f Hz", i, f)
+		}
+	}
+
+	// Tracks which cannot glide without being elongated are left alone.
+	if d, f := harmonic.Duration(), harmonic.Frequency(); d != time.Millisecond*500 || f != 200 {
+		t.Errorf("expected the harmonic tone to keep 200 Hz for 500ms, but got %f Hz for %s", f, d)
+	}
+}
+
+func TestToneTrackVibrato(t *testing.T) {
+	const sampleRate = 44100
+	frequencyAt := func(samples []wav.Sample, at time.Duration) float64 {
+		center := sampleCount(at, sampleRate)
+		return zeroCrossingFrequency(samples[center-sampleRate/100:center+sampleRate/100],
+			sampleRate)
+	}
+
+	// At 2 Hz, the vibrato peaks at 125ms and bottoms out at 375ms.
+	for _, base := range []float64{220, 880} {
+		tone := NewToneTrack(base, 0.5, 0)
+		tone.SetVibrato(2, 100)
+		tone.Continue(time.Second)
+		samples := tone.Encode(sampleRate)
+		high, low := base*math.Pow(2, 1.0/12), base*math.Pow(2, -1.0/12)
+		if f := frequencyAt(samples, time.Millisecond*125); math.Abs(f/high-1) > 0.005 {
+			t.Errorf("%f Hz: expected the vibrato to peak at %f Hz but got %f", base, high, f)
+		}
+		if f := frequencyAt(samples, time.Millisecond*375); math.Abs(f/low-1) > 0.005 {
+			t.Errorf("%f Hz: expected the vibrato to bottom out at %f Hz but got %f", base, low, f)
+		}
+	}
+
+	// The vibrato rides on top of a glide, and its depth can bloom in.
+	// An eighth of the way into the glide, the base frequency is 247.5 Hz.
+	tone := NewToneTrack(220, 0.5, 0)
+	tone.SetVibrato(2, 0)
+	tone.AdjustVibrato(100, time.Second)
+	tone.AdjustFrequency(440, time.Second)
+	samples := tone.Encode(sampleRate)
+	if f := frequencyAt(samples, time.Millisecond*125); math.Abs(f/220-1) > 0.01 {
+		t.Errorf("expected the vibrato to start shallow, but got %f Hz", f)
+	}
+	expected := 247.5 * math.Pow(2, 1.0/12)
+	if f := frequencyAt(samples, time.Millisecond*1125); math.Abs(f/expected-1) > 0.01 {
+		t.Errorf("expected the vibrato to peak at %f Hz during the glide but got %f", expected, f)
+	}
+
+	// A vibrato with no depth leaves the tone untouched.
+	plain := NewToneTrack(300, 0.5, 20)
+	plain.AdjustFrequency(400, time.Millisecond*300)
+	flat := plain.Clone().(*ToneTrack)
+	flat.SetVibrato(5, 0)
+	plain.Continue(time.Millisecond * 200)
+	flat.Continue(time.Millisecond * 200)
+	assertSamplesClose(t, plain.Encode(sampleRate), flat.Encode(sampleRate), 0)
+}
+
+func BenchmarkToneTrack(b *testing.B) {
+	tone := NewToneTrack(220, 0.5, 0)
+	tone.AdjustAll(330, 0.3, 40, time.Millisecond*300)
+	tone.SetVibrato(5, 30)
+	tone.Continue(time.Millisecond * 700)
+	for i := 0; i < b.N; i++ {
+		tone.Clone().Encode(44100)
+	}
+}
+
+func BenchmarkNoiseTrack(b *testing.B) {
+	noise := NewNoiseTrack(PinkNoise, 0.3, 3)
+	noise.Continue(time.Second)
+	for i := 0; i < b.N; i++ {
+		noise.Encode(44100)
+	}
+}
+
+// zeroCrossingFrequency estimates the frequency of a tone from the spacing of its rising zero
+// crossings.
+func zeroCrossingFrequency(samples []wav.Sample, sampleRate int) float64 {
+	var first, last, count int
+	for i := 1; i < len(samples); i++ {
+		if samples[i-1] < 0 && samples[i] >= 0 {
+			if count == 0 {
+				first = i
+			}
+			last = i
+			count++
+		}
+	}
+	if count < 2 {
+		return 0
+	}
+	return float64(count-1) * float64(sampleRate) / float64(last-first)
+}
+
+// assertSamplesClose checks that two encodings have the same length and differ by at most
+// epsilon at every sample.
+func assertSamplesClose(t *testing.T, expected, actual []wav.Sample, epsilon float64) {
+	t.Helper()
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %d samples but got %d", len(expected), len(actual))
+	}
+	for i := range expected {
+		if math.Abs(float64(expected[i]-actual[i])) > epsilon {
+			t.Fatalf("sample %d: expected %v but got %v", i, expected[i], actual[i])
+		}
+	}
+}
+
+func TestToneTrackWaveformRolloff(t *testing.T) {
+	const sampleRate = 16000
+	harmonics := func(w Waveform) []float64 {
+		tone := NewToneTrack(250, 0.5, 0)
+		tone.SetWaveform(w)
+		tone.SetOpenQuotient(0.5)
+		tone.Continue(time.Second)
+		spectrum := averageSpectrum(tone.Encode(sampleRate), sampleRate)
+		res := make([]float64, 31)
+		for k := range res {
+			freq := float64(k+1) * 250
+			res[k] = bandPower(spectrum, sampleRate, freq-40, freq+40)
+		}
+		for k := range res {
+			res[len(res)-1-k] /= res[0]
+		}
+		return res
+	}
+
+	for k, power := range harmonics(SineWave)[1:] {
+		if power > 1e-4 {
+			t.Errorf("sine: expected no harmonic %d, but got a relative power of %f", k+2, power)
+		}
+	}
+
+	// A sawtooth has every harmonic and a pulse with an open quotient of 0.5 has the odd ones,
+	// each with an amplitude of 1/k.
+	sawtooth, pulse := harmonics(SawtoothWave), harmonics(PulseWave)
+	for i := range sawtooth {
+		k := float64(i + 1)
+		if math.Abs(sawtooth[i]*k*k-1) > 0.05 {
+			t.Errorf("sawtooth: expected harmonic %d at %f but got %f", i+1, 1/(k*k), sawtooth[i])
+		}
+		if i%2 == 1 && pulse[i] > 1e-4 {
+			t.Errorf("pulse: expected no harmonic %d, but got %f", i+1, pulse[i])
+		} else if i%2 == 0 && math.Abs(pulse[i]*k*k-1) > 0.05 {
+			t.Errorf("pulse: expected harmonic %d at %f but got %f", i+1, 1/(k*k), pulse[i])
+		}
+	}
+
+	// A glottal pulse falls off steeply at first, and then approaches 6 dB per octave, which
+	// halves the power of each octave band.
+	glottal := harmonics(GlottalWave)
+	var octaves []float64
+	for start := 1; start < 32; start *= 2 {
+		var sum float64
+		for k := start; k < 2*start && k <= len(glottal); k++ {
+			sum += glottal[k-1]
+		}
+		octaves = append(octaves, sum)
+	}
+	for i := 2; i < len(octaves)-1; i++ {
+		if ratio := octaves[i+1] / octaves[i]; ratio < 0.15 || ratio > 0.6 {
+			t.Errorf("glottal: octave %d has %f of the power of octave %d", i+1, ratio, i)
+		}
+	}
+}
+
+func TestToneTrackWaveformAliasing(t *testing.T) {
+	const sampleRate = 8000
+	for _, w := range []Waveform{SawtoothWave, PulseWave, GlottalWave} {
+		tone := NewToneTrack(1500, 0.5, 0)
+		tone.SetWaveform(w)
+		tone.Continue(time.Second)
+		spectrum := averageSpectrum(tone.Encode(sampleRate), sampleRate)
+		kept := bandPower(spectrum, sampleRate, 2960, 3040)
+		for _, alias := range []float64{500, 1000, 2000, 3500} {
+			// The harmonics at 4500 and 6000 Hz would fold back onto these frequencies.
+			if folded := bandPower(spectrum, sampleRate, alias-40, alias+40); folded > kept*1e-3 {
+				t.Errorf("waveform %d: expected no aliasing at %f Hz, but got %f of %f", w, alias,
+					folded, kept)
+			}
+		}
+	}
+}
+
+func TestToneTrackBreathiness(t *testing.T) {
+	const sampleRate = 16000
+	tone := func(breathiness float64) *ToneTrack {
+		res := NewToneTrack(200, 0.5, 0)
+		res.SetWaveform(GlottalWave)
+		res.SetSeed(3)
+		res.AdjustBreathiness(breathiness, 0)
+		res.Continue(time.Second)
+		return res
+	}
+
+	// The harmonics-to-noise ratio compares the power at the harmonics to the power between them.
+	hnr := func(samples []wav.Sample) float64 {
+		spectrum := averageSpectrum(samples, sampleRate)
+		var harmonics, noise float64
+		for k := 1; k <= 15; k++ {
+			freq := float64(k) * 200
+			harmonics += bandPower(spectrum, sampleRate, freq-20, freq+20)
+			noise += bandPower(spectrum, sampleRate, freq+60, freq+140)
+		}
+		return harmonics / noise
+	}
+	last := math.Inf(1)
+	for _, breathiness := range []float64{0, 0.1, 0.3, 0.6, 0.9, 1} {
+		track := tone(breathiness)
+		if track.Breathiness() != breathiness {
+			t.Errorf("expected a breathiness of %f but got %f", breathiness, track.Breathiness())
+		}
+		samples := track.Encode(sampleRate)
+		ratio := hnr(samples)
+		if ratio >= last {
+			t.Errorf("breathiness %f: expected the HNR to fall below %f, but got %f", breathiness,
+				last, ratio)
+		}
+		last = ratio
+
+		// The tone keeps its power, however much of it is noise.
+		if rms := RMSLevel(samples); math.Abs(rms-0.5/math.Sqrt2) > 0.03 {
+			t.Errorf("breathiness %f: expected an RMS of %f but got %f", breathiness,
+				0.5/math.Sqrt2, rms)
+		}
+	}
+
+	// Pure breath is only heard in the open phase of each period, which lasts 48 of its 80
+	// samples.
+	for i, sample := range tone(1).Encode(sampleRate) {
+		if phase := i % 80; phase > 48 && sample != 0 {
+			t.Fatalf("sample %d: expected silence in the closed phase but got %f", i, sample)
+		}
+	}
+
+	// Adjustments glide from one breathiness to the next.
+	glide := NewToneTrack(200, 0.5, 0)
+	glide.Continue(time.Millisecond * 500)
+	glide.AdjustBreathiness(0.8, time.Millisecond*500)
+	glide.Continue(time.Millisecond * 500)
+	samples := glide.Encode(sampleRate)
+	start, middle, end := hnr(samples[:sampleRate/2]), hnr(samples[sampleRate/2:sampleRate]),
+		hnr(samples[sampleRate:])
+	if !(start > middle && middle > end) {
+		t.Errorf("expected the HNR to fall through the glide, but got %f, %f, and %f", start,
+			middle, end)
+	}
+}
+
+func TestToneTrackJitterShimmer(t *testing.T) {
+	const sampleRate = 16000
+	tone := func(jitter, shimmer float64, seed int64) *ToneTrack {
+		res := NewToneTrack(200, 0.5, 0)
+		res.SetJitter(jitter)
+		res.SetShimmer(shimmer)
+		res.SetSeed(seed)
+		res.Continue(time.Second * 2)
+		return res
+	}
+
+	// f0Deviation measures the period of every cycle from its rising zero crossings, and returns
+	// the standard deviation of the resulting F0 as a percentage of its mean.
+	f0Deviation := func(samples []wav.Sample) float64 {
+		var crossings []float64
+		for i := 1; i < len(samples); i++ {
+			if samples[i-1] < 0 && samples[i] >= 0 {
+				frac := float64(-samples[i-1] / (samples[i] - samples[i-1]))
+				crossings = append(crossings, float64(i-1)+frac)
+			}
+		}
+		var sum, squares float64
+		for i := 1; i < len(crossings); i++ {
+			f0 := sampleRate / (crossings[i] - crossings[i-1])
+			sum += f0
+			squares += f0 * f0
+		}
+		n := float64(len(crossings) - 1)
+		mean := sum / n
+		return 100 * math.Sqrt(math.Max(0, squares/n-mean*mean)) / mean
+	}
+	if d := f0Deviation(tone(0, 0, 1).Encode(sampleRate)); d > 1e-3 {
+		t.Errorf("expected a steady pitch without jitter, but it deviated by %f%%", d)
+	}
+	for _, jitter := range []float64{0.5, 1, 2, 4} {
+		d := f0Deviation(tone(jitter, 0, 1).Encode(sampleRate))
+		if d < jitter*0.6 || d > jitter*1.2 {
+			t.Errorf("jitter %f%%: expected F0 to deviate by about as much, but got %f%%", jitter,
+				d)
+		}
+	}
+
+	// Shimmer wobbles the level of each 20ms window.
+	for _, shimmer := range []float64{0, 5, 10} {
+		levels := RMSSeries(tone(0, shimmer, 1), sampleRate, time.Millisecond*20)
+		var sum, squares float64
+		for _, level := range levels {
+			sum += level
+			squares += level * level
+		}
+		mean := sum / float64(len(levels))
+		d := 100 * math.Sqrt(math.Max(0, squares/float64(len(levels))-mean*mean)) / mean
+		if d < shimmer*0.4 || d > shimmer*1.2+0.1 {
+			t.Errorf("shimmer %f%%: expected the level to deviate by about as much, but got %f%%",
+				shimmer, d)
+		}
+	}
+
+	// Zero perturbation reproduces a plain tone, and the seed alone decides the perturbation.
+	plain := NewToneTrack(200, 0.5, 0)
+	plain.Continue(time.Second * 2)
+	assertSamplesClose(t, plain.Encode(sampleRate), tone(0, 0, 7).Encode(sampleRate), 0)
+	assertSamplesClose(t, tone(1, 3, 7).Encode(sampleRate), tone(1, 3, 7).Encode(sampleRate), 0)
+	if samplesEqual(tone(1, 3, 7).Encode(sampleRate), tone(1, 3, 8).Encode(sampleRate)) {
+		t.Error("expected different seeds to perturb the tone differently")
+	}
+}
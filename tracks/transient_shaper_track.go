@@ -0,0 +1,127 @@
+package tracks
+
+import (
+	"math"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// Time constants for the envelope followers in a TransientShaperTrack.
+const (
+	transientFastTime = time.Millisecond
+	transientSlowTime = time.Millisecond * 20
+	transientHoldTime = time.Millisecond * 200
+)
+
+// A TransientShaperTrack emphasizes or softens the attack and sustain portions of an inner track
+// independently, for example to make plosive bursts punchier.
+//
+// Transients are detected by comparing a fast envelope follower to a slow one: while the fast
+// envelope rises above the slow one, the signal is in an attack, and while it decays faster than
+// the slow one, the signal is in a sustain.
+type TransientShaperTrack struct {
+	inner   Track
+	attack  float64
+	sustain float64
+}
+
+// NewTransientShaperTrack creates a TransientShaperTrack around an inner track.
+// The attack and sustain arguments are gains applied to the respective portions of the sound,
+// where 1 leaves the sound unchanged.
+func NewTransientShaperTrack(inner Track, attack, sustain float64) *TransientShaperTrack {
+	return &TransientShaperTrack{inner: inner, attack: attack, sustain: sustain}
+}
+
+// Attack returns the gain applied to attacks.
+func (t *TransientShaperTrack) Attack() float64 {
+	return t.attack
+}
+
+// SetAttack sets the gain applied to attacks.
+func (t *TransientShaperTrack) SetAttack(gain float64) {
+	t.attack = gain
+}
+
+// Sustain returns the gain applied to sustains.
+func (t *TransientShaperTrack) Sustain() float64 {
+	return t.sustain
+}
+
+// SetSustain sets the gain applied to sustains.
+func (t *TransientShaperTrack) SetSustain(gain float64) {
+	t.sustain = gain
+}
+
+// Inner returns the track being shaped.
+func (t *TransientShaperTrack) Inner() Track {
+	return t.inner
+}
+
+func (t *TransientShaperTrack) Duration() time.Duration {
+	return t.inner.Duration()
+}
+
+func (t *TransientShaperTrack) Encode(sampleRate int) []wav.Sample {
+	res := t.inner.Encode(sampleRate)
+	fast := math.Exp(-1 / (transientFastTime.Seconds() * float64(sampleRate)))
+	slow := math.Exp(-1 / (transientSlowTime.Seconds() * float64(sampleRate)))
+	hold := math.Exp(-1 / (transientHoldTime.Seconds() * float64(sampleRate)))
+
+	// The attack followers share a long release and differ in attack time.
+	// The sustain followers share a fast attack and differ in release time.
+	var attackFast, attackSlow, sustainFast, sustainSlow float64
+	for i, sample := range res {
+		level := math.Abs(float64(sample))
+		attackFast = followEnvelope(attackFast, level, fast, hold)
+		attackSlow = followEnvelope(attackSlow, level, slow, hold)
+		sustainFast = followEnvelope(sustainFast, level, fast, slow)
+		sustainSlow = followEnvelope(sustainSlow, level, fast, hold)
+
+		var attackAmount, sustainAmount float64
+		if attackFast > 0 {
+			attackAmount = math.Max(0, attackFast-attackSlow) / attackFast
+		}
+		if sustainSlow > 0 {
+			sustainAmount = math.Max(0, sustainSlow-sustainFast) / sustainSlow
+		}
+		gain := 1 + (t.attack-1)*attackAmount + (t.sustain-1)*sustainAmount
+		res[i] = wav.Sample(float64(sample) * math.Max(0, gain))
+	}
+	return res
+}
+
+func (t *TransientShaperTrack) Continue(d time.Duration) {
+	t.inner.Continue(d)
+}
+
+func (t *TransientShaperTrack) Volume() float64 {
+	return t.inner.Volume()
+}
+
+func (t *TransientShaperTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	t.inner.AdjustVolume(newVolume, d)
+}
+
+func (t *TransientShaperTrack) AdjustVolumeCurve(newVolume float64, d time.Duration, curve Curve) {
+	AdjustVolumeCurve(t.inner, newVolume, d, curve)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (t *TransientShaperTrack) Clone() Track {
+	inner := cloneTrack(t.inner)
+	if inner == nil {
+		return nil
+	}
+	return &TransientShaperTrack{inner: inner, attack: t.attack, sustain: t.sustain}
+}
+
+// followEnvelope advances a one-pole envelope follower by one sample, using the attack
+// coefficient when the level is rising and the release coefficient when it is falling.
+func followEnvelope(env, level, attackCoeff, releaseCoeff float64) float64 {
+	coeff := releaseCoeff
+	if level > env {
+		coeff = attackCoeff
+	}
+	return coeff*env + (1-coeff)*level
+}
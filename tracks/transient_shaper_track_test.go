@@ -0,0 +1,45 @@
+package tracks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTransientShaperTrackAttack(t *testing.T) {
+	const sampleRate = 22050
+	attackRatio := func(attack float64) float64 {
+		// The hit starts at full volume and decays, like a percussive sound.
+		hit := NewToneTrack(200, 0.5, 0)
+		hit.AdjustVolume(0.05, time.Millisecond*300)
+		shaper := NewTransientShaperTrack(hit, attack, 1)
+		samples := shaper.Encode(sampleRate)
+		head := RMSLevel(samples[:sampleRate/100])
+		tail := RMSLevel(samples[sampleRate/10 : sampleRate/5])
+		return head / tail
+	}
+	neutral, boosted := attackRatio(1), attackRatio(3)
+	if boosted < neutral*1.5 {
+		t.Errorf("expected a larger attack to raise the transient, but got ratios %f and %f",
+			neutral, boosted)
+	}
+	if softened := attackRatio(0.3); softened > neutral/1.5 {
+		t.Errorf("expected a smaller attack to soften the transient, but got ratios %f and %f",
+			neutral, softened)
+	}
+}
+
+func TestTransientShaperTrackContinue(t *testing.T) {
+	hit := NewToneTrack(200, 0.5, 0)
+	hit.AdjustVolume(0.05, time.Millisecond*300)
+	shaper := NewTransientShaperTrack(hit, 3, 0.5)
+	before := shaper.Encode(8000)
+	shaper.Continue(time.Millisecond * 200)
+	if d := shaper.Duration(); d != time.Millisecond*500 {
+		t.Fatalf("expected duration 500ms but got %v", d)
+	}
+	after := shaper.Encode(8000)
+	assertSamplesClose(t, before, after[:len(before)], 1e-12)
+
+	unshaped := NewTransientShaperTrack(hit, 1, 1)
+	assertSamplesClose(t, hit.Encode(8000), unshaped.Encode(8000), 1e-12)
+}
@@ -0,0 +1,174 @@
+package tracks
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// minVarispeed is the slowest speed a VarispeedTrack will play at.
+const minVarispeed = 0.01
+
+// A VarispeedTrack plays an inner track at a varying speed, like a tape machine: speeding up
+// raises the pitch and shortens the sound, while slowing down does the opposite.
+//
+// The speed is automated by keyframes placed on the VarispeedTrack's own timeline.
+// Between keyframes, the speed changes linearly, and after the last keyframe, it stays constant.
+type VarispeedTrack struct {
+	inner     Track
+	keyframes []varispeedKeyframe
+}
+
+// NewVarispeedTrack creates a VarispeedTrack which starts playing inner at the given speed.
+func NewVarispeedTrack(inner Track, speed float64) *VarispeedTrack {
+	return &VarispeedTrack{
+		inner:     inner,
+		keyframes: []varispeedKeyframe{{speed: math.Max(speed, minVarispeed)}},
+	}
+}
+
+// SetSpeed sets the speed at the given time, replacing any existing keyframe at that time.
+func (v *VarispeedTrack) SetSpeed(at time.Duration, speed float64) {
+	if at < 0 {
+		at = 0
+	}
+	keyframe := varispeedKeyframe{time: at.Seconds(), speed: math.Max(speed, minVarispeed)}
+	idx := sort.Search(len(v.keyframes), func(i int) bool {
+		return v.keyframes[i].time >= keyframe.time
+	})
+	if idx < len(v.keyframes) && v.keyframes[idx].time == keyframe.time {
+		v.keyframes[idx] = keyframe
+		return
+	}
+	v.keyframes = append(v.keyframes, varispeedKeyframe{})
+	copy(v.keyframes[idx+1:], v.keyframes[idx:])
+	v.keyframes[idx] = keyframe
+}
+
+// Inner returns the track being played at a varying speed.
+func (v *VarispeedTrack) Inner() Track {
+	return v.inner
+}
+
+// Duration returns the time it takes to play through the entire inner track.
+func (v *VarispeedTrack) Duration() time.Duration {
+	return time.Duration(v.outputTime(v.inner.Duration().Seconds()) * float64(time.Second))
+}
+
+func (v *VarispeedTrack) Encode(sampleRate int) []wav.Sample {
+	innerSamples := v.inner.Encode(sampleRate)
+	res := make([]wav.Sample, sampleCount(v.Duration(), sampleRate))
+	for i := range res {
+		t := float64(i) / float64(sampleRate)
+		pos := v.innerTime(t) * float64(sampleRate)
+		res[i] = wav.Sample(interpolateSinc(innerSamples, pos, 1/v.speedAt(t)))
+	}
+	return res
+}
+
+// Continue elongates the inner track.
+func (v *VarispeedTrack) Continue(d time.Duration) {
+	v.inner.Continue(d)
+}
+
+func (v *VarispeedTrack) Volume() float64 {
+	return v.inner.Volume()
+}
+
+// AdjustVolume elongates the inner track while adjusting its volume.
+// The durations given to Continue and AdjustVolume are measured on the inner track's timeline.
+func (v *VarispeedTrack) AdjustVolume(newVolume float64, d time.Duration) {
+	v.inner.AdjustVolume(newVolume, d)
+}
+
+// Clone creates a copy of the track, or returns nil if the inner track cannot be cloned.
+func (v *VarispeedTrack) Clone() Track {
+	inner := cloneTrack(v.inner)
+	if inner == nil {
+		return nil
+	}
+	keyframes := append([]varispeedKeyframe{}, v.keyframes...)
+	return &VarispeedTrack{inner: inner, keyframes: keyframes}
+}
+
+// speedAt returns the speed at a time, in seconds.
+func (v *VarispeedTrack) speedAt(t float64) float64 {
+	idx := v.segmentAt(t)
+	start, end := v.segment(idx)
+	if end.time == start.time {
+		return start.speed
+	}
+	frac := (t - start.time) / (end.time - start.time)
+	return start.speed + (end.speed-start.speed)*frac
+}
+
+// innerTime maps a time on the VarispeedTrack's timeline to a time on the inner track's
+// timeline, both in seconds.
+func (v *VarispeedTrack) innerTime(t float64) float64 {
+	var res float64
+	idx := v.segmentAt(t)
+	for i := 0; i < idx; i++ {
+		start, end := v.segment(i)
+		res += (start.speed + end.speed) / 2 * (end.time - start.time)
+	}
+	start, end := v.segment(idx)
+	x := t - start.time
+	res += start.speed * x
+	if end.time > start.time {
+		res += (end.speed - start.speed) / (2 * (end.time - start.time)) * x * x
+	}
+	return res
+}
+
+// outputTime is the inverse of innerTime.
+func (v *VarispeedTrack) outputTime(inner float64) float64 {
+	remaining := inner
+	for i := 0; i < len(v.keyframes)-1; i++ {
+		start, end := v.segment(i)
+		length := end.time - start.time
+		area := (start.speed + end.speed) / 2 * length
+		if remaining <= area {
+			// Solve a*x^2 + b*x = remaining for the time x into the segment.
+			a := (end.speed - start.speed) / (2 * length)
+			b := start.speed
+			if math.Abs(a) < 1e-12 {
+				return start.time + remaining/b
+			}
+			return start.time + (-b+math.Sqrt(b*b+4*a*remaining))/(2*a)
+		}
+		remaining -= area
+	}
+	last := v.keyframes[len(v.keyframes)-1]
+	return last.time + remaining/last.speed
+}
+
+// segmentAt returns the index of the segment containing a time, in seconds.
+// The first keyframe is always at time zero.
+func (v *VarispeedTrack) segmentAt(t float64) int {
+	idx := sort.Search(len(v.keyframes), func(i int) bool {
+		return v.keyframes[i].time > t
+	})
+	if idx == 0 {
+		return 0
+	}
+	return idx - 1
+}
+
+// segment returns the keyframes at the start and end of the i-th segment.
+// The last segment extends forever at a constant speed.
+func (v *VarispeedTrack) segment(i int) (start, end varispeedKeyframe) {
+	start = v.keyframes[i]
+	if i+1 < len(v.keyframes) {
+		end = v.keyframes[i+1]
+	} else {
+		end = start
+	}
+	return
+}
+
+type varispeedKeyframe struct {
+	time  float64
+	speed float64
+}
@@ -0,0 +1,53 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVarispeedTrackDoubleSpeed(t *testing.T) {
+	const sampleRate = 16000
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.Continue(time.Second)
+	fast := NewVarispeedTrack(tone, 2)
+	if d := fast.Duration(); d != time.Millisecond*500 {
+		t.Fatalf("expected duration 500ms but got %v", d)
+	}
+
+	// Playing the samples twice as fast is the same as treating them as recorded at twice the
+	// sample rate.
+	samples := fast.Encode(sampleRate)
+	resampled, err := Resample(tone.Encode(sampleRate), sampleRate*2, sampleRate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSamplesClose(t, resampled, samples, 1e-9)
+
+	// Away from the edges, the result is the tone an octave up.
+	for i := 500; i < len(samples)-500; i++ {
+		expected := 0.5 * math.Sin(2*math.Pi*600*float64(i)/sampleRate)
+		if math.Abs(float64(samples[i])-expected) > 1e-3 {
+			t.Fatalf("sample %d: expected %f but got %f", i, expected, samples[i])
+		}
+	}
+}
+
+func TestVarispeedTrackKeyframes(t *testing.T) {
+	tone := NewToneTrack(300, 0.5, 0)
+	tone.Continue(time.Second)
+	track := NewVarispeedTrack(tone, 1)
+	track.SetSpeed(time.Millisecond*400, 1)
+	track.SetSpeed(time.Millisecond*600, 3)
+
+	// The first 400ms play at normal speed and the ramp plays 400ms of the inner track, leaving
+	// 200ms to play at triple speed.
+	expected := time.Millisecond*600 + time.Millisecond*200/3
+	if d := track.Duration(); d < expected-time.Microsecond || d > expected+time.Microsecond {
+		t.Errorf("expected duration %v but got %v", expected, d)
+	}
+	track.SetSpeed(time.Millisecond*600, 1)
+	if d := track.Duration(); d != time.Second {
+		t.Errorf("expected replacing a keyframe to restore duration 1s but got %v", d)
+	}
+}
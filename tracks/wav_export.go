@@ -0,0 +1,151 @@
+package tracks
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/unixpickle/wav"
+)
+
+// EncodeOptions describes the format of an exported WAV file.
+type EncodeOptions struct {
+	SampleRate int
+
+	// BitDepth is 8, 16, or 24.
+	BitDepth int
+
+	// Channels is 1 for mono or 2 for stereo.
+	// Stereo exports use EncodeStereo for StereoTracks and duplicate the mono
+	// encoding of other tracks.
+	Channels int
+}
+
+func (e EncodeOptions) validate() error {
+	if e.SampleRate <= 0 {
+		return errors.New("invalid sample rate: " + strconv.Itoa(e.SampleRate))
+	}
+	switch e.BitDepth {
+	case 8, 16, 24:
+	default:
+		return errors.New("unsupported bit depth: " + strconv.Itoa(e.BitDepth))
+	}
+	if e.Channels != 1 && e.Channels != 2 {
+		return errors.New("unsupported channel count: " + strconv.Itoa(e.Channels))
+	}
+	return nil
+}
+
+// WriteWAVFile writes a track to a 16-bit mono WAV file.
+func WriteWAVFile(t Track, path string, sampleRate int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = WriteWAV(t, f, EncodeOptions{SampleRate: sampleRate, BitDepth: 16, Channels: 1})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// WriteWAV writes a track as a PCM WAV file.
+//
+// Mono exports are streamed, so only a chunk of the track is in memory at once.
+func WriteWAV(t Track, w io.Writer, opts EncodeOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	frameCount := sampleCount(t.Duration(), opts.SampleRate)
+	var stream SampleStream
+	if opts.Channels == 1 {
+		stream = Stream(t, opts.SampleRate)
+	} else {
+		stream = &sliceStream{samples: encodeStereo(t, opts.SampleRate)}
+	}
+
+	writer := bufio.NewWriter(w)
+	if err := writeWAVHeader(writer, opts, frameCount*opts.Channels); err != nil {
+		return err
+	}
+
+	// Every track produces the sample count its duration implies, but the
+	// stream is padded or truncated regardless so that the header is correct.
+	bytesPerSample := opts.BitDepth / 8
+	remaining := frameCount * opts.Channels
+	buf := make([]wav.Sample, streamChunkSize)
+	data := make([]byte, streamChunkSize*bytesPerSample)
+	for remaining > 0 {
+		chunk := buf
+		if len(chunk) > remaining {
+			chunk = chunk[:remaining]
+		}
+		n := stream.Read(chunk)
+		for i := n; i < len(chunk); i++ {
+			chunk[i] = 0
+		}
+		for i, sample := range chunk {
+			putPCMSample(data[i*bytesPerSample:], sample, opts.BitDepth)
+		}
+		if _, err := writer.Write(data[:len(chunk)*bytesPerSample]); err != nil {
+			return err
+		}
+		remaining -= len(chunk)
+	}
+	if (frameCount*opts.Channels*bytesPerSample)%2 == 1 {
+		// RIFF chunks are padded to an even number of bytes.
+		if err := writer.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+func writeWAVHeader(w io.Writer, opts EncodeOptions, sampleCount int) error {
+	bytesPerSample := opts.BitDepth / 8
+	dataSize := uint32(sampleCount * bytesPerSample)
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + dataSize + dataSize%2),
+		[4]byte{'W', 'A', 'V', 'E'},
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16),
+		uint16(1),
+		uint16(opts.Channels),
+		uint32(opts.SampleRate),
+		uint32(opts.SampleRate * opts.Channels * bytesPerSample),
+		uint16(opts.Channels * bytesPerSample),
+		uint16(opts.BitDepth),
+		[4]byte{'d', 'a', 't', 'a'},
+		dataSize,
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putPCMSample encodes a sample as little-endian PCM of the given bit depth, clamping it to the
+// range [-1, 1].
+// Like the WAV format itself, 8-bit samples are unsigned and deeper samples are signed.
+func putPCMSample(buf []byte, sample wav.Sample, bitDepth int) {
+	value := clampSample(sample)
+	switch bitDepth {
+	case 8:
+		buf[0] = byte(128 + int(math.Round(value*127)))
+	case 16:
+		binary.LittleEndian.PutUint16(buf, uint16(pcm16Value(sample)))
+	case 24:
+		scaled := int32(math.Round(value * (1<<23 - 1)))
+		buf[0] = byte(scaled)
+		buf[1] = byte(scaled >> 8)
+		buf[2] = byte(scaled >> 16)
+	}
+}
@@ -0,0 +1,102 @@
+package tracks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestWriteWAVRoundTrip(t *testing.T) {
+	tone := NewToneTrack(440, 0.5, 0)
+	tone.Continue(time.Millisecond * 300)
+	for _, opts := range []EncodeOptions{
+		{SampleRate: 8000, BitDepth: 16, Channels: 1},
+		{SampleRate: 22050, BitDepth: 16, Channels: 2},
+		{SampleRate: 8000, BitDepth: 8, Channels: 1},
+	} {
+		var buf bytes.Buffer
+		if err := WriteWAV(tone, &buf, opts); err != nil {
+			t.Fatal(err)
+		}
+		sound, err := wav.ReadSound(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sound.SampleRate() != opts.SampleRate || sound.Channels() != opts.Channels {
+			t.Errorf("%v: got %d channels at %d Hz", opts, sound.Channels(), sound.SampleRate())
+		}
+		expected := tone.Encode(opts.SampleRate)
+		if opts.Channels == 2 {
+			expected = interleave(expected, expected)
+		}
+		epsilon := 1.0 / 32767
+		if opts.BitDepth == 8 {
+			epsilon = 1.0 / 127
+		}
+		assertSamplesClose(t, expected, sound.Samples(), epsilon)
+	}
+}
+
+func TestWriteWAVHeader(t *testing.T) {
+	// 7 samples of 24 bits is an odd number of bytes, so the data chunk is padded.
+	d := sampleTime(7, 8000)
+	var buf bytes.Buffer
+	opts := EncodeOptions{SampleRate: 8000, BitDepth: 24, Channels: 1}
+	if err := WriteWAV(NewSilenceTrack(d), &buf, opts); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if len(data) != 44+21+1 {
+		t.Fatalf("expected %d bytes but got %d", 44+21+1, len(data))
+	}
+	for _, field := range []struct {
+		offset   int
+		expected uint32
+	}{
+		{4, 36 + 22},
+		{24, 8000},
+		{28, 8000 * 3},
+		{40, 21},
+	} {
+		if v := binary.LittleEndian.Uint32(data[field.offset:]); v != field.expected {
+			t.Errorf("header offset %d: expected %d but got %d", field.offset, field.expected, v)
+		}
+	}
+	if bits := binary.LittleEndian.Uint16(data[34:]); bits != 24 {
+		t.Errorf("expected 24 bits per sample but got %d", bits)
+	}
+}
+
+func TestWriteWAVFile(t *testing.T) {
+	tone := NewToneTrack(300, 0.4, 0)
+	tone.Continue(time.Millisecond * 100)
+	path := filepath.Join(t.TempDir(), "tone.wav")
+	if err := WriteWAVFile(tone, path, 16000); err != nil {
+		t.Fatal(err)
+	}
+	sound, err := wav.ReadSoundFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSamplesClose(t, tone.Encode(16000), sound.Samples(), 1.0/32767)
+}
+
+func TestWriteWAVInvalidOptions(t *testing.T) {
+	for _, opts := range []EncodeOptions{
+		{SampleRate: 0, BitDepth: 16, Channels: 1},
+		{SampleRate: 8000, BitDepth: 32, Channels: 1},
+		{SampleRate: 8000, BitDepth: 16, Channels: 3},
+	} {
+		var buf bytes.Buffer
+		if err := WriteWAV(NewSilenceTrack(time.Second), &buf, opts); err == nil {
+			t.Errorf("%v: expected an error", opts)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%v: expected nothing to be written but got %d bytes", opts, buf.Len())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package tracks
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// DefaultOpenQuotient is the open quotient of a glottal pulse in modal voice.
+const DefaultOpenQuotient = 0.6
+
+// waveformPeriodSize is the number of points at which one period of a waveform is sampled to
+// compute its harmonics.
+const waveformPeriodSize = 2048
+
+// A Waveform selects the shape of each period of a ToneTrack.
+//
+// Every waveform other than SineWave is synthesized from its harmonics, and harmonics above the
+// Nyquist frequency of the sample rate are left out, so high tones do not alias.
+// The waveforms are scaled to the power of a sine of the same volume.
+type Waveform int
+
+const (
+	// SineWave is a pure tone.
+	SineWave Waveform = iota
+
+	// SawtoothWave has every harmonic, falling off at 6 dB per octave.
+	SawtoothWave
+
+	// PulseWave is high for the open quotient of each period and low for the rest.
+	PulseWave
+
+	// GlottalWave models the airflow through the vocal folds, after the LF model.
+	// The flow rises smoothly for most of the open phase, then falls abruptly as the folds snap
+	// shut, and stays closed for the rest of the period.
+	// The waveform is the derivative of the flow, which accounts for the radiation at the lips.
+	// The abrupt closure excites every harmonic, and they fall off at about 6 dB per octave.
+	GlottalWave
+)
+
+// waveformHarmonics returns the cosine and sine coefficients of the harmonics of a waveform,
+// starting with the fundamental.
+// The coefficients are normalized so that their squares sum to 1.
+func waveformHarmonics(w Waveform, openQuotient float64) (cos, sin []float64) {
+	if w == SineWave {
+		return []float64{0}, []float64{1}
+	}
+	openQuotient = clampOpenQuotient(openQuotient)
+	period := make([]complex128, waveformPeriodSize)
+	for i := range period {
+		period[i] = complex(waveformPoint(w, openQuotient, float64(i)/waveformPeriodSize), 0)
+	}
+	fft(period)
+
+	count := waveformPeriodSize/2 - 1
+	cos = make([]float64, count)
+	sin = make([]float64, count)
+	var power float64
+	for k := range cos {
+		coeff := period[k+1] * 2 / waveformPeriodSize
+		cos[k], sin[k] = real(coeff), -imag(coeff)
+		power += cmplx.Abs(coeff) * cmplx.Abs(coeff)
+	}
+	if power > 0 {
+		scale := 1 / math.Sqrt(power)
+		for k := range cos {
+			cos[k] *= scale
+			sin[k] *= scale
+		}
+	}
+	return
+}
+
+// clampOpenQuotient keeps an open quotient within the range in which both phases of a glottal
+// period have some length.
+func clampOpenQuotient(q float64) float64 {
+	return math.Max(0.05, math.Min(0.95, q))
+}
+
+// waveformPoint evaluates a waveform at a phase, in turns.
+// The DC component does not matter, since it is dropped with the other coefficients.
+func waveformPoint(w Waveform, openQuotient, phase float64) float64 {
+	switch w {
+	case SawtoothWave:
+		return 2*phase - 1
+	case PulseWave:
+		if phase < openQuotient {
+			return 1
+		}
+		return 0
+	case GlottalWave:
+		return glottalFlow(openQuotient, phase+0.5/waveformPeriodSize) -
+			glottalFlow(openQuotient, phase-0.5/waveformPeriodSize)
+	}
+	return math.Sin(2 * math.Pi * phase)
+}
+
+// glottalFlow evaluates the airflow of a glottal pulse, which peaks two thirds of the way into
+// the open phase.
+func glottalFlow(openQuotient, phase float64) float64 {
+	phase -= math.Floor(phase)
+	peak := openQuotient * 2 / 3
+	if phase < peak {
+		return 0.5 - 0.5*math.Cos(math.Pi*phase/peak)
+	} else if phase < openQuotient {
+		return math.Cos(math.Pi / 2 * (phase - peak) / (openQuotient - peak))
+	}
+	return 0
+}
@@ -0,0 +1,178 @@
+package tracks
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+// A WeightedTrackSet is a TrackSet which scales each member by a gain while mixing.
+//
+// Gains balance a mix without changing the members' own volumes.
+// Members without a gain are mixed at a gain of 1, and nested weighted sets compose, so a
+// member's overall gain is the product of the gains along its path.
+type WeightedTrackSet struct {
+	set   TrackSet
+	gains map[TrackID]float64
+}
+
+// NewWeightedTrackSet creates a WeightedTrackSet with the members of a TrackSet, all at a gain
+// of 1.
+// The TrackSet is shared, so adding members to it adds them to the weighted set.
+func NewWeightedTrackSet(set TrackSet) *WeightedTrackSet {
+	if set == nil {
+		set = TrackSet{}
+	}
+	return &WeightedTrackSet{set: set, gains: map[TrackID]float64{}}
+}
+
+// Tracks returns the unweighted members of the set.
+func (w *WeightedTrackSet) Tracks() TrackSet {
+	return w.set
+}
+
+// Gain returns the gain of the member with the given ID, which is 1 unless it has been set.
+func (w *WeightedTrackSet) Gain(id TrackID) float64 {
+	if gain, ok := w.gains[id]; ok {
+		return gain
+	}
+	return 1
+}
+
+// SetGain sets the gain of the member with the given ID.
+// The gain is kept if the member is replaced, and it may be set before the member is added.
+func (w *WeightedTrackSet) SetGain(id TrackID, gain float64) {
+	w.gains[id] = gain
+}
+
+// ExcludeTracks returns a WeightedTrackSet of the members that do not have the given track IDs.
+// The returned set shares its members with w and keeps their gains.
+func (w *WeightedTrackSet) ExcludeTracks(ids ...TrackID) *WeightedTrackSet {
+	return w.view(w.set.ExcludeTracks(ids...))
+}
+
+// IncludeTracks returns a WeightedTrackSet of the members that have the given track IDs.
+// The returned set shares its members with w and keeps their gains.
+func (w *WeightedTrackSet) IncludeTracks(ids ...TrackID) *WeightedTrackSet {
+	return w.view(w.set.IncludeTracks(ids...))
+}
+
+// Filter returns a WeightedTrackSet of the members for which pred returns true.
+// The returned set shares its members with w and keeps their gains.
+func (w *WeightedTrackSet) Filter(pred func(id TrackID, track Track) bool) *WeightedTrackSet {
+	return w.view(w.set.Filter(pred))
+}
+
+func (w *WeightedTrackSet) view(set TrackSet) *WeightedTrackSet {
+	res := NewWeightedTrackSet(set)
+	for id := range set {
+		if gain, ok := w.gains[id]; ok {
+			res.gains[id] = gain
+		}
+	}
+	return res
+}
+
+func (w *WeightedTrackSet) Duration() time.Duration {
+	return w.set.Duration()
+}
+
+// Encode is like TrackSet.Encode, but each member's signal is scaled by its gain before the
+// signals are summed.
+func (w *WeightedTrackSet) Encode(sampleRate int) []wav.Sample {
+	workers := runtime.GOMAXPROCS(0)
+	ids, encodedTracks := w.set.encodeMembers(sampleRate, workers)
+	for i, id := range ids {
+		encodedTracks[i] = scaledSamples(encodedTracks[i], w.Gain(id))
+	}
+	return mixSamplesWorkers(encodedTracks, workers)
+}
+
+// EncodeStereo is like TrackSet.EncodeStereo, but each member's signal is scaled by its gain.
+func (w *WeightedTrackSet) EncodeStereo(sampleRate int) []wav.Sample {
+	ids := w.set.sortedIDs()
+	encodedTracks := make([][]wav.Sample, len(ids))
+	for i, id := range ids {
+		encodedTracks[i] = scaledSamples(encodeStereo(w.set[id], sampleRate), w.Gain(id))
+	}
+	return mixSamples(encodedTracks)
+}
+
+func (w *WeightedTrackSet) Continue(duration time.Duration) {
+	w.set.Continue(duration)
+}
+
+// EvenOut is like TrackSet.EvenOut.
+func (w *WeightedTrackSet) EvenOut() {
+	w.set.EvenOut()
+}
+
+// Volume returns the sum of the members' volumes, each scaled by its gain.
+func (w *WeightedTrackSet) Volume() (sum float64) {
+	for id, track := range w.set {
+		sum += w.Gain(id) * track.Volume()
+	}
+	return
+}
+
+// AdjustVolume elongates all of the members while adjusting their volumes, such that every
+// member with a non-zero gain contributes equally to a Volume of newVolume.
+// Members with a gain of 0 are silenced.
+func (w *WeightedTrackSet) AdjustVolume(newVolume float64, duration time.Duration) {
+	for id, track := range w.set {
+		track.AdjustVolume(w.memberVolume(id, newVolume), duration)
+	}
+}
+
+// AdjustVolumeCurve is like AdjustVolume, but every track's volume follows the given curve.
+// Tracks which are not CurvedTracks transition linearly.
+func (w *WeightedTrackSet) AdjustVolumeCurve(newVolume float64, duration time.Duration,
+	curve Curve) {
+	for id, track := range w.set {
+		AdjustVolumeCurve(track, w.memberVolume(id, newVolume), duration, curve)
+	}
+}
+
+// memberVolume returns the volume which gives a member an equal share of a set volume.
+func (w *WeightedTrackSet) memberVolume(id TrackID, newVolume float64) float64 {
+	gain := w.Gain(id)
+	if gain == 0 {
+		return 0
+	}
+	var audible int
+	for id := range w.set {
+		if w.Gain(id) != 0 {
+			audible++
+		}
+	}
+	return newVolume / (float64(audible) * gain)
+}
+
+// Clone creates a WeightedTrackSet with a deep copy of the members and the same gains, or
+// returns nil if some member cannot be cloned.
+func (w *WeightedTrackSet) Clone() Track {
+	set, err := w.set.Clone()
+	if err != nil {
+		return nil
+	}
+	res := NewWeightedTrackSet(set)
+	for id, gain := range w.gains {
+		res.gains[id] = gain
+	}
+	return res
+}
+
+// scaledSamples returns a signal scaled by a gain.
+// The signal is copied rather than scaled in place, since a track may return samples it keeps
+// internally.
+func scaledSamples(samples []wav.Sample, gain float64) []wav.Sample {
+	if gain == 1 {
+		return samples
+	}
+	res := make([]wav.Sample, len(samples))
+	for i, sample := range samples {
+		res[i] = sample * wav.Sample(gain)
+	}
+	return res
+}
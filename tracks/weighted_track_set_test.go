@@ -0,0 +1,74 @@
+package tracks
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/wav"
+)
+
+func TestWeightedTrackSetGain(t *testing.T) {
+	const sampleRate = 16000
+	low := NewToneTrack(200, 0.4, 0)
+	high := NewToneTrack(900, 0.4, 0)
+	set := NewWeightedTrackSet(TrackSet{"low": low, "high": high})
+	set.Continue(time.Millisecond * 100)
+	set.SetGain("high", 0.5)
+
+	lowSamples, highSamples := low.Encode(sampleRate), high.Encode(sampleRate)
+	expected := make([]wav.Sample, len(lowSamples))
+	for i := range expected {
+		expected[i] = lowSamples[i] + highSamples[i]*0.5
+	}
+	assertSamplesClose(t, expected, set.Encode(sampleRate), 0)
+	if g := set.Gain("low"); g != 1 {
+		t.Errorf("expected a default gain of 1 but got %f", g)
+	}
+	if v := set.Volume(); math.Abs(v-0.6) > 1e-9 {
+		t.Errorf("expected a weighted volume of 0.6 but got %f", v)
+	}
+
+	// The members themselves are not scaled.
+	assertSamplesClose(t, highSamples, set.Tracks()["high"].Encode(sampleRate), 0)
+
+	// Views keep the gains of their members.
+	for _, view := range []*WeightedTrackSet{
+		set.ExcludeTracks("low"),
+		set.IncludeTracks("high"),
+		set.Filter(func(id TrackID, track Track) bool { return id == "high" }),
+	} {
+		if view.Gain("high") != 0.5 {
+			t.Errorf("expected the view to keep a gain of 0.5 but got %f", view.Gain("high"))
+		}
+		assertSamplesClose(t, scaledSamples(highSamples, 0.5), view.Encode(sampleRate), 0)
+	}
+
+	// Gains compose through nesting.
+	outer := NewWeightedTrackSet(TrackSet{"inner": set.IncludeTracks("high")})
+	outer.SetGain("inner", 0.5)
+	assertSamplesClose(t, scaledSamples(highSamples, 0.25), outer.Encode(sampleRate), 0)
+	if v := outer.Volume(); math.Abs(v-0.1) > 1e-9 {
+		t.Errorf("expected a nested volume of 0.1 but got %f", v)
+	}
+}
+
+func TestWeightedTrackSetAdjustVolume(t *testing.T) {
+	set := NewWeightedTrackSet(TrackSet{
+		"a":     NewToneTrack(200, 0.4, 0),
+		"b":     NewToneTrack(300, 0.4, 0),
+		"muted": NewToneTrack(400, 0.4, 0),
+	})
+	set.SetGain("b", 0.25)
+	set.SetGain("muted", 0)
+	set.AdjustVolume(0.5, time.Millisecond*10)
+	if v := set.Volume(); math.Abs(v-0.5) > 1e-9 {
+		t.Errorf("expected a volume of 0.5 but got %f", v)
+	}
+	if v := set.Tracks()["muted"].Volume(); v != 0 {
+		t.Errorf("expected the muted member to be silenced, but got %f", v)
+	}
+	if a, b := set.Tracks()["a"].Volume(), set.Tracks()["b"].Volume(); math.Abs(a-b/4) > 1e-9 {
+		t.Errorf("expected both members to contribute equally, but got %f and %f", a, b)
+	}
+}
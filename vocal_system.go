@@ -6,6 +6,19 @@ import (
 	"github.com/unixpickle/gospeech/tracks"
 )
 
+const (
+	// whisperFormantSpread is the spread that whispering adds to the formants, which widens
+	// them into bands of noise a couple hundred Hz wide.
+	whisperFormantSpread = 800
+
+	// whisperAspirationSpread is the spread that whispering adds to the consonant voice.
+	whisperAspirationSpread = 1500
+
+	// whisperAspirationGain is the level of the whispered consonant voice, relative to the
+	// voicing it replaces.
+	whisperAspirationGain = 0.5
+)
+
 // FormantState represents an instantaneous state of three formants.
 type FormantState struct {
 	Frequencies [3]float64
@@ -27,11 +40,11 @@ type VocalSystem struct {
 // NewVocalSystem creates a VocalSystem that is currently silent.
 func NewVocalSystem() VocalSystem {
 	return VocalSystem{tracks.TrackSet{
-		"Formants": tracks.TrackSet{
+		"Formants": tracks.NewNotchTrack(tracks.TrackSet{
 			"F1": tracks.NewToneTrack(400, 0, 0),
 			"F2": tracks.NewToneTrack(1000, 0, 0),
 			"F3": tracks.NewToneTrack(2000, 0, 0),
-		},
+		}, 1000, 300, 0),
 		"Turbulence": tracks.TrackSet{
 			"S":  tracks.NewToneTrack(5000, 0, 1000),
 			"SH": tracks.NewToneTrack(3500, 0, 2000),
@@ -53,6 +66,7 @@ func NewVocalSystem() VocalSystem {
 				"F2": tracks.NewToneTrack(2250, 0, 500),
 				"F3": tracks.NewToneTrack(2890, 0, 500),
 			},
+			"Burst": tracks.NewToneTrack(1000, 0, 500),
 		},
 		"ConsonantVoice": tracks.TrackSet{
 			//"Humm1": tracks.NewToneTrack(400, 0, 0),
@@ -64,34 +78,55 @@ func NewVocalSystem() VocalSystem {
 
 // FormantsTrack returns the track corresponding to the formants as a whole.
 func (v VocalSystem) FormantsTrack() tracks.TrackSet {
-	return v.TrackSet[tracks.TrackID("Formants")].(tracks.TrackSet)
+	return v.AntiFormant().Inner().(tracks.TrackSet)
+}
+
+// AntiFormant returns the notch which cuts an anti-formant into the formants, as heard in nasals.
+// Its depth is 0 outside of nasals.
+func (v VocalSystem) AntiFormant() *tracks.NotchTrack {
+	return v.TrackSet[tracks.TrackID("Formants")].(*tracks.NotchTrack)
 }
 
 // Formants returns the current formant state.
 func (v VocalSystem) Formants() FormantState {
-	freqs := map[string]float64{}
-	volumes := map[string]float64{}
-	for name, track := range v.FormantsTrack() {
-		tone := track.(*tracks.ToneTrack)
-		freqs[string(name)] = tone.Frequency()
-		volumes[string(name)] = tone.Volume()
+	var res FormantState
+	for i, tone := range v.FormantTracks() {
+		res.Frequencies[i] = tone.Frequency()
+		res.Volumes[i] = tone.Volume()
 	}
-	return FormantState{
-		Frequencies: [3]float64{freqs["F1"], freqs["F2"], freqs["F3"]},
-		Volumes:     [3]float64{volumes["F1"], volumes["F2"], volumes["F3"]},
+	return res
+}
+
+// formantIDs lists the IDs of the formant tracks from F1 to F3.
+var formantIDs = []tracks.TrackID{"F1", "F2", "F3"}
+
+// FormantTracks returns the tones of the formants, ordered from F1 to F3.
+func (v VocalSystem) FormantTracks() []*tracks.ToneTrack {
+	set := v.FormantsTrack()
+	res := make([]*tracks.ToneTrack, len(formantIDs))
+	for i, id := range formantIDs {
+		res[i] = set[id].(*tracks.ToneTrack)
 	}
+	return res
 }
 
 // AdjustFormants adjusts the formant state over a period of time.
+// The formants glide from their current state, with no bandwidth.
 func (v VocalSystem) AdjustFormants(state FormantState, d time.Duration) {
-	freqs := map[string]float64{"F1": state.Frequencies[0], "F2": state.Frequencies[1],
-		"F3": state.Frequencies[2]}
-	volumes := map[string]float64{"F1": state.Volumes[0], "F2": state.Volumes[1],
-		"F3": state.Volumes[2]}
-	for name, track := range v.FormantsTrack() {
-		n := string(name)
-		track.(*tracks.ToneTrack).AdjustAll(freqs[n], volumes[n], 0, d)
+	targets := make([]tracks.FormantTarget, 3)
+	for i := range targets {
+		targets[i] = tracks.FormantTarget{
+			Frequency: state.Frequencies[i],
+			Amplitude: state.Volumes[i],
+		}
 	}
+	v.AdjustFormantTargets(targets, d)
+}
+
+// AdjustFormantTargets glides the formants, starting with F1, to the given targets over a period
+// of time.
+func (v VocalSystem) AdjustFormantTargets(targets []tracks.FormantTarget, d time.Duration) {
+	tracks.AdjustFormants(v.FormantTracks(), targets, d)
 }
 
 // Turbulence returns the set of track that correspond to different kinds of turbulent airflow,
@@ -105,7 +140,88 @@ func (v VocalSystem) ConsonantVoice() tracks.Track {
 	return v.TrackSet[tracks.TrackID("ConsonantVoice")]
 }
 
+// Pitch returns the current frequency of the humming heard in voiced consonants.
+func (v VocalSystem) Pitch() float64 {
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		return track.(*tracks.ToneTrack).Frequency()
+	}
+	return 0
+}
+
+// AdjustPitch elongates the consonant voice while gliding the frequency of its humming.
+func (v VocalSystem) AdjustPitch(freq float64, d time.Duration) {
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		track.(*tracks.ToneTrack).AdjustFrequency(freq, d)
+	}
+}
+
+// SetSource sets the waveform and open quotient of the consonant voice's humming.
+func (v VocalSystem) SetSource(w tracks.Waveform, openQuotient float64) {
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		tone := track.(*tracks.ToneTrack)
+		tone.SetWaveform(w)
+		tone.SetOpenQuotient(openQuotient)
+	}
+}
+
+// SetPerturbation sets the jitter and shimmer of the consonant voice's humming, as percentages.
+func (v VocalSystem) SetPerturbation(jitter, shimmer float64) {
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		tone := track.(*tracks.ToneTrack)
+		tone.SetJitter(jitter)
+		tone.SetShimmer(shimmer)
+	}
+}
+
+// Breathiness returns the fraction of the consonant voice's power which is aspiration noise.
+func (v VocalSystem) Breathiness() float64 {
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		return track.(*tracks.ToneTrack).Breathiness()
+	}
+	return 0
+}
+
+// AdjustBreathiness elongates the consonant voice while mixing aspiration noise into its
+// humming.
+func (v VocalSystem) AdjustBreathiness(breathiness float64, d time.Duration) {
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		track.(*tracks.ToneTrack).AdjustBreathiness(breathiness, d)
+	}
+}
+
+// Whisper turns the voicing of everything encoded so far into breath noise, keeping the timing
+// and the trajectories of the formants.
+//
+// The formants become bands of noise, and the consonant voice's humming becomes aspiration
+// around its pitch, so pitch glides still move the aspiration but no harmonics are heard.
+func (v VocalSystem) Whisper() {
+	for _, tone := range v.FormantTracks() {
+		tone.AddSpread(whisperFormantSpread)
+	}
+	for _, track := range v.ConsonantVoice().(tracks.TrackSet) {
+		tone := track.(*tracks.ToneTrack)
+		tone.SetWaveform(tracks.SineWave)
+		tone.AddSpread(whisperAspirationSpread)
+	}
+}
+
+// GlidePitch glides the frequency of the consonant voice's humming over the last part of the
+// track, without elongating it.
+func (v VocalSystem) GlidePitch(freq float64, d time.Duration) {
+	tracks.GlideAll(v.ConsonantVoice(), freq, d)
+}
+
 // Liquid returns the track that corresponds to the "L" sound.
 func (v VocalSystem) Liquid() tracks.Track {
 	return v.TrackSet[tracks.TrackID("Liquid")]
 }
+
+// Clone creates a deep copy of the vocal system, so that one setup can be continued in
+// different ways.
+func (v VocalSystem) Clone() (VocalSystem, error) {
+	ts, err := v.TrackSet.Clone()
+	if err != nil {
+		return VocalSystem{}, err
+	}
+	return VocalSystem{ts}, nil
+}
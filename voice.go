@@ -3,16 +3,65 @@ package gospeech
 import (
 	"time"
 
+	"github.com/unixpickle/gospeech/tracks"
 	"github.com/unixpickle/wav"
 )
 
 type Voice struct {
 	Phones map[string]Phone
+
+	// Pitch is the frequency of the humming heard in voiced consonants.
+	// If it is 0, the default of the VocalSystem is kept.
+	// Intonation contours move the pitch around this base.
+	Pitch float64
+
+	// PitchRange is the number of semitones by which intonation contours may raise or lower the
+	// pitch.
+	// If it is 0, DefaultPitchRange is used.
+	PitchRange float64
+
+	// Stress describes how stressed and unstressed vowels are spoken.
+	// It only affects phones produced by ParseIPA, which carry a stress.
+	Stress StressEffects
+
+	// Rate is the speed of speech, where 1 is normal and 2 is twice as fast.
+	// The steady states of vowels and the silences between words scale with the rate, while
+	// closures compress less and bursts keep their length.
+	// If it is 0, the rate is 1.
+	Rate float64
+
+	// MinPause is the shortest that a Pause may be shortened to by a fast rate.
+	// If it is 0, DefaultMinPause is used.
+	MinPause time.Duration
+
+	// Source is the waveform of the voice, which every voiced sound of an utterance shares.
+	// The default is tracks.SineWave.
+	Source tracks.Waveform
+
+	// OpenQuotient is the open quotient of a pulse or glottal Source.
+	// If it is 0, tracks.DefaultOpenQuotient is used.
+	OpenQuotient float64
+
+	// Breathiness is the fraction of the voicing's power which is aspiration noise, from 0 for
+	// a pressed voice to 1 for pure breath.
+	Breathiness float64
+
+	// Jitter and Shimmer are the random perturbations of the voicing's pitch and volume, as
+	// percentages.
+	// A percent or so of each makes a voice sound less mechanical.
+	Jitter  float64
+	Shimmer float64
+
+	// Seed drives all of the voice's noise, so that synthesizing the same speech with the same
+	// seed gives identical samples.
+	Seed int64
+
+	// Whisper replaces the voicing with breath noise, as in VocalSystem.Whisper.
+	// The timing of the speech is the same as without it.
+	Whisper bool
 }
 
 func (v Voice) Synthesize(ipaString string) wav.Sound {
-	vocalSystem := NewVocalSystem()
-
 	words := [][]Phone{}
 	word := []Phone{}
 
@@ -31,27 +80,131 @@ func (v Voice) Synthesize(ipaString string) wav.Sound {
 		words = append(words, word)
 	}
 
+	return v.synthesizeWords(words)
+}
+
+// SynthesizePhones synthesizes a sequence of phones, such as one produced by ParseIPA.
+// WordBreaks and Pauses in the sequence separate words, and Pauses add silence after the word.
+func (v Voice) SynthesizePhones(phones []Phone) wav.Sound {
+	vocalSystem := v.newVocalSystem()
+	v.encodePhones(vocalSystem, phones, nil)
+	return v.encodeSound(vocalSystem)
+}
+
+func (v Voice) synthesizeWords(words [][]Phone) wav.Sound {
+	vocalSystem := v.newVocalSystem()
 	for _, word := range words {
-		for i, phone := range word {
-			var lastPhone, nextPhone Phone
-			if i > 0 {
-				lastPhone = word[i-1]
+		v.encodeWord(vocalSystem, v.applyRate(word), nil)
+	}
+	return v.encodeSound(vocalSystem)
+}
+
+func (v Voice) newVocalSystem() VocalSystem {
+	vocalSystem := NewVocalSystem()
+	vocalSystem.SetSeed(v.Seed)
+	vocalSystem.SetSource(v.Source, v.OpenQuotient)
+	vocalSystem.SetPerturbation(v.Jitter, v.Shimmer)
+	if v.Breathiness != 0 {
+		vocalSystem.AdjustBreathiness(v.Breathiness, 0)
+	}
+	if v.Pitch != 0 {
+		vocalSystem.AdjustPitch(v.Pitch, 0)
+	}
+	return vocalSystem
+}
+
+// encodePhones encodes a sequence of phones which WordBreaks and Pauses split into words,
+// applying the voice's stress effects and rate.
+//
+// If mark is non-nil, it is called with the index of each phone right before the phone is
+// encoded, and with len(phones) before the silence which ends the final word.
+// The silence ending a word is encoded after the mark for the WordBreak or Pause which ends it.
+func (v Voice) encodePhones(vocalSystem VocalSystem, phones []Phone, mark func(i int)) {
+	if mark == nil {
+		mark = func(int) {}
+	}
+	phones = v.applyRate(v.applyStress(phones))
+	word := []Phone{}
+	indices := []int{}
+	flushWord := func(end int) {
+		v.encodeWord(vocalSystem, word, func(j int) {
+			if j < len(indices) {
+				mark(indices[j])
+			} else {
+				mark(end)
 			}
-			if i < len(word)-1 {
-				nextPhone = word[i+1]
+		})
+		word = []Phone{}
+		indices = []int{}
+	}
+	for i, phone := range phones {
+		switch phone := phone.(type) {
+		case WordBreak:
+			flushWord(i)
+		case Pause:
+			if len(word) > 0 {
+				flushWord(i)
+			} else {
+				mark(i)
 			}
-			phone.EncodeBeginning(vocalSystem, lastPhone, nextPhone)
+			phone.EncodeBeginning(vocalSystem, nil, nil)
+		default:
+			word = append(word, phone)
+			indices = append(indices, i)
 		}
-		vocalSystem.AdjustVolume(0, time.Millisecond*50)
-		vocalSystem.Continue(time.Millisecond * 300)
 	}
+	if len(word) > 0 {
+		flushWord(len(phones))
+	} else {
+		mark(len(phones))
+	}
+}
 
+// encodeWord encodes the phones of a word followed by a brief silence, which shortens as the
+// voice's rate goes up.
+// If mark is non-nil, it is called with the index of each phone right before the phone is
+// encoded, and with len(word) before the silence.
+func (v Voice) encodeWord(vocalSystem VocalSystem, word []Phone, mark func(i int)) {
+	for i, phone := range word {
+		var lastPhone, nextPhone Phone
+		if i > 0 {
+			lastPhone = word[i-1]
+		}
+		if i < len(word)-1 {
+			nextPhone = word[i+1]
+		}
+		if mark != nil {
+			mark(i)
+		}
+		phone.EncodeBeginning(vocalSystem, lastPhone, nextPhone)
+	}
+	if mark != nil {
+		mark(len(word))
+	}
+	vocalSystem.AdjustVolume(0, time.Millisecond*50)
+	vocalSystem.Continue(v.scaleSteadyState(time.Millisecond * 300))
+}
+
+func (v Voice) encodeSound(vocalSystem VocalSystem) wav.Sound {
 	s := wav.NewPCM8Sound(1, 44100)
-	s.SetSamples(vocalSystem.Encode(44100))
+	s.SetSamples(v.mixTrack(vocalSystem).Encode(44100))
 	return s
 }
 
+// mixTrack returns the track which mixes a finished VocalSystem into the voice's sound.
+func (v Voice) mixTrack(vocalSystem VocalSystem) tracks.Track {
+	if !v.Whisper {
+		return vocalSystem.TrackSet
+	}
+	vocalSystem.Whisper()
+	weighted := tracks.NewWeightedTrackSet(vocalSystem.TrackSet)
+	weighted.SetGain("ConsonantVoice", whisperAspirationGain)
+	return weighted
+}
+
 var DefaultVoice = Voice{
+	Pitch:  350,
+	Stress: DefaultStressEffects,
 	Phones: map[string]Phone{
 		"i": Vowel{
 			Formants: NewFormantState(280, 0.3, 2250, 0.3, 2890, 0.3),
@@ -101,12 +254,42 @@ var DefaultVoice = Voice{
 			Formants: NewFormantState(710, 0.3, 1100, 0.3, 2540, 0.3),
 			Duration: time.Millisecond * 200,
 		},
-		"p": BilabialPlosive{Voiced: false},
-		"b": BilabialPlosive{Voiced: true},
-		"t": AlveolarPlosive{Voiced: false},
-		"d": AlveolarPlosive{Voiced: true},
-		"k": VelarPlosive{Voiced: false},
-		"g": VelarPlosive{Voiced: true},
+		"aI": Diphthong{
+			Start:    NewFormantState(710, 0.3, 1100, 0.3, 2540, 0.3),
+			End:      NewFormantState(400, 0.3, 1920, 0.3, 2560, 0.3),
+			Duration: time.Millisecond * 250,
+			Glide:    0.6,
+		},
+		"aʊ": Diphthong{
+			Start:    NewFormantState(710, 0.3, 1100, 0.3, 2540, 0.3),
+			End:      NewFormantState(450, 0.3, 1030, 0.3, 2380, 0.3),
+			Duration: time.Millisecond * 250,
+			Glide:    0.6,
+		},
+		"eI": Diphthong{
+			Start:    NewFormantState(400, 0.3, 2200, 0.3, 2890, 0.3),
+			End:      NewFormantState(400, 0.3, 1920, 0.3, 2560, 0.3),
+			Duration: time.Millisecond * 250,
+			Glide:    0.5,
+		},
+		"oʊ": Diphthong{
+			Start:    NewFormantState(450, 0.3, 700, 0.3, 2380, 0.3),
+			End:      NewFormantState(450, 0.3, 1030, 0.3, 2380, 0.3),
+			Duration: time.Millisecond * 250,
+			Glide:    0.5,
+		},
+		"ɔI": Diphthong{
+			Start:    NewFormantState(590, 0.3, 880, 0.3, 2540, 0.3),
+			End:      NewFormantState(400, 0.3, 1920, 0.3, 2560, 0.3),
+			Duration: time.Millisecond * 250,
+			Glide:    0.6,
+		},
+		"p": NewPlosive(BilabialPlace, false),
+		"b": NewPlosive(BilabialPlace, true),
+		"t": NewPlosive(AlveolarPlace, false),
+		"d": NewPlosive(AlveolarPlace, true),
+		"k": NewPlosive(VelarPlace, false),
+		"g": NewPlosive(VelarPlace, true),
 		"m": Nasal{
 			Type:     "m",
 			Formants: NewFormantState(250, 0.3, 2500, 0.1, 3250, 0.1),
@@ -0,0 +1,480 @@
+package gospeech
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+// Phone kinds used by PhoneConfig.
+const (
+	VowelKind            = "vowel"
+	DiphthongKind        = "diphthong"
+	PlosiveKind          = "plosive"
+	BilabialPlosiveKind  = "bilabial_plosive"
+	AlveolarPlosiveKind  = "alveolar_plosive"
+	VelarPlosiveKind     = "velar_plosive"
+	NasalKind            = "nasal"
+	FricativeKind        = "fricative"
+	RetroflexLiquidKind  = "retroflex_liquid"
+	LateralLiquidKind    = "lateral_liquid"
+	GlottalStopKind      = "glottal_stop"
+	maxConfigFrequency   = 20000
+	maxConfigDurationMs  = 10000
+	maxConfigPitchRange  = 24
+	maxConfigPitchAccent = 12
+	maxConfigStressScale = 4
+	maxConfigRate        = 4
+	maxConfigJitter      = 20
+	configDurationFactor = float64(time.Millisecond)
+)
+
+// A VoiceConfig is a serializable definition of a Voice.
+// Its source is one of "sine", "sawtooth", "pulse", or "glottal", and it is a sine if it is
+// empty.
+type VoiceConfig struct {
+	Pitch        float64                `json:"pitch"`
+	PitchRange   float64                `json:"pitch_range,omitempty"`
+	Stress       StressEffects          `json:"stress"`
+	Rate         float64                `json:"rate,omitempty"`
+	MinPauseMs   float64                `json:"min_pause_ms,omitempty"`
+	Source       string                 `json:"source,omitempty"`
+	OpenQuotient float64                `json:"open_quotient,omitempty"`
+	Breathiness  float64                `json:"breathiness,omitempty"`
+	Jitter       float64                `json:"jitter,omitempty"`
+	Shimmer      float64                `json:"shimmer,omitempty"`
+	Seed         int64                  `json:"seed,omitempty"`
+	Whisper      bool                   `json:"whisper,omitempty"`
+	Phones       map[string]PhoneConfig `json:"phones"`
+}
+
+// A PhoneConfig is a serializable definition of one of the package's Phone types.
+// Which fields apply depends on the kind.
+type PhoneConfig struct {
+	Kind string `json:"kind"`
+
+	// Formants applies to vowels, nasals, and retroflex liquids.
+	// For diphthongs, it is the start target.
+	Formants *FormantState `json:"formants,omitempty"`
+
+	// EndFormants applies to diphthongs.
+	EndFormants *FormantState `json:"end_formants,omitempty"`
+
+	// DurationMs applies to vowels and diphthongs.
+	DurationMs float64 `json:"duration_ms,omitempty"`
+
+	// Glide applies to diphthongs.
+	Glide float64 `json:"glide,omitempty"`
+
+	// Voiced applies to plosives and fricatives.
+	Voiced bool `json:"voiced,omitempty"`
+
+	// ContinueToNext applies to alveolar plosives.
+	ContinueToNext bool `json:"continue_to_next,omitempty"`
+
+	// Type applies to nasals and fricatives.
+	Type string `json:"type,omitempty"`
+
+	// AntiFormant and AntiFormantBandwidth apply to nasals.
+	AntiFormant          float64 `json:"anti_formant,omitempty"`
+	AntiFormantBandwidth float64 `json:"anti_formant_bandwidth,omitempty"`
+
+	// These fields apply to plosives.
+	Place           string  `json:"place,omitempty"`
+	ClosureMs       float64 `json:"closure_ms,omitempty"`
+	BurstFrequency  float64 `json:"burst_frequency,omitempty"`
+	BurstBandwidth  float64 `json:"burst_bandwidth,omitempty"`
+	BurstMs         float64 `json:"burst_ms,omitempty"`
+	VoiceOnsetMs    float64 `json:"voice_onset_ms,omitempty"`
+	Prevoiced       bool    `json:"prevoiced,omitempty"`
+	UnreleasedFinal bool    `json:"unreleased_final,omitempty"`
+}
+
+// plosivePlaceNames maps the places of articulation of plosives to their names in a PhoneConfig.
+var plosivePlaceNames = map[PlosivePlace]string{
+	BilabialPlace: "bilabial",
+	AlveolarPlace: "alveolar",
+	VelarPlace:    "velar",
+}
+
+// sourceNames maps the waveforms of a voice's source to their names in a VoiceConfig.
+var sourceNames = map[tracks.Waveform]string{
+	tracks.SineWave:     "sine",
+	tracks.SawtoothWave: "sawtooth",
+	tracks.PulseWave:    "pulse",
+	tracks.GlottalWave:  "glottal",
+}
+
+// LoadVoice reads a VoiceConfig in JSON and creates the Voice it defines.
+func LoadVoice(r io.Reader) (*Voice, error) {
+	var config VoiceConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, err
+	}
+	return config.Voice()
+}
+
+// Save writes the voice's VoiceConfig in JSON.
+func (v *Voice) Save(w io.Writer) error {
+	config, err := v.Config()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Config creates a VoiceConfig for the voice.
+// It fails if the voice uses Phone types which the package does not define.
+func (v *Voice) Config() (*VoiceConfig, error) {
+	res := &VoiceConfig{
+		Pitch:       v.Pitch,
+		PitchRange:  v.PitchRange,
+		Stress:      v.Stress,
+		Rate:        v.Rate,
+		MinPauseMs:  float64(v.MinPause) / configDurationFactor,
+		Breathiness: v.Breathiness,
+		Jitter:      v.Jitter,
+		Shimmer:     v.Shimmer,
+		Seed:        v.Seed,
+		Whisper:     v.Whisper,
+		Phones:      map[string]PhoneConfig{},
+	}
+	if v.Source != tracks.SineWave {
+		name, ok := sourceNames[v.Source]
+		if !ok {
+			return nil, errors.New("source: cannot serialize waveform")
+		}
+		res.Source = name
+		res.OpenQuotient = v.OpenQuotient
+	}
+	for symbol, phone := range v.Phones {
+		config, ok := phoneConfig(phone)
+		if !ok {
+			return nil, errors.New("phones." + symbol + ": cannot serialize phone")
+		}
+		res.Phones[symbol] = config
+	}
+	return res, nil
+}
+
+// Voice validates the config and creates the Voice it defines.
+// Errors name the field which failed to validate.
+func (v *VoiceConfig) Voice() (*Voice, error) {
+	if v.Pitch < 0 || v.Pitch > maxConfigFrequency {
+		return nil, errors.New("pitch: out of range")
+	}
+	if v.PitchRange < 0 || v.PitchRange > maxConfigPitchRange {
+		return nil, errors.New("pitch_range: out of range")
+	}
+	stressEffects := map[string]StressEffect{
+		"primary":    v.Stress.Primary,
+		"secondary":  v.Stress.Secondary,
+		"unstressed": v.Stress.Unstressed,
+	}
+	for _, name := range []string{"primary", "secondary", "unstressed"} {
+		if err := validateStressEffect(stressEffects[name]); err != nil {
+			return nil, errors.New("stress." + name + "." + err.Error())
+		}
+	}
+	if v.Rate < 0 || v.Rate > maxConfigRate {
+		return nil, errors.New("rate: out of range")
+	}
+	if v.MinPauseMs < 0 || v.MinPauseMs > maxConfigDurationMs {
+		return nil, errors.New("min_pause_ms: out of range")
+	}
+	source, ok := tracks.SineWave, v.Source == ""
+	for key, name := range sourceNames {
+		if name == v.Source {
+			source, ok = key, true
+		}
+	}
+	if !ok {
+		return nil, errors.New("source: unknown waveform: " + strconv.Quote(v.Source))
+	}
+	if v.OpenQuotient < 0 || v.OpenQuotient > 1 {
+		return nil, errors.New("open_quotient: out of range")
+	}
+	if v.Breathiness < 0 || v.Breathiness > 1 {
+		return nil, errors.New("breathiness: out of range")
+	}
+	if v.Jitter < 0 || v.Jitter > maxConfigJitter {
+		return nil, errors.New("jitter: out of range")
+	}
+	if v.Shimmer < 0 || v.Shimmer > maxConfigJitter {
+		return nil, errors.New("shimmer: out of range")
+	}
+	if len(v.Phones) == 0 {
+		return nil, errors.New("phones: missing")
+	}
+	symbols := make([]string, 0, len(v.Phones))
+	for symbol := range v.Phones {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	res := &Voice{
+		Pitch:        v.Pitch,
+		PitchRange:   v.PitchRange,
+		Stress:       v.Stress,
+		Rate:         v.Rate,
+		MinPause:     configDuration(v.MinPauseMs),
+		Source:       source,
+		OpenQuotient: v.OpenQuotient,
+		Breathiness:  v.Breathiness,
+		Jitter:       v.Jitter,
+		Shimmer:      v.Shimmer,
+		Seed:         v.Seed,
+		Whisper:      v.Whisper,
+		Phones:       map[string]Phone{},
+	}
+	for _, symbol := range symbols {
+		phone, err := v.Phones[symbol].phone()
+		if err != nil {
+			return nil, errors.New("phones." + symbol + "." + err.Error())
+		}
+		res.Phones[symbol] = phone
+	}
+	return res, nil
+}
+
+func validateStressEffect(s StressEffect) error {
+	if s.DurationScale < 0 || s.DurationScale > maxConfigStressScale {
+		return errors.New("duration_scale: out of range")
+	}
+	if math.Abs(s.PitchAccent) > maxConfigPitchAccent {
+		return errors.New("pitch_accent: out of range")
+	}
+	if s.VolumeScale < 0 || s.VolumeScale > maxConfigStressScale {
+		return errors.New("volume_scale: out of range")
+	}
+	return nil
+}
+
+func phoneConfig(phone Phone) (PhoneConfig, bool) {
+	switch phone := phone.(type) {
+	case Vowel:
+		return PhoneConfig{
+			Kind:       VowelKind,
+			Formants:   copyFormants(phone.Formants),
+			DurationMs: float64(phone.Duration) / configDurationFactor,
+		}, true
+	case Diphthong:
+		return PhoneConfig{
+			Kind:        DiphthongKind,
+			Formants:    copyFormants(phone.Start),
+			EndFormants: copyFormants(phone.End),
+			DurationMs:  float64(phone.Duration) / configDurationFactor,
+			Glide:       phone.Glide,
+		}, true
+	case Plosive:
+		return PhoneConfig{
+			Kind:            PlosiveKind,
+			Voiced:          phone.Voiced,
+			Place:           plosivePlaceNames[phone.Place],
+			ClosureMs:       float64(phone.Closure) / configDurationFactor,
+			BurstFrequency:  phone.BurstFrequency,
+			BurstBandwidth:  phone.BurstBandwidth,
+			BurstMs:         float64(phone.BurstDuration) / configDurationFactor,
+			VoiceOnsetMs:    float64(phone.VoiceOnsetTime) / configDurationFactor,
+			Prevoiced:       phone.Prevoiced,
+			UnreleasedFinal: phone.UnreleasedFinal,
+		}, true
+	case BilabialPlosive:
+		return PhoneConfig{Kind: BilabialPlosiveKind, Voiced: phone.Voiced}, true
+	case AlveolarPlosive:
+		return PhoneConfig{Kind: AlveolarPlosiveKind, Voiced: phone.Voiced,
+			ContinueToNext: phone.ContinueToNext}, true
+	case VelarPlosive:
+		return PhoneConfig{Kind: VelarPlosiveKind, Voiced: phone.Voiced}, true
+	case Nasal:
+		return PhoneConfig{
+			Kind:                 NasalKind,
+			Type:                 phone.Type,
+			Formants:             copyFormants(phone.Formants),
+			AntiFormant:          phone.AntiFormant,
+			AntiFormantBandwidth: phone.AntiFormantBandwidth,
+		}, true
+	case Fricative:
+		return PhoneConfig{Kind: FricativeKind, Type: phone.Type, Voiced: phone.Voiced}, true
+	case RetroflexLiquid:
+		return PhoneConfig{Kind: RetroflexLiquidKind, Formants: copyFormants(phone.Formants)}, true
+	case LateralLiquid:
+		return PhoneConfig{Kind: LateralLiquidKind}, true
+	case GlottalStop:
+		return PhoneConfig{Kind: GlottalStopKind}, true
+	}
+	return PhoneConfig{}, false
+}
+
+// phone validates the config and creates the Phone it defines.
+// Errors start with the name of the field which failed to validate.
+func (p PhoneConfig) phone() (Phone, error) {
+	switch p.Kind {
+	case VowelKind:
+		formants, err := p.formants()
+		if err != nil {
+			return nil, err
+		}
+		duration, err := p.duration()
+		if err != nil {
+			return nil, err
+		}
+		return Vowel{Formants: formants, Duration: duration}, nil
+	case DiphthongKind:
+		start, err := p.formants()
+		if err != nil {
+			return nil, err
+		}
+		end, err := validateFormants("end_formants", p.EndFormants)
+		if err != nil {
+			return nil, err
+		}
+		duration, err := p.duration()
+		if err != nil {
+			return nil, err
+		}
+		if p.Glide <= 0 || p.Glide > 1 {
+			return nil, errors.New("glide: out of range")
+		}
+		return Diphthong{Start: start, End: end, Duration: duration, Glide: p.Glide}, nil
+	case PlosiveKind:
+		return p.plosive()
+	case BilabialPlosiveKind:
+		return BilabialPlosive{Voiced: p.Voiced}, nil
+	case AlveolarPlosiveKind:
+		return AlveolarPlosive{Voiced: p.Voiced, ContinueToNext: p.ContinueToNext}, nil
+	case VelarPlosiveKind:
+		return VelarPlosive{Voiced: p.Voiced}, nil
+	case NasalKind:
+		switch p.Type {
+		case "m", "n", "ng", "ŋ":
+		default:
+			return nil, errors.New("type: unknown nasal type: " + strconv.Quote(p.Type))
+		}
+		formants, err := p.formants()
+		if err != nil {
+			return nil, err
+		}
+		if p.AntiFormant < 0 || p.AntiFormant > maxConfigFrequency {
+			return nil, errors.New("anti_formant: out of range")
+		}
+		if p.AntiFormantBandwidth < 0 || p.AntiFormantBandwidth > maxConfigFrequency {
+			return nil, errors.New("anti_formant_bandwidth: out of range")
+		}
+		return Nasal{
+			Type:                 p.Type,
+			Formants:             formants,
+			AntiFormant:          p.AntiFormant,
+			AntiFormantBandwidth: p.AntiFormantBandwidth,
+		}, nil
+	case FricativeKind:
+		switch p.Type {
+		case "F", "TH", "S", "SH", "H":
+		default:
+			return nil, errors.New("type: unknown fricative type: " + strconv.Quote(p.Type))
+		}
+		return Fricative{Type: p.Type, Voiced: p.Voiced}, nil
+	case RetroflexLiquidKind:
+		formants, err := p.formants()
+		if err != nil {
+			return nil, err
+		}
+		return RetroflexLiquid{Formants: formants}, nil
+	case LateralLiquidKind:
+		return LateralLiquid{}, nil
+	case GlottalStopKind:
+		return GlottalStop{}, nil
+	case "":
+		return nil, errors.New("kind: missing")
+	}
+	return nil, errors.New("kind: unknown phone kind: " + strconv.Quote(p.Kind))
+}
+
+func (p PhoneConfig) plosive() (Phone, error) {
+	res := Plosive{
+		Voiced:          p.Voiced,
+		BurstFrequency:  p.BurstFrequency,
+		BurstBandwidth:  p.BurstBandwidth,
+		Prevoiced:       p.Prevoiced,
+		UnreleasedFinal: p.UnreleasedFinal,
+	}
+	place, ok := PlosivePlace(0), false
+	for key, name := range plosivePlaceNames {
+		if name == p.Place {
+			place, ok = key, true
+		}
+	}
+	if !ok {
+		return nil, errors.New("place: unknown place: " + strconv.Quote(p.Place))
+	}
+	res.Place = place
+	if p.ClosureMs <= 0 || p.ClosureMs > maxConfigDurationMs {
+		return nil, errors.New("closure_ms: out of range")
+	}
+	if p.BurstFrequency <= 0 || p.BurstFrequency > maxConfigFrequency {
+		return nil, errors.New("burst_frequency: out of range")
+	}
+	if p.BurstBandwidth < 0 || p.BurstBandwidth > maxConfigFrequency {
+		return nil, errors.New("burst_bandwidth: out of range")
+	}
+	minBurstMs := float64(plosiveBurstRise) / configDurationFactor
+	if p.BurstMs < minBurstMs || p.BurstMs > maxConfigDurationMs {
+		return nil, errors.New("burst_ms: out of range")
+	}
+	if p.VoiceOnsetMs < 0 || p.VoiceOnsetMs > maxConfigDurationMs {
+		return nil, errors.New("voice_onset_ms: out of range")
+	}
+	res.Closure = configDuration(p.ClosureMs)
+	res.BurstDuration = configDuration(p.BurstMs)
+	res.VoiceOnsetTime = configDuration(p.VoiceOnsetMs)
+	return res, nil
+}
+
+func (p PhoneConfig) formants() (FormantState, error) {
+	return validateFormants("formants", p.Formants)
+}
+
+func (p PhoneConfig) duration() (time.Duration, error) {
+	if p.DurationMs <= 0 || p.DurationMs > maxConfigDurationMs {
+		return 0, errors.New("duration_ms: out of range")
+	}
+	return configDuration(p.DurationMs), nil
+}
+
+func configDuration(ms float64) time.Duration {
+	return time.Duration(math.Round(ms * configDurationFactor))
+}
+
+func validateFormants(field string, f *FormantState) (FormantState, error) {
+	if f == nil {
+		return FormantState{}, errors.New(field + ": missing")
+	}
+	for i, freq := range f.Frequencies {
+		if freq <= 0 || freq > maxConfigFrequency {
+			return FormantState{}, errors.New(field + ".Frequencies[" + strconv.Itoa(i) +
+				"]: out of range")
+		}
+	}
+	for i, vol := range f.Volumes {
+		if vol < 0 || vol > 1 {
+			return FormantState{}, errors.New(field + ".Volumes[" + strconv.Itoa(i) +
+				"]: out of range")
+		}
+	}
+	return *f, nil
+}
+
+func copyFormants(f FormantState) *FormantState {
+	return &f
+}
@@ -0,0 +1,128 @@
+package gospeech
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+)
+
+func TestVoiceSaveLoad(t *testing.T) {
+	breathy := DefaultVoice
+	breathy.Source = tracks.GlottalWave
+	breathy.OpenQuotient = 0.5
+	breathy.Breathiness = 0.3
+	breathy.Jitter = 1
+	breathy.Shimmer = 2
+	breathy.Seed = 7
+	breathy.Rate = 1.25
+	breathy.MinPause = time.Millisecond * 40
+	breathy.PitchRange = 5
+
+	for i, voice := range []Voice{DefaultVoice, breathy} {
+		var buf bytes.Buffer
+		if err := voice.Save(&buf); err != nil {
+			t.Fatal(err)
+		}
+		loaded, err := LoadVoice(&buf)
+		if err != nil {
+			t.Fatalf("voice %d: %s", i, err)
+		}
+		if !reflect.DeepEqual(*loaded, voice) {
+			t.Errorf("voice %d: expected %+v but got %+v", i, voice, *loaded)
+		}
+
+		expected, err := voice.ParseIPA("həˈloʊ ˈwəɹld")
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := loaded.ParseIPA("həˈloʊ ˈwəɹld")
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedSamples := voice.SynthesizePhones(expected).Samples()
+		actualSamples := loaded.SynthesizePhones(actual).Samples()
+		if !reflect.DeepEqual(expectedSamples, actualSamples) {
+			t.Errorf("voice %d: the loaded voice sounds different", i)
+		}
+	}
+}
+
+func TestVoiceConfigValidation(t *testing.T) {
+	for _, test := range []struct {
+		modify func(c *VoiceConfig)
+		err    string
+	}{
+		{func(c *VoiceConfig) { c.Pitch = -100 }, "pitch: out of range"},
+		{func(c *VoiceConfig) { c.PitchRange = 100 }, "pitch_range: out of range"},
+		{func(c *VoiceConfig) { c.Stress.Primary.DurationScale = -1 },
+			"stress.primary.duration_scale: out of range"},
+		{func(c *VoiceConfig) { c.Stress.Unstressed.PitchAccent = -20 },
+			"stress.unstressed.pitch_accent: out of range"},
+		{func(c *VoiceConfig) { c.Rate = -1 }, "rate: out of range"},
+		{func(c *VoiceConfig) { c.Source = "square" }, `source: unknown waveform: "square"`},
+		{func(c *VoiceConfig) { c.Breathiness = 1.5 }, "breathiness: out of range"},
+		{func(c *VoiceConfig) { c.Jitter = -1 }, "jitter: out of range"},
+		{func(c *VoiceConfig) { c.Phones = nil }, "phones: missing"},
+		{func(c *VoiceConfig) { c.Phones["a"].Formants.Frequencies[1] = -5 },
+			"phones.a.formants.Frequencies[1]: out of range"},
+		{func(c *VoiceConfig) { c.Phones["e"].Formants.Volumes[2] = 2 },
+			"phones.e.formants.Volumes[2]: out of range"},
+		{func(c *VoiceConfig) { c.Phones["x"] = PhoneConfig{Kind: "click"} },
+			`phones.x.kind: unknown phone kind: "click"`},
+		{func(c *VoiceConfig) { c.Phones["x"] = PhoneConfig{} }, "phones.x.kind: missing"},
+		{func(c *VoiceConfig) { c.Phones["x"] = PhoneConfig{Kind: VowelKind} },
+			"phones.x.formants: missing"},
+		{func(c *VoiceConfig) {
+			p := c.Phones["i"]
+			p.DurationMs = 0
+			c.Phones["i"] = p
+		}, "phones.i.duration_ms: out of range"},
+		{func(c *VoiceConfig) {
+			p := c.Phones["p"]
+			p.BurstFrequency = -1
+			c.Phones["p"] = p
+		}, "phones.p.burst_frequency: out of range"},
+		{func(c *VoiceConfig) {
+			p := c.Phones["s"]
+			p.Type = "X"
+			c.Phones["s"] = p
+		}, `phones.s.type: unknown fricative type: "X"`},
+	} {
+		config, err := DefaultVoice.Config()
+		if err != nil {
+			t.Fatal(err)
+		}
+		test.modify(config)
+		if _, err := config.Voice(); err == nil {
+			t.Errorf("expected error %q", test.err)
+		} else if err.Error() != test.err {
+			t.Errorf("expected error %q but got %q", test.err, err)
+		}
+	}
+}
+
+func TestLoadVoiceInvalidJSON(t *testing.T) {
+	if _, err := LoadVoice(strings.NewReader("{\"pitch\": ")); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+	if _, err := LoadVoice(strings.NewReader(`{"pitch": 200}`)); err == nil ||
+		err.Error() != "phones: missing" {
+		t.Errorf("expected a missing phones error but got %v", err)
+	}
+}
+
+type unknownPhone struct {
+	GlottalStop
+}
+
+func TestVoiceSaveUnknownPhone(t *testing.T) {
+	voice := Voice{Phones: map[string]Phone{"q": unknownPhone{}}}
+	var buf bytes.Buffer
+	if err := voice.Save(&buf); err == nil || err.Error() != "phones.q: cannot serialize phone" {
+		t.Errorf("expected a serialization error but got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package gospeech
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/unixpickle/gospeech/tracks"
+	"github.com/unixpickle/wav"
+)
+
+func TestVoiceSource(t *testing.T) {
+	for _, test := range []struct {
+		source       tracks.Waveform
+		openQuotient float64
+		expected     float64
+	}{
+		{tracks.SineWave, 0, tracks.DefaultOpenQuotient},
+		{tracks.GlottalWave, 0, tracks.DefaultOpenQuotient},
+		{tracks.PulseWave, 0.4, 0.4},
+	} {
+		voice := DefaultVoice
+		voice.Source = test.source
+		voice.OpenQuotient = test.openQuotient
+		set := voice.TimedTrack(timeIPA(t, voice, "zaz")).(tracks.TrackSet)
+		humming := set["ConsonantVoice"].(tracks.TrackSet)
+		if len(humming) == 0 {
+			t.Fatal("expected the consonant voice to hum")
+		}
+		for id, track := range humming {
+			tone := track.(*tracks.ToneTrack)
+			if tone.Waveform() != test.source || tone.OpenQuotient() != test.expected {
+				t.Errorf("source %d: %s has waveform %d with open quotient %f", test.source, id,
+					tone.Waveform(), tone.OpenQuotient())
+			}
+		}
+	}
+}
+
+func TestVoiceBreathiness(t *testing.T) {
+	voice := DefaultVoice
+	voice.Breathiness = 0.4
+	set := voice.TimedTrack(timeIPA(t, voice, "zaz")).(tracks.TrackSet)
+	for id, track := range set["ConsonantVoice"].(tracks.TrackSet) {
+		if b := track.(*tracks.ToneTrack).Breathiness(); b != 0.4 {
+			t.Errorf("%s: expected a breathiness of 0.4 but got %f", id, b)
+		}
+	}
+	breathy := set["ConsonantVoice"].Encode(16000)
+	plain := DefaultVoice.TimedTrack(timeIPA(t, DefaultVoice, "zaz")).(tracks.TrackSet)
+	if reflect.DeepEqual(breathy, plain["ConsonantVoice"].Encode(16000)) {
+		t.Error("expected breathiness to change the voicing")
+	}
+}
+
+func TestVoiceSeed(t *testing.T) {
+	render := func(seed int64) []wav.Sample {
+		voice := DefaultVoice
+		voice.Jitter, voice.Shimmer, voice.Breathiness = 1, 2, 0.2
+		voice.Seed = seed
+		return voice.TimedTrack(timeIPA(t, voice, "sazi")).Encode(16000)
+	}
+	if !reflect.DeepEqual(render(5), render(5)) {
+		t.Error("expected the same seed to render identical samples")
+	}
+	if reflect.DeepEqual(render(5), render(6)) {
+		t.Error("expected different seeds to render different samples")
+	}
+}
+
+func TestVoiceWhisper(t *testing.T) {
+	const sampleRate = 44100
+	whisper := DefaultVoice
+	whisper.Whisper = true
+	timed := timeIPA(t, DefaultVoice, "zaz")
+	voiced := DefaultVoice.SynthesizeTimed(timed).Samples()
+	whispered := whisper.SynthesizeTimed(timed).Samples()
+	if len(voiced) != len(whispered) {
+		t.Fatalf("expected whispering to keep %d samples but got %d", len(voiced), len(whispered))
+	}
+	for _, contour := range []ContourType{StatementContour, QuestionContour} {
+		phones, err := whisper.ParseIPA("zaz")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(whisper.SynthesizeContour(phones, contour).Samples()); n != len(voiced) {
+			t.Errorf("contour %d: expected %d samples but got %d", contour, len(voiced), n)
+		}
+	}
+
+	// peakiness compares the strongest bin of the vowel's spectrum to most of the other bins.
+	// Tones stand far above the rest of the spectrum, while noise is nearly flat.
+	peakiness := func(samples []wav.Sample) float64 {
+		var res float64
+		for _, frame := range tracks.Spectrogram(samples, sampleRate, 2048, 1024) {
+			var band []float64
+			for k, m := range frame {
+				f := tracks.SpectrogramBinFrequency(k, len(frame), sampleRate)
+				if f > 200 && f < 4000 {
+					band = append(band, m)
+				}
+			}
+			sort.Float64s(band)
+			res = math.Max(res, band[len(band)-1]/band[len(band)*9/10])
+		}
+		return res
+	}
+	start := int(timed[1].Start * sampleRate / time.Second)
+	if p := peakiness(voiced[start : start+4096]); p < 20 {
+		t.Errorf("expected the voiced vowel to have tones, but its peaks are only %f high", p)
+	}
+	if p := peakiness(whispered[start : start+4096]); p > 8 {
+		t.Errorf("expected the whispered vowel to be noise, but its peaks are %f high", p)
+	}
+}